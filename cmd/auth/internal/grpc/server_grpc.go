@@ -78,10 +78,16 @@ func (s *Server) verifyToken(ctx context.Context, in *structpb.Struct) (*structp
 	if err != nil {
 		return structpb.NewStruct(map[string]interface{}{"error": err.Error()})
 	}
+	// structpb only accepts []interface{} for list values
+	scopes := make([]interface{}, 0, len(claims.Scopes))
+	for _, sc := range claims.Scopes {
+		scopes = append(scopes, sc)
+	}
 	return structpb.NewStruct(map[string]interface{}{
 		"user_id": claims.UserID,
 		"email":   claims.Email,
-		"scopes":  claims.Scopes,
+		"scopes":  scopes,
+		"exp":     claims.Expires,
 	})
 }
 
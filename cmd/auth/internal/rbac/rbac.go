@@ -11,7 +11,7 @@ const (
 // Scopes are fine-grained permissions checked by API Gateway/services.
 var RoleScopes = map[Role][]string{
 	RoleUser:  {"profile:read", "booking:create", "booking:read"},
-	RoleAdmin: {"profile:read", "profile:write", "booking:*", "space:*", "admin:*"},
+	RoleAdmin: {"profile:read", "profile:write", "booking:*", "space:*", "voucher:*", "admin:*"},
 }
 
 func ScopesFor(role Role) []string { return RoleScopes[role] }
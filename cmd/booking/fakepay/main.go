@@ -0,0 +1,56 @@
+// Command fakepay runs the fake payment provider for local development and tests. Point the booking
+// service at it with PAYMENT_PROVIDER_URL and share PAYMENT_WEBHOOK_SECRET between both.
+package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"templespace/cmd/booking/internal/payment"
+)
+
+func main() {
+	secret := os.Getenv("PAYMENT_WEBHOOK_SECRET")
+	if secret == "" {
+		log.Println("fakepay: PAYMENT_WEBHOOK_SECRET is required")
+		os.Exit(1)
+	}
+	p := payment.NewFakeProvider(getenv("FAKEPAY_WEBHOOK_URL", "http://localhost:8081/booking/payments/webhook"), secret)
+	p.APIKey = os.Getenv("PAYMENT_API_KEY")
+	p.FailureRate = getfloat("FAKEPAY_FAILURE_RATE", 0)
+	p.DuplicateRate = getfloat("FAKEPAY_DUPLICATE_RATE", 0)
+	p.ErrorRate = getfloat("FAKEPAY_ERROR_RATE", 0)
+	p.WebhookDelay = getduration("FAKEPAY_WEBHOOK_DELAY", time.Second)
+	p.AutoConfirm = os.Getenv("FAKEPAY_AUTO_CONFIRM") == "true"
+
+	addr := getenv("FAKEPAY_ADDR", ":8090")
+	log.Printf("fakepay listening on %s, webhooks to %s", addr, p.WebhookURL)
+	if err := http.ListenAndServe(addr, p); err != nil {
+		log.Println("fakepay error:", err)
+		os.Exit(1)
+	}
+}
+
+func getenv(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+func getfloat(key string, def float64) float64 {
+	if f, err := strconv.ParseFloat(os.Getenv(key), 64); err == nil {
+		return f
+	}
+	return def
+}
+
+func getduration(key string, def time.Duration) time.Duration {
+	if d, err := time.ParseDuration(os.Getenv(key)); err == nil {
+		return d
+	}
+	return def
+}
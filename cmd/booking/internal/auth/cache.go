@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"templespace/cmd/booking/internal/service"
+)
+
+// maxCacheEntries bounds the cache; expired entries are swept once it is exceeded.
+const maxCacheEntries = 4096
+
+type cacheEntry struct {
+	p      service.Principal
+	expiry time.Time
+}
+
+// CachingVerifier memoizes successful verifications for a short TTL, never past the expiry of the
+// token. Entries are keyed by the SHA-256 of the token so raw tokens are never held in memory.
+type CachingVerifier struct {
+	next service.TokenVerifier
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu sync.Mutex
+	m  map[string]cacheEntry
+}
+
+func NewCachingVerifier(next service.TokenVerifier, ttl time.Duration) *CachingVerifier {
+	return &CachingVerifier{next: next, ttl: ttl, now: time.Now, m: make(map[string]cacheEntry)}
+}
+
+func (c *CachingVerifier) Verify(ctx context.Context, token string) (service.Principal, error) {
+	sum := sha256.Sum256([]byte(token))
+	key := hex.EncodeToString(sum[:])
+
+	c.mu.Lock()
+	e, ok := c.m[key]
+	if ok && c.now().Before(e.expiry) {
+		c.mu.Unlock()
+		return e.p, nil
+	}
+	c.mu.Unlock()
+
+	p, err := c.next.Verify(ctx, token)
+	if err != nil {
+		return service.Principal{}, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	expiry := now.Add(c.ttl)
+	if !p.ExpiresAt.IsZero() && p.ExpiresAt.Before(expiry) {
+		expiry = p.ExpiresAt
+	}
+	if !now.Before(expiry) {
+		return p, nil
+	}
+	if len(c.m) >= maxCacheEntries {
+		for k, v := range c.m {
+			if !now.Before(v.expiry) {
+				delete(c.m, k)
+			}
+		}
+	}
+	if len(c.m) < maxCacheEntries {
+		c.m[key] = cacheEntry{p: p, expiry: expiry}
+	}
+	return p, nil
+}
@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"templespace/cmd/booking/internal/service"
+)
+
+type countingVerifier struct {
+	p     service.Principal
+	calls int
+}
+
+func (v *countingVerifier) Verify(ctx context.Context, token string) (service.Principal, error) {
+	v.calls++
+	return v.p, nil
+}
+
+func TestCachingVerifierStopsAtTokenExpiry(t *testing.T) {
+	start := time.Unix(1_700_000_000, 0)
+	tests := []struct {
+		name      string
+		expiresAt time.Time
+		after     time.Duration
+		wantCalls int
+	}{
+		{"within ttl and token lifetime", start.Add(time.Hour), 10 * time.Second, 1},
+		{"past ttl", start.Add(time.Hour), 31 * time.Second, 2},
+		{"past token expiry within ttl", start.Add(5 * time.Second), 10 * time.Second, 2},
+		{"expiry unknown", time.Time{}, 10 * time.Second, 1},
+		{"already expired", start, 0, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := &countingVerifier{p: service.Principal{UserID: "u1", ExpiresAt: tt.expiresAt}}
+			c := NewCachingVerifier(next, 30*time.Second)
+			now := start
+			c.now = func() time.Time { return now }
+			if _, err := c.Verify(context.Background(), "token"); err != nil {
+				t.Fatal(err)
+			}
+			now = now.Add(tt.after)
+			if _, err := c.Verify(context.Background(), "token"); err != nil {
+				t.Fatal(err)
+			}
+			if next.calls != tt.wantCalls {
+				t.Errorf("verifier called %d times, want %d", next.calls, tt.wantCalls)
+			}
+		})
+	}
+}
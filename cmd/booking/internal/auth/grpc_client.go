@@ -0,0 +1,92 @@
+//go:build grpc
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	gogrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"templespace/cmd/booking/internal/service"
+)
+
+const verifyTokenMethod = "/auth.AuthService/VerifyToken"
+
+// GRPCVerifier verifies tokens by calling auth.AuthService/VerifyToken over the structpb contract.
+type GRPCVerifier struct {
+	conn    *gogrpc.ClientConn
+	timeout time.Duration
+	retries int
+	backoff time.Duration
+}
+
+func NewGRPCVerifier(addr string, timeout time.Duration, retries int) (*GRPCVerifier, error) {
+	conn, err := gogrpc.NewClient(addr, gogrpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+	return &GRPCVerifier{conn: conn, timeout: timeout, retries: retries, backoff: 50 * time.Millisecond}, nil
+}
+
+func (v *GRPCVerifier) Verify(ctx context.Context, token string) (service.Principal, error) {
+	in, err := structpb.NewStruct(map[string]interface{}{"access_token": token})
+	if err != nil {
+		return service.Principal{}, err
+	}
+	var out *structpb.Struct
+	for attempt := 0; ; attempt++ {
+		out, err = v.invoke(ctx, in)
+		if err == nil || attempt >= v.retries || !retryable(err) {
+			break
+		}
+		select {
+		case <-time.After(v.backoff << attempt):
+		case <-ctx.Done():
+			return service.Principal{}, ctx.Err()
+		}
+	}
+	if err != nil {
+		return service.Principal{}, err
+	}
+	if e := out.GetFields()["error"].GetStringValue(); e != "" {
+		return service.Principal{}, errors.New(e)
+	}
+	p := service.Principal{UserID: out.GetFields()["user_id"].GetStringValue()}
+	if exp := int64(out.GetFields()["exp"].GetNumberValue()); exp > 0 {
+		p.ExpiresAt = time.Unix(exp, 0)
+	}
+	for _, sc := range out.GetFields()["scopes"].GetListValue().GetValues() {
+		p.Scopes = append(p.Scopes, sc.GetStringValue())
+	}
+	if p.UserID == "" {
+		return service.Principal{}, errors.New("auth service returned no user_id")
+	}
+	return p, nil
+}
+
+func (v *GRPCVerifier) invoke(ctx context.Context, in *structpb.Struct) (*structpb.Struct, error) {
+	ctx, cancel := context.WithTimeout(ctx, v.timeout)
+	defer cancel()
+	out := &structpb.Struct{}
+	if err := v.conn.Invoke(ctx, verifyTokenMethod, in, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (v *GRPCVerifier) Close() error { return v.conn.Close() }
+
+// retryable reports transport-level failures; token rejections are returned in-band and never retried.
+func retryable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted:
+		return true
+	}
+	return false
+}
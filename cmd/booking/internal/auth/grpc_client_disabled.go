@@ -0,0 +1,24 @@
+//go:build !grpc
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"templespace/cmd/booking/internal/service"
+)
+
+// GRPCVerifier is unavailable without the grpc build tag; NewGRPCVerifier always fails.
+type GRPCVerifier struct{}
+
+func NewGRPCVerifier(addr string, timeout time.Duration, retries int) (*GRPCVerifier, error) {
+	return nil, errors.New("auth grpc client requires the grpc build tag")
+}
+
+func (v *GRPCVerifier) Verify(ctx context.Context, token string) (service.Principal, error) {
+	return service.Principal{}, errors.New("auth grpc client requires the grpc build tag")
+}
+
+func (v *GRPCVerifier) Close() error { return nil }
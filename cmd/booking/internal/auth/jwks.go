@@ -0,0 +1,232 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"templespace/cmd/booking/internal/service"
+)
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type jwtClaims struct {
+	Issuer   string   `json:"iss"`
+	Subject  string   `json:"sub"`
+	Audience audience `json:"aud"`
+	Expires  int64    `json:"exp"`
+	Scopes   []string `json:"scopes"`
+	UserID   string   `json:"uid"`
+}
+
+// audience is the aud claim, which RFC 7519 allows as a single string or a list.
+type audience []string
+
+func (a *audience) UnmarshalJSON(b []byte) error {
+	var one string
+	if err := json.Unmarshal(b, &one); err == nil {
+		*a = audience{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(b, &many); err != nil {
+		return err
+	}
+	*a = many
+	return nil
+}
+
+// JWKSVerifier checks RS256 access tokens locally against the Auth service's JWKS,
+// avoiding a network hop per request. Keys are refreshed periodically and on unknown kid.
+// With an audience set, tokens must name it in their aud claim.
+type JWKSVerifier struct {
+	url      string
+	issuer   string
+	audience string
+	refresh  time.Duration
+	client   *http.Client
+	now      func() time.Time
+
+	mu      sync.RWMutex
+	keys    map[string]*rsa.PublicKey
+	fetched time.Time
+}
+
+func NewJWKSVerifier(url, issuer, audience string, refresh, timeout time.Duration) *JWKSVerifier {
+	return &JWKSVerifier{
+		url:      url,
+		issuer:   issuer,
+		audience: audience,
+		refresh:  refresh,
+		client:   &http.Client{Timeout: timeout},
+		now:      time.Now,
+		keys:     map[string]*rsa.PublicKey{},
+	}
+}
+
+func (v *JWKSVerifier) Verify(ctx context.Context, token string) (service.Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return service.Principal{}, errors.New("invalid token format")
+	}
+	var hdr jwtHeader
+	if err := decodeSegment(parts[0], &hdr); err != nil {
+		return service.Principal{}, err
+	}
+	if hdr.Alg != "RS256" {
+		return service.Principal{}, fmt.Errorf("unsupported alg %q", hdr.Alg)
+	}
+	key, err := v.key(ctx, hdr.Kid)
+	if err != nil {
+		return service.Principal{}, err
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return service.Principal{}, err
+	}
+	sum := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, sum[:], sig); err != nil {
+		return service.Principal{}, errors.New("invalid signature")
+	}
+	var cl jwtClaims
+	if err := decodeSegment(parts[1], &cl); err != nil {
+		return service.Principal{}, err
+	}
+	if v.now().Unix() > cl.Expires {
+		return service.Principal{}, errors.New("token expired")
+	}
+	if cl.Issuer != v.issuer {
+		return service.Principal{}, errors.New("invalid issuer")
+	}
+	if v.audience != "" && !slices.Contains(cl.Audience, v.audience) {
+		return service.Principal{}, errors.New("invalid audience")
+	}
+	userID := cl.UserID
+	if userID == "" {
+		userID = cl.Subject
+	}
+	return service.Principal{UserID: userID, Scopes: cl.Scopes, ExpiresAt: time.Unix(cl.Expires, 0)}, nil
+}
+
+// key returns the public key for kid, refetching the JWKS when stale or when kid is unknown.
+func (v *JWKSVerifier) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	v.mu.RLock()
+	k, ok := lookupKey(v.keys, kid)
+	stale := v.now().Sub(v.fetched) > v.refresh
+	v.mu.RUnlock()
+	if ok && !stale {
+		return k, nil
+	}
+	if err := v.fetch(ctx); err != nil {
+		if ok {
+			// serve the last known key rather than failing closed on a transient JWKS outage
+			return k, nil
+		}
+		return nil, err
+	}
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	if k, ok := lookupKey(v.keys, kid); ok {
+		return k, nil
+	}
+	return nil, fmt.Errorf("unknown key id %q", kid)
+}
+
+// lookupKey resolves kid; tokens without a kid are accepted when the set holds a single key.
+func lookupKey(keys map[string]*rsa.PublicKey, kid string) (*rsa.PublicKey, bool) {
+	if k, ok := keys[kid]; ok {
+		return k, true
+	}
+	if kid == "" && len(keys) == 1 {
+		for _, k := range keys {
+			return k, true
+		}
+	}
+	return nil, false
+}
+
+func (v *JWKSVerifier) fetch(ctx context.Context) error {
+	v.mu.RLock()
+	recent := v.now().Sub(v.fetched) < time.Second
+	v.mu.RUnlock()
+	if recent {
+		// avoid hammering the JWKS endpoint with tokens carrying bogus key ids
+		return nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("jwks fetch: status %d", resp.StatusCode)
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return err
+	}
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		pub, err := k.rsaPublicKey()
+		if err != nil {
+			return err
+		}
+		keys[k.Kid] = pub
+	}
+	v.mu.Lock()
+	v.keys = keys
+	v.fetched = v.now()
+	v.mu.Unlock()
+	return nil
+}
+
+func (k jwk) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, err
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+}
+
+func decodeSegment(seg string, v any) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// jwksServer serves the public halves of its keys as a JWKS and counts the fetches.
+type jwksServer struct {
+	mu      sync.Mutex
+	keys    map[string]*rsa.PrivateKey
+	fetches int
+}
+
+func (s *jwksServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fetches++
+	set := struct {
+		Keys []jwk `json:"keys"`
+	}{}
+	for kid, k := range s.keys {
+		set.Keys = append(set.Keys, jwk{Kty: "RSA", Kid: kid, Alg: "RS256",
+			N: base64.RawURLEncoding.EncodeToString(k.N.Bytes()),
+			E: base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes())})
+	}
+	_ = json.NewEncoder(w).Encode(set)
+}
+
+func (s *jwksServer) serve(keys map[string]*rsa.PrivateKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = keys
+}
+
+func rsaKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	k, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+// sign returns an RS256 token over claims with kid in its header.
+func sign(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]any) string {
+	t.Helper()
+	seg := func(v any) string {
+		b, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+	signed := seg(map[string]string{"alg": "RS256", "kid": kid}) + "." + seg(claims)
+	sum := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, sum[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func claims(now time.Time, extra map[string]any) map[string]any {
+	c := map[string]any{"iss": "templespace", "sub": "alice", "exp": now.Add(time.Hour).Unix(), "scopes": []string{"booking:read"}}
+	for k, v := range extra {
+		c[k] = v
+	}
+	return c
+}
+
+func newTestJWKSVerifier(t *testing.T, srv *jwksServer, audience string, now *time.Time) *JWKSVerifier {
+	t.Helper()
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+	v := NewJWKSVerifier(ts.URL, "templespace", audience, 10*time.Minute, time.Second)
+	v.now = func() time.Time { return *now }
+	return v
+}
+
+func TestJWKSVerifierRotatesKeys(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	oldKey, newKey := rsaKey(t), rsaKey(t)
+	srv := &jwksServer{keys: map[string]*rsa.PrivateKey{"k1": oldKey}}
+	v := newTestJWKSVerifier(t, srv, "", &now)
+
+	p, err := v.Verify(context.Background(), sign(t, oldKey, "k1", claims(now, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.UserID != "alice" || len(p.Scopes) != 1 || !p.ExpiresAt.Equal(now.Add(time.Hour)) {
+		t.Fatalf("principal %+v", p)
+	}
+
+	// the Auth service starts signing with a new key and publishes both
+	srv.serve(map[string]*rsa.PrivateKey{"k1": oldKey, "k2": newKey})
+	now = now.Add(time.Minute)
+	if _, err := v.Verify(context.Background(), sign(t, newKey, "k2", claims(now, nil))); err != nil {
+		t.Fatalf("token signed with the new key: %v", err)
+	}
+	if _, err := v.Verify(context.Background(), sign(t, oldKey, "k1", claims(now, nil))); err != nil {
+		t.Fatalf("token signed with the old key during rotation: %v", err)
+	}
+	if srv.fetches != 2 {
+		t.Fatalf("%d fetches, want one more for the unknown kid", srv.fetches)
+	}
+
+	// once the old key is retired, the next refresh drops it
+	srv.serve(map[string]*rsa.PrivateKey{"k2": newKey})
+	now = now.Add(11 * time.Minute)
+	if _, err := v.Verify(context.Background(), sign(t, oldKey, "k1", claims(now, nil))); err == nil {
+		t.Fatal("token signed with the retired key verified")
+	}
+	if _, err := v.Verify(context.Background(), sign(t, newKey, "k2", claims(now, nil))); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestJWKSVerifierThrottlesUnknownKids(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	key := rsaKey(t)
+	srv := &jwksServer{keys: map[string]*rsa.PrivateKey{"k1": key}}
+	v := newTestJWKSVerifier(t, srv, "", &now)
+	for i := 0; i < 3; i++ {
+		if _, err := v.Verify(context.Background(), sign(t, key, "bogus", claims(now, nil))); err == nil {
+			t.Fatal("token with an unknown kid verified")
+		}
+	}
+	if srv.fetches != 1 {
+		t.Fatalf("%d fetches for bogus kids within a second, want 1", srv.fetches)
+	}
+}
+
+func TestJWKSVerifierRejects(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	key, other := rsaKey(t), rsaKey(t)
+	srv := &jwksServer{keys: map[string]*rsa.PrivateKey{"k1": key}}
+	tests := []struct {
+		name     string
+		audience string
+		token    string
+		ok       bool
+	}{
+		{"valid", "", sign(t, key, "k1", claims(now, nil)), true},
+		{"audience as string", "booking", sign(t, key, "k1", claims(now, map[string]any{"aud": "booking"})), true},
+		{"audience in list", "booking", sign(t, key, "k1", claims(now, map[string]any{"aud": []string{"space", "booking"}})), true},
+		{"unknown kid", "", sign(t, key, "k9", claims(now, nil)), false},
+		{"signed by another key", "", sign(t, other, "k1", claims(now, nil)), false},
+		{"expired", "", sign(t, key, "k1", claims(now, map[string]any{"exp": now.Add(-time.Second).Unix()})), false},
+		{"wrong issuer", "", sign(t, key, "k1", claims(now, map[string]any{"iss": "elsewhere"})), false},
+		{"wrong audience", "booking", sign(t, key, "k1", claims(now, map[string]any{"aud": "space"})), false},
+		{"missing audience", "booking", sign(t, key, "k1", claims(now, nil)), false},
+		{"unsigned", "", base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." +
+			base64.RawURLEncoding.EncodeToString([]byte(`{"iss":"templespace","sub":"alice","exp":9999999999}`)) + ".", false},
+		{"malformed", "", "not-a-token", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := newTestJWKSVerifier(t, srv, tt.audience, &now)
+			if _, err := v.Verify(context.Background(), tt.token); (err == nil) != tt.ok {
+				t.Fatalf("verified %v, want %v: %v", err == nil, tt.ok, err)
+			}
+		})
+	}
+}
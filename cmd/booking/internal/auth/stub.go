@@ -0,0 +1,21 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"templespace/cmd/booking/internal/service"
+)
+
+// StubVerifier accepts any non-empty token; it stands in for the Auth service during development.
+type StubVerifier struct{}
+
+func NewStubVerifier() *StubVerifier { return &StubVerifier{} }
+
+func (v *StubVerifier) Verify(ctx context.Context, token string) (service.Principal, error) {
+	if strings.TrimSpace(token) == "" {
+		return service.Principal{}, errors.New("missing token")
+	}
+	return service.Principal{UserID: "user-1", Scopes: []string{"booking:create", "booking:read"}}, nil
+}
@@ -2,25 +2,94 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"time"
 )
 
 type Config struct {
-	HTTPAddr     string
-	GRPCAddr     string
+	HTTPAddr string
+	GRPCAddr string
+	// Storage selects the write model: "memory" or "postgres" (uses PostgresURL)
+	Storage      string
 	PostgresURL  string
 	RedisURL     string
 	KafkaBrokers string
 	AuthGRPCAddr string
+	// AuthMode selects the token verifier: "jwks" (local verification), "grpc" (Auth service call) or
+	// "stub", which accepts any token and is only for development
+	AuthMode    string
+	AuthJWKSURL string
+	JWTIssuer   string
+	// JWTAudience, when set, must be named in the aud claim of JWKS-verified tokens
+	JWTAudience  string
+	AuthTimeout  time.Duration
+	AuthRetries  int
+	AuthCacheTTL time.Duration
+	// SpaceGRPCAddr points at the Space service; when empty, space owners get no special access
+	SpaceGRPCAddr string
+	// Currency prices spaces whose attributes carry no "currency"
+	Currency string
+	// HoldTTL is how long an unpaid pending booking blocks its slot; zero disables expiry
+	HoldTTL        time.Duration
+	ExpiryInterval time.Duration
+	// IdempotencyTTL is how long responses to requests with an Idempotency-Key are replayed
+	IdempotencyTTL time.Duration
+	// OutboxInterval is how often the relay publishes stored events
+	OutboxInterval time.Duration
+	// PaymentProviderURL is the payment provider's API; when empty, intents are stubbed and never paid
+	PaymentProviderURL string
+	PaymentAPIKey      string
+	// PaymentWebhookSecret signs provider webhooks; it has no default and is required with PaymentProviderURL.
+	// Without it the webhook endpoint is not served.
+	PaymentWebhookSecret string
+
+	// Events selects the event publisher: "memory" (logs events) or "kafka" (KafkaBrokers)
+	Events string
+	// KafkaTopic receives all booking events, so the events of one booking share a partition and
+	// stay in order; the event type travels in the "event_type" header
+	KafkaTopic string
+	// KafkaAcks is "none", "leader" or "all"
+	KafkaAcks      string
+	KafkaBatchSize int
+	// KafkaLinger is how long a batch waits to fill up before it is sent
+	KafkaLinger time.Duration
+	// KafkaCompression is "none", "gzip", "snappy", "lz4" or "zstd"
+	KafkaCompression string
 }
 
 func FromEnv() *Config {
 	return &Config{
-		HTTPAddr:     getenv("BOOKING_HTTP_ADDR", ":8081"),
-		GRPCAddr:     getenv("BOOKING_GRPC_ADDR", ":9091"),
-		PostgresURL:  getenv("BOOKING_POSTGRES_URL", "postgres://localhost:5432/templespace?sslmode=disable"),
-		RedisURL:     getenv("BOOKING_REDIS_URL", "redis://localhost:6379"),
-		KafkaBrokers: getenv("BOOKING_KAFKA_BROKERS", "localhost:9092"),
-		AuthGRPCAddr: getenv("AUTH_GRPC_ADDR", ":9090"),
+		HTTPAddr:       getenv("BOOKING_HTTP_ADDR", ":8081"),
+		GRPCAddr:       getenv("BOOKING_GRPC_ADDR", ":9091"),
+		Storage:        getenv("BOOKING_STORAGE", "memory"),
+		PostgresURL:    getenv("BOOKING_POSTGRES_URL", "postgres://localhost:5432/templespace?sslmode=disable"),
+		RedisURL:       getenv("BOOKING_REDIS_URL", "redis://localhost:6379"),
+		KafkaBrokers:   getenv("BOOKING_KAFKA_BROKERS", "localhost:9092"),
+		AuthGRPCAddr:   getenv("AUTH_GRPC_ADDR", ":9090"),
+		AuthMode:       getenv("AUTH_MODE", "jwks"),
+		AuthJWKSURL:    getenv("AUTH_JWKS_URL", "http://localhost:8080/.well-known/jwks.json"),
+		JWTIssuer:      getenv("JWT_ISSUER", "templespace"),
+		JWTAudience:    getenv("JWT_AUDIENCE", ""),
+		AuthTimeout:    getduration("AUTH_TIMEOUT", 2*time.Second),
+		AuthRetries:    getint("AUTH_RETRIES", 2),
+		AuthCacheTTL:   getduration("AUTH_CACHE_TTL", 30*time.Second),
+		SpaceGRPCAddr:  getenv("SPACE_GRPC_ADDR", ""),
+		Currency:       getenv("BOOKING_CURRENCY", "EUR"),
+		HoldTTL:        getduration("BOOKING_HOLD_TTL", 15*time.Minute),
+		ExpiryInterval: getduration("BOOKING_EXPIRY_INTERVAL", 30*time.Second),
+		IdempotencyTTL: getduration("BOOKING_IDEMPOTENCY_TTL", 24*time.Hour),
+		OutboxInterval: getduration("BOOKING_OUTBOX_INTERVAL", time.Second),
+
+		PaymentProviderURL:   getenv("PAYMENT_PROVIDER_URL", ""),
+		PaymentAPIKey:        getenv("PAYMENT_API_KEY", ""),
+		PaymentWebhookSecret: getenv("PAYMENT_WEBHOOK_SECRET", ""),
+
+		Events:           getenv("BOOKING_EVENTS", "memory"),
+		KafkaTopic:       getenv("BOOKING_KAFKA_TOPIC", "booking.events"),
+		KafkaAcks:        getenv("BOOKING_KAFKA_ACKS", "all"),
+		KafkaBatchSize:   getint("BOOKING_KAFKA_BATCH_SIZE", 100),
+		KafkaLinger:      getduration("BOOKING_KAFKA_LINGER", 10*time.Millisecond),
+		KafkaCompression: getenv("BOOKING_KAFKA_COMPRESSION", "snappy"),
 	}
 }
 
@@ -30,3 +99,17 @@ func getenv(key, def string) string {
 	}
 	return def
 }
+
+func getint(key string, def int) int {
+	if n, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return n
+	}
+	return def
+}
+
+func getduration(key string, def time.Duration) time.Duration {
+	if d, err := time.ParseDuration(os.Getenv(key)); err == nil {
+		return d
+	}
+	return def
+}
@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// Interval is a half-open time range [Start, End).
+type Interval struct {
+	Start time.Time
+	End   time.Time
+}
+
+// FreeIntervals subtracts busy from window and returns what remains, in order. With a positive
+// granularity each free interval is shrunk inward to granularity boundaries on the wall clock of loc
+// (see TruncateIn); intervals too short to hold a whole step are dropped.
+func FreeIntervals(window Interval, busy []Interval, granularity time.Duration, loc *time.Location) []Interval {
+	sorted := make([]Interval, len(busy))
+	copy(sorted, busy)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start.Before(sorted[j].Start) })
+
+	var free []Interval
+	cursor := window.Start
+	for _, b := range sorted {
+		if !b.End.After(cursor) {
+			continue
+		}
+		if !b.Start.Before(window.End) {
+			break
+		}
+		if b.Start.After(cursor) {
+			free = appendSnapped(free, Interval{Start: cursor, End: b.Start}, granularity, loc)
+		}
+		cursor = b.End
+	}
+	if cursor.Before(window.End) {
+		free = appendSnapped(free, Interval{Start: cursor, End: window.End}, granularity, loc)
+	}
+	return free
+}
+
+func appendSnapped(out []Interval, iv Interval, granularity time.Duration, loc *time.Location) []Interval {
+	if granularity > 0 {
+		start := TruncateIn(iv.Start, granularity, loc)
+		if start.Before(iv.Start) {
+			start = TruncateIn(start.Add(granularity), granularity, loc)
+		}
+		iv = Interval{Start: start, End: TruncateIn(iv.End, granularity, loc)}
+	}
+	if !iv.Start.Before(iv.End) {
+		return out
+	}
+	return append(out, iv)
+}
+
+// TruncateIn rounds t down to a multiple of step counted on the wall clock of loc, so 1h snaps to the
+// local hour and 24h to local midnight; nil loc means UTC.
+func TruncateIn(t time.Time, step time.Duration, loc *time.Location) time.Time {
+	if loc == nil {
+		return t.Truncate(step)
+	}
+	_, offset := t.In(loc).Zone()
+	shift := time.Duration(offset) * time.Second
+	return t.Add(shift).Truncate(step).Add(-shift)
+}
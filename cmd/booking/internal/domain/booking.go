@@ -1,6 +1,10 @@
 package domain
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 type BookingStatus string
 
@@ -9,29 +13,160 @@ const (
 	StatusConfirmed BookingStatus = "confirmed"
 	StatusPaid      BookingStatus = "paid"
 	StatusCancelled BookingStatus = "cancelled"
+	StatusExpired   BookingStatus = "expired"
+	StatusCompleted BookingStatus = "completed"
+	StatusNoShow    BookingStatus = "no_show"
+)
+
+var (
+	ErrNotFound         = errors.New("not found")
+	ErrSlotNotAvailable = errors.New("slot not available")
+	ErrUnauthorized     = errors.New("unauthorized")
+	ErrForbidden        = errors.New("forbidden")
+	ErrConflict         = errors.New("version conflict")
+	// ErrInvalidInput is matched by every *InputError.
+	ErrInvalidInput = errors.New("invalid input")
+	// ErrNotEnabled reports a feature this deployment runs without, e.g. "vouchers are not enabled".
+	ErrNotEnabled = errors.New("not enabled")
 )
 
+// InputError marks a request the caller has to correct, as opposed to a failure of the service.
+// Its message is that of Err.
+type InputError struct {
+	Err error
+}
+
+func (e *InputError) Error() string { return e.Err.Error() }
+
+func (e *InputError) Unwrap() error { return e.Err }
+
+func (e *InputError) Is(target error) bool { return target == ErrInvalidInput }
+
+// Invalid wraps err in an *InputError; it returns nil for a nil err.
+func Invalid(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &InputError{Err: err}
+}
+
+// ConflictError reports a write whose expected version no longer matches the stored booking.
+// It matches ErrConflict under errors.Is.
+type ConflictError struct {
+	ID       string
+	Expected int
+	Actual   int
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("version conflict on booking %s: expected %d, found %d", e.ID, e.Expected, e.Actual)
+}
+
+func (e *ConflictError) Is(target error) bool { return target == ErrConflict }
+
+// SeriesConflictError lists the occurrences of a recurring booking whose slots are taken.
+// It matches ErrSlotNotAvailable under errors.Is.
+type SeriesConflictError struct {
+	Conflicts []Interval
+}
+
+func (e *SeriesConflictError) Error() string {
+	return fmt.Sprintf("%s: %d occurrences conflict", ErrSlotNotAvailable, len(e.Conflicts))
+}
+
+func (e *SeriesConflictError) Is(target error) bool { return target == ErrSlotNotAvailable }
+
 type Booking struct {
-	ID        string
-	SpaceID   string
-	UserID    string
+	ID      string
+	SpaceID string
+	UserID  string
+	// SeriesID links the occurrences of a recurring booking; empty for one-off bookings.
+	SeriesID  string
 	SlotStart time.Time
 	SlotEnd   time.Time
-	Status    BookingStatus
-	Version   int
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	// BufferBefore and BufferAfter extend the time the booking keeps the space busy; they are copied
+	// from the space's policy when the slot is booked.
+	BufferBefore time.Duration
+	BufferAfter  time.Duration
+	// Seats is how many places the booking takes in a shared space.
+	Seats int
+	// Capacity is the capacity of the shared space the booking was checked against; zero books the
+	// space exclusively. Like the buffers it is copied from the space's policy.
+	Capacity int
+	// Total is the quoted price stored when the booking is made, net of any voucher.
+	Total Money
+	// VoucherCode is the voucher redeemed for the booking, if any.
+	VoucherCode string
+	// Refunded is what was paid back when a paid booking was cancelled, in the currency of Total.
+	Refunded Money
+	Status   BookingStatus
+	Version  int
+	// HoldExpiresAt is when an unpaid pending booking releases its slot; zero means never.
+	HoldExpiresAt time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	// Outbox holds the events recorded against the booking since it was loaded. The repository stores
+	// them in the same transaction as the next write of the booking and then clears it.
+	Outbox []OutboxMessage `json:"-"`
+	// History holds the changes made to the booking since it was loaded; the repository appends them
+	// to the booking's event log with the next write and then clears it.
+	History []HistoryEvent `json:"-"`
+	// Redemption is the voucher use to store with the booking's creation. The repository redeems it in
+	// the same transaction as the insert, so a booking never stands without its redemption or the
+	// other way round, and then clears it.
+	Redemption *Redemption `json:"-"`
+}
+
+// Record queues an event to be stored with the next write of the booking.
+func (b *Booking) Record(topic string, payload []byte, at time.Time) {
+	b.Outbox = append(b.Outbox, OutboxMessage{Topic: topic, Key: b.ID, Payload: payload, CreatedAt: at})
+}
+
+// Blocked is the interval the booking keeps its space busy, buffers included.
+func (b *Booking) Blocked() Interval {
+	return Interval{Start: b.SlotStart.Add(-b.BufferBefore), End: b.SlotEnd.Add(b.BufferAfter)}
 }
 
+// BookingRepository detects overlaps on Blocked intervals, so buffers of both bookings count.
+// Writes of slot-holding bookings are checked with Booking.Fits, so shared spaces take overlapping
+// bookings up to their capacity.
+//
+// Every write also stores the events recorded on the written bookings (Booking.Outbox) in the outbox,
+// atomically with the booking, and clears them once committed. Likewise every write appends the
+// booking's History to its event log; GetByID folds that log, so it is the record of truth, while the
+// stored rows serve the overlap queries.
 type BookingRepository interface {
-	Create(b *Booking) error
-	Update(b *Booking) error
+	BookingHistory
+	// CreateIfAvailable stores b only if it fits next to the live bookings of the same space,
+	// as one atomic step; otherwise it returns ErrSlotNotAvailable. The Redemption of b is redeemed in
+	// the same step, failing the whole write with a *VoucherError when the voucher's limits are reached.
+	CreateIfAvailable(b *Booking) error
+	// CreateAllIfAvailable stores every booking or none: if any slot is taken (or two of them overlap)
+	// it returns a *SeriesConflictError listing the conflicting slots.
+	CreateAllIfAvailable(bs []*Booking) error
+	// CreateAvailable stores, in one atomic step, every booking that fits next to the live bookings and
+	// the ones stored before it, and returns the slots of those skipped because they did not fit.
+	CreateAvailable(bs []*Booking) ([]Interval, error)
+	// Update overwrites the stored booking only if its version still equals expectedVersion,
+	// returning a *ConflictError otherwise. A slot-holding booking must still fit next to the other
+	// live bookings of the space (ErrSlotNotAvailable otherwise); its own old slot does not count.
+	Update(b *Booking, expectedVersion int) error
 	GetByID(id string) (*Booking, error)
-	IsAvailable(spaceID string, start, end time.Time) (bool, error)
+	// ListOverlapping returns the bookings of spaceID whose blocked interval overlaps [start, end).
+	ListOverlapping(spaceID string, start, end time.Time) ([]*Booking, error)
+	// ListBySeries returns the occurrences of a recurring booking ordered by slot start.
+	ListBySeries(seriesID string) ([]*Booking, error)
+	// ListExpiredHolds returns up to limit pending bookings whose hold expired at or before now.
+	ListExpiredHolds(now time.Time, limit int) ([]*Booking, error)
 }
 
 type ReadModel interface {
-	CacheAvailability(spaceID string, start, end time.Time, available bool) error
+	// Invalidate drops the cached free slots of a space after a write changed its bookings.
+	Invalidate(spaceID string) error
+	FreeSlots(spaceID string, from, to time.Time, granularity time.Duration) ([]FreeSlot, bool)
+	CacheFreeSlots(spaceID string, from, to time.Time, granularity time.Duration, free []FreeSlot) error
+	// Reset drops everything cached, before the model is rebuilt from the event log.
+	Reset() error
 }
 
 type EventPublisher interface {
@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// RefundTier refunds Percent of the price of a booking cancelled at least Before ahead of its start.
+type RefundTier struct {
+	Before  time.Duration
+	Percent float64
+}
+
+// CancellationPolicy sets how much of a paid booking is refunded when the guest cancels it. The tier
+// with the longest notice that a cancellation meets applies; one meeting none is refunded nothing. A
+// policy without tiers refunds in full until the booking starts.
+type CancellationPolicy struct {
+	Tiers []RefundTier
+}
+
+// RefundPercent returns the share of the price refunded for cancelling a booking starting at start at now.
+func (p CancellationPolicy) RefundPercent(start, now time.Time) float64 {
+	notice := start.Sub(now)
+	if len(p.Tiers) == 0 {
+		if notice > 0 {
+			return 100
+		}
+		return 0
+	}
+	best := -1
+	for i, t := range p.Tiers {
+		if notice >= t.Before && (best < 0 || t.Before > p.Tiers[best].Before) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return 0
+	}
+	return p.Tiers[best].Percent
+}
+
+// RefundOf returns percent of total, rounded half up to the minor unit.
+func RefundOf(total Money, percent float64) Money {
+	bp := int64(math.Round(percent * basisPoints / 100))
+	return Money{Amount: mulDiv(total.Amount, bp, basisPoints), Currency: total.Currency}
+}
+
+// cancellationFromAttributes reads "cancellation_policy", a list of tiers such as
+// [{"before": "48h", "refund_percent": 100}, {"before": "1d", "refund_percent": 50}].
+func cancellationFromAttributes(raw any) (CancellationPolicy, error) {
+	var p CancellationPolicy
+	list, ok := raw.([]any)
+	if !ok {
+		return p, errors.New("cancellation_policy must be a list of tiers")
+	}
+	for i, item := range list {
+		m, ok := item.(map[string]any)
+		if !ok {
+			return p, fmt.Errorf("cancellation_policy[%d] must be an object", i)
+		}
+		before, _ := m["before"].(string)
+		d, err := parsePolicyDuration(before)
+		if err != nil || d < 0 {
+			return p, fmt.Errorf("cancellation_policy[%d]: invalid before %q", i, before)
+		}
+		pct, ok := m["refund_percent"].(float64)
+		if !ok || pct < 0 || pct > 100 {
+			return p, fmt.Errorf("cancellation_policy[%d]: refund_percent must be between 0 and 100", i)
+		}
+		p.Tiers = append(p.Tiers, RefundTier{Before: d, Percent: pct})
+	}
+	sort.Slice(p.Tiers, func(i, j int) bool { return p.Tiers[i].Before > p.Tiers[j].Before })
+	for i := 1; i < len(p.Tiers); i++ {
+		if p.Tiers[i].Before == p.Tiers[i-1].Before {
+			return p, fmt.Errorf("cancellation_policy: two tiers for %s", p.Tiers[i].Before)
+		}
+	}
+	return p, nil
+}
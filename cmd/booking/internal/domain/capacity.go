@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// Occupancy is a number of seats taken during an interval.
+type Occupancy struct {
+	Interval
+	Seats int
+}
+
+// FreeSlot is a free interval together with how many seats remain in it; exclusive spaces have one seat.
+type FreeSlot struct {
+	Interval
+	Seats int
+}
+
+// Occupancy returns the seats the booking takes over its blocked interval. An exclusive booking takes
+// every seat, which is reported as capacity (the capacity of the space being checked, at least 1).
+func (b *Booking) Occupancy(capacity int) Occupancy {
+	seats := b.Seats
+	if b.Capacity == 0 || seats < 1 {
+		seats = max(capacity, 1)
+	}
+	return Occupancy{Interval: b.Blocked(), Seats: seats}
+}
+
+// Fits reports whether b can be added next to the live bookings others, which must all belong to
+// b's space and already overlap b's blocked interval. Exclusive bookings tolerate no overlap at all;
+// shared ones need the summed seats to stay within b.Capacity at every instant.
+func (b *Booking) Fits(others []*Booking) bool {
+	if b.Capacity == 0 {
+		return len(others) == 0
+	}
+	occ := make([]Occupancy, 0, len(others))
+	for _, o := range others {
+		occ = append(occ, o.Occupancy(b.Capacity))
+	}
+	return PeakSeats(b.Blocked(), occ)+b.Seats <= b.Capacity
+}
+
+// PeakSeats returns the largest number of seats taken at any instant of window.
+func PeakSeats(window Interval, occ []Occupancy) int {
+	peak := 0
+	for _, seg := range seatProfile(window, occ) {
+		peak = max(peak, seg.Seats)
+	}
+	return peak
+}
+
+// FreeSeats subtracts occ from capacity over window and returns the intervals with at least one seat
+// left, each with its remaining seats. Adjacent intervals have different seat counts. With a positive
+// granularity each interval is shrunk inward to granularity boundaries in loc as in FreeIntervals.
+func FreeSeats(window Interval, occ []Occupancy, capacity int, granularity time.Duration, loc *time.Location) []FreeSlot {
+	var out []FreeSlot
+	for _, seg := range seatProfile(window, occ) {
+		left := capacity - seg.Seats
+		if left <= 0 {
+			continue
+		}
+		if n := len(out); n > 0 && out[n-1].Seats == left && out[n-1].End.Equal(seg.Start) {
+			out[n-1].End = seg.End
+			continue
+		}
+		out = append(out, FreeSlot{Interval: seg.Interval, Seats: left})
+	}
+	snapped := out[:0]
+	for _, fs := range out {
+		for _, iv := range appendSnapped(nil, fs.Interval, granularity, loc) {
+			snapped = append(snapped, FreeSlot{Interval: iv, Seats: fs.Seats})
+		}
+	}
+	return snapped
+}
+
+// seatProfile splits window at every occupancy boundary and sums the seats taken in each piece.
+func seatProfile(window Interval, occ []Occupancy) []Occupancy {
+	type edge struct {
+		at    time.Time
+		delta int
+	}
+	var edges []edge
+	for _, o := range occ {
+		start, end := o.Start, o.End
+		if start.Before(window.Start) {
+			start = window.Start
+		}
+		if end.After(window.End) {
+			end = window.End
+		}
+		if !start.Before(end) {
+			continue
+		}
+		edges = append(edges, edge{start, o.Seats}, edge{end, -o.Seats})
+	}
+	sort.Slice(edges, func(i, j int) bool { return edges[i].at.Before(edges[j].at) })
+
+	var out []Occupancy
+	cursor, taken := window.Start, 0
+	for _, e := range edges {
+		if e.at.After(cursor) {
+			out = append(out, Occupancy{Interval: Interval{Start: cursor, End: e.at}, Seats: taken})
+			cursor = e.at
+		}
+		taken += e.delta
+	}
+	if cursor.Before(window.End) {
+		out = append(out, Occupancy{Interval: Interval{Start: cursor, End: window.End}, Seats: taken})
+	}
+	return out
+}
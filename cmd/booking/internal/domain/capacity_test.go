@@ -0,0 +1,118 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+var day = time.Date(2030, 3, 4, 0, 0, 0, 0, time.UTC)
+
+// at returns the interval [day+from, day+to) hours.
+func at(from, to int) Interval {
+	return Interval{Start: day.Add(time.Duration(from) * time.Hour), End: day.Add(time.Duration(to) * time.Hour)}
+}
+
+// seated returns a booking of seats over [from, to) hours in a space of capacity; 0 is exclusive.
+func seated(from, to, seats, capacity int) *Booking {
+	iv := at(from, to)
+	return &Booking{SlotStart: iv.Start, SlotEnd: iv.End, Seats: seats, Capacity: capacity}
+}
+
+func TestPeakSeats(t *testing.T) {
+	tests := []struct {
+		name string
+		occ  []Occupancy
+		want int
+	}{
+		{"empty", nil, 0},
+		{"back to back", []Occupancy{{at(9, 10), 3}, {at(10, 11), 4}}, 4},
+		{"overlapping", []Occupancy{{at(9, 11), 3}, {at(10, 12), 4}}, 7},
+		{"nested", []Occupancy{{at(9, 17), 1}, {at(10, 11), 2}, {at(10, 12), 2}}, 5},
+		{"ends where another starts", []Occupancy{{at(9, 10), 2}, {at(10, 12), 2}, {at(8, 10), 1}}, 3},
+		{"outside the window", []Occupancy{{at(6, 9), 5}, {at(12, 14), 5}, {at(10, 11), 1}}, 1},
+		{"clipped to the window", []Occupancy{{at(6, 10), 2}, {at(11, 14), 2}}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PeakSeats(at(9, 12), tt.occ); got != tt.want {
+				t.Fatalf("PeakSeats() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFits(t *testing.T) {
+	tests := []struct {
+		name   string
+		b      *Booking
+		others []*Booking
+		fits   bool
+	}{
+		{"exclusive in an empty slot", seated(9, 10, 1, 0), nil, true},
+		{"exclusive next to a booking", seated(9, 10, 1, 0), []*Booking{seated(8, 10, 1, 0)}, false},
+		{"shared up to capacity", seated(9, 10, 2, 5), []*Booking{seated(9, 10, 3, 5)}, true},
+		{"shared over capacity", seated(9, 10, 3, 5), []*Booking{seated(9, 10, 3, 5)}, false},
+		{"full room", seated(9, 10, 1, 5), []*Booking{seated(8, 12, 5, 5)}, false},
+		// bookings before and after never take seats at the same instant
+		{"peaks apart", seated(9, 12, 2, 5), []*Booking{seated(9, 10, 3, 5), seated(10, 12, 3, 5)}, true},
+		{"peaks stack", seated(9, 12, 2, 5), []*Booking{seated(9, 11, 2, 5), seated(10, 12, 2, 5)}, false},
+		{"exclusive booking takes the shared room", seated(9, 10, 1, 5), []*Booking{seated(9, 10, 1, 0)}, false},
+		{"buffer reaches the other booking", &Booking{SlotStart: at(10, 11).Start, SlotEnd: at(10, 11).End, Seats: 1,
+			BufferBefore: 30 * time.Minute}, []*Booking{seated(9, 10, 1, 0)}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.b.Fits(tt.others); got != tt.fits {
+				t.Fatalf("Fits() = %v, want %v", got, tt.fits)
+			}
+		})
+	}
+}
+
+func TestFitsSeatUpdate(t *testing.T) {
+	// repositories check an update against the other bookings only, so its own old seats do not count
+	others := []*Booking{seated(9, 12, 2, 5), seated(10, 11, 1, 5)}
+	for seats, fits := range map[int]bool{1: true, 2: true, 3: false, 5: false} {
+		if got := seated(9, 12, seats, 5).Fits(others); got != fits {
+			t.Errorf("%d seats: Fits() = %v, want %v", seats, got, fits)
+		}
+	}
+	// the room shrinking below what is booked
+	if seated(9, 12, 1, 3).Fits(others) {
+		t.Error("fits into a room of 3 next to 3 taken seats")
+	}
+}
+
+func TestFreeSeats(t *testing.T) {
+	occ := []Occupancy{{at(9, 11), 2}, {at(10, 12), 3}, {at(13, 14), 1}}
+	got := FreeSeats(at(8, 15), occ, 5, 0, time.UTC)
+	want := []FreeSlot{
+		{at(8, 9), 5},
+		{at(9, 10), 3},
+		{at(11, 12), 2},
+		{at(12, 13), 5},
+		{at(13, 14), 4},
+		{at(14, 15), 5},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("FreeSeats() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if !got[i].Start.Equal(want[i].Start) || !got[i].End.Equal(want[i].End) || got[i].Seats != want[i].Seats {
+			t.Fatalf("FreeSeats()[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	// a full room leaves nothing; equal neighbours merge
+	if got := FreeSeats(at(9, 12), []Occupancy{{at(8, 13), 5}}, 5, 0, time.UTC); len(got) != 0 {
+		t.Fatalf("full room: %v", got)
+	}
+	if got := FreeSeats(at(9, 12), []Occupancy{{at(9, 10), 1}, {at(10, 11), 1}}, 5, 0, time.UTC); len(got) != 2 || got[0].End != at(0, 11).End {
+		t.Fatalf("merged: %v", got)
+	}
+	// granularity shrinks intervals to whole hours
+	got = FreeSeats(at(9, 12), []Occupancy{{Interval{Start: at(9, 10).Start, End: at(9, 10).End.Add(15 * time.Minute)}, 5}}, 5, time.Hour, time.UTC)
+	if len(got) != 1 || !got[0].Start.Equal(at(11, 12).Start) || got[0].Seats != 5 {
+		t.Fatalf("snapped: %v", got)
+	}
+}
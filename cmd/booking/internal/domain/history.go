@@ -0,0 +1,201 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// History event types besides the status a change moved the booking to (confirmed, paid, cancelled,
+// expired, completed, no_show).
+const (
+	HistoryCreated     = "created"
+	HistoryRescheduled = "rescheduled"
+	// HistoryImported starts the stream of a booking stored before the event log existed; it holds
+	// the booking as it was then.
+	HistoryImported = "imported"
+	HistoryUpdated  = "updated"
+)
+
+// SnapshotEvery is how many events a stream grows between snapshots, so loading a booking never
+// folds more than that many events.
+const SnapshotEvery = 20
+
+// HistoryEvent is one entry of a booking's append-only history. The booking's state is the fold of
+// its events in version order, see Replay.
+type HistoryEvent struct {
+	BookingID string
+	// Version is the booking version the event produced.
+	Version int
+	Type    string
+	// Actor is the user who made the change, or "system:<component>" for background jobs.
+	Actor  string
+	At     time.Time
+	Change BookingChange
+}
+
+// BookingChange holds the fields an event set; nil fields kept their value. The first event of a
+// stream sets every field that is not zero.
+type BookingChange struct {
+	SpaceID       *string        `json:"space_id,omitempty"`
+	UserID        *string        `json:"user_id,omitempty"`
+	SeriesID      *string        `json:"series_id,omitempty"`
+	SlotStart     *time.Time     `json:"slot_start,omitempty"`
+	SlotEnd       *time.Time     `json:"slot_end,omitempty"`
+	BufferBefore  *time.Duration `json:"buffer_before,omitempty"`
+	BufferAfter   *time.Duration `json:"buffer_after,omitempty"`
+	Seats         *int           `json:"seats,omitempty"`
+	Capacity      *int           `json:"capacity,omitempty"`
+	Total         *Money         `json:"total,omitempty"`
+	VoucherCode   *string        `json:"voucher_code,omitempty"`
+	Refunded      *Money         `json:"refunded,omitempty"`
+	Status        *BookingStatus `json:"status,omitempty"`
+	HoldExpiresAt *time.Time     `json:"hold_expires_at,omitempty"`
+	CreatedAt     *time.Time     `json:"created_at,omitempty"`
+}
+
+// NewHistoryEvent describes how actor turned before into after, which carries the new version and
+// update time. A nil before makes it the created event.
+func NewHistoryEvent(before, after *Booking, actor string) HistoryEvent {
+	typ := HistoryUpdated
+	switch {
+	case before == nil:
+		typ, before = HistoryCreated, &Booking{}
+	case !after.SlotStart.Equal(before.SlotStart) || !after.SlotEnd.Equal(before.SlotEnd):
+		typ = HistoryRescheduled
+	case after.Status != before.Status:
+		typ = string(after.Status)
+	}
+	return HistoryEvent{
+		BookingID: after.ID,
+		Version:   after.Version,
+		Type:      typ,
+		Actor:     actor,
+		At:        after.UpdatedAt,
+		Change:    Diff(before, after),
+	}
+}
+
+// Diff returns the change that turns before into after; versions and update times are left out.
+func Diff(before, after *Booking) BookingChange {
+	var c BookingChange
+	c.SpaceID = changed(before.SpaceID, after.SpaceID)
+	c.UserID = changed(before.UserID, after.UserID)
+	c.SeriesID = changed(before.SeriesID, after.SeriesID)
+	c.SlotStart = changedTime(before.SlotStart, after.SlotStart)
+	c.SlotEnd = changedTime(before.SlotEnd, after.SlotEnd)
+	c.BufferBefore = changed(before.BufferBefore, after.BufferBefore)
+	c.BufferAfter = changed(before.BufferAfter, after.BufferAfter)
+	c.Seats = changed(before.Seats, after.Seats)
+	c.Capacity = changed(before.Capacity, after.Capacity)
+	c.Total = changed(before.Total, after.Total)
+	c.VoucherCode = changed(before.VoucherCode, after.VoucherCode)
+	c.Refunded = changed(before.Refunded, after.Refunded)
+	c.Status = changed(before.Status, after.Status)
+	c.HoldExpiresAt = changedTime(before.HoldExpiresAt, after.HoldExpiresAt)
+	c.CreatedAt = changedTime(before.CreatedAt, after.CreatedAt)
+	return c
+}
+
+func changed[T comparable](before, after T) *T {
+	if before == after {
+		return nil
+	}
+	return &after
+}
+
+func changedTime(before, after time.Time) *time.Time {
+	if before.Equal(after) {
+		return nil
+	}
+	return &after
+}
+
+// Apply folds e into b. The first event of a stream may start at any version (an imported booking
+// keeps its version); every later one must be the next version of b.
+func (b *Booking) Apply(e HistoryEvent) error {
+	if b.Version != 0 && e.Version != b.Version+1 || b.Version == 0 && e.Version < 1 {
+		return fmt.Errorf("booking %s: event version %d does not follow version %d", e.BookingID, e.Version, b.Version)
+	}
+	c := e.Change
+	b.ID = e.BookingID
+	set(&b.SpaceID, c.SpaceID)
+	set(&b.UserID, c.UserID)
+	set(&b.SeriesID, c.SeriesID)
+	set(&b.SlotStart, c.SlotStart)
+	set(&b.SlotEnd, c.SlotEnd)
+	set(&b.BufferBefore, c.BufferBefore)
+	set(&b.BufferAfter, c.BufferAfter)
+	set(&b.Seats, c.Seats)
+	set(&b.Capacity, c.Capacity)
+	set(&b.Total, c.Total)
+	set(&b.VoucherCode, c.VoucherCode)
+	set(&b.Refunded, c.Refunded)
+	set(&b.Status, c.Status)
+	set(&b.HoldExpiresAt, c.HoldExpiresAt)
+	set(&b.CreatedAt, c.CreatedAt)
+	b.Version = e.Version
+	b.UpdatedAt = e.At
+	return nil
+}
+
+func set[T any](field *T, v *T) {
+	if v != nil {
+		*field = *v
+	}
+}
+
+// Replay folds events onto a copy of snapshot, or onto an empty booking when snapshot is nil, and
+// returns ErrNotFound when that leaves no booking.
+func Replay(snapshot *Booking, events []HistoryEvent) (*Booking, error) {
+	b := &Booking{}
+	if snapshot != nil {
+		cp := *snapshot
+		b = &cp
+	}
+	for _, e := range events {
+		if err := b.Apply(e); err != nil {
+			return nil, err
+		}
+	}
+	if b.ID == "" {
+		return nil, ErrNotFound
+	}
+	return b, nil
+}
+
+// Track records the change from before to b by actor in b's history, to be stored with the next
+// write of b.
+func (b *Booking) Track(before *Booking, actor string) {
+	b.History = append(b.History, NewHistoryEvent(before, b, actor))
+}
+
+// CheckHistory verifies that the tracked history of b leads up to b's version, so the event log
+// still folds to b once the write is stored.
+func (b *Booking) CheckHistory() error {
+	if n := len(b.History); n == 0 || b.History[n-1].Version != b.Version {
+		return fmt.Errorf("booking %s: version %d written without its history event", b.ID, b.Version)
+	}
+	return nil
+}
+
+// SnapshotDue reports whether appending events takes the stream past a multiple of SnapshotEvery,
+// after which the repository stores a snapshot of the booking.
+func SnapshotDue(events []HistoryEvent) bool {
+	for _, e := range events {
+		if e.Version%SnapshotEvery == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// BookingHistory is the event log behind a BookingRepository.
+type BookingHistory interface {
+	// History returns the events of a booking in version order, or ErrNotFound.
+	History(id string) ([]HistoryEvent, error)
+	// ReplayAll rebuilds every booking from the log and hands it to fn, stopping at fn's first error.
+	ReplayAll(fn func(b *Booking) error) error
+	// Reproject overwrites the stored row of b, which serves the overlap and listing queries, with b as
+	// folded from the log. A row at a later version than b, written meanwhile, is kept.
+	Reproject(b *Booking) error
+}
@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// changes returns the history of a booking created at 09:00, confirmed, then moved an hour later.
+func changes() []HistoryEvent {
+	at := time.Date(2030, 3, 4, 9, 0, 0, 0, time.UTC)
+	created := &Booking{ID: "b-1", SpaceID: "s1", UserID: "alice", SlotStart: at, SlotEnd: at.Add(time.Hour), Seats: 1,
+		Status: StatusPending, Version: 1, CreatedAt: at, UpdatedAt: at}
+	confirmed := *created
+	confirmed.Status, confirmed.Version, confirmed.UpdatedAt = StatusConfirmed, 2, at.Add(time.Minute)
+	moved := confirmed
+	moved.SlotStart, moved.SlotEnd, moved.Version, moved.UpdatedAt = at.Add(time.Hour), at.Add(2*time.Hour), 3, at.Add(2*time.Minute)
+	return []HistoryEvent{
+		NewHistoryEvent(nil, created, "alice"),
+		NewHistoryEvent(created, &confirmed, "admin"),
+		NewHistoryEvent(&confirmed, &moved, "alice"),
+	}
+}
+
+func TestReplay(t *testing.T) {
+	events := changes()
+	if got := []string{events[0].Type, events[1].Type, events[2].Type}; got[0] != HistoryCreated || got[1] != string(StatusConfirmed) || got[2] != HistoryRescheduled {
+		t.Fatalf("event types %v", got)
+	}
+	b, err := Replay(nil, events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2030, 3, 4, 9, 0, 0, 0, time.UTC)
+	if b.ID != "b-1" || b.Version != 3 || b.Status != StatusConfirmed || !b.SlotStart.Equal(at.Add(time.Hour)) ||
+		!b.CreatedAt.Equal(at) || !b.UpdatedAt.Equal(at.Add(2*time.Minute)) || b.UserID != "alice" {
+		t.Fatalf("replayed %+v", b)
+	}
+
+	// a snapshot at version 2 folds only the later events and is left unchanged
+	snap, err := Replay(nil, events[:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromSnap, err := Replay(snap, events[2:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fromSnap, b) || snap.Version != 2 {
+		t.Fatalf("from snapshot %+v, snapshot now at version %d", fromSnap, snap.Version)
+	}
+
+	if _, err := Replay(nil, nil); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("empty stream: %v", err)
+	}
+	if _, err := Replay(nil, []HistoryEvent{events[0], events[2]}); err == nil {
+		t.Fatal("replayed a stream with a missing version")
+	}
+	if _, err := Replay(snap, events[1:]); err == nil {
+		t.Fatal("replayed an event the snapshot already holds")
+	}
+}
+
+func TestSnapshotDue(t *testing.T) {
+	for _, tt := range []struct {
+		versions []int
+		due      bool
+	}{
+		{[]int{1}, false},
+		{[]int{SnapshotEvery - 1}, false},
+		{[]int{SnapshotEvery}, true},
+		{[]int{SnapshotEvery - 1, SnapshotEvery, SnapshotEvery + 1}, true},
+		{[]int{2*SnapshotEvery + 1}, false},
+	} {
+		var events []HistoryEvent
+		for _, v := range tt.versions {
+			events = append(events, HistoryEvent{Version: v})
+		}
+		if got := SnapshotDue(events); got != tt.due {
+			t.Errorf("versions %v: due %v, want %v", tt.versions, got, tt.due)
+		}
+	}
+}
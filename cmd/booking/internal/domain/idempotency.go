@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrIdempotencyMismatch reports an idempotency key reused for a different request.
+	ErrIdempotencyMismatch = errors.New("idempotency key reused with a different request")
+	// ErrIdempotencyInFlight reports a retry that gave up waiting for the first request with its key.
+	ErrIdempotencyInFlight = errors.New("a request with this idempotency key is still in progress")
+	// ErrIdempotencyClaimLost reports a request whose claim on a key was taken over after its lease ran out.
+	ErrIdempotencyClaimLost = errors.New("idempotency claim was taken over")
+)
+
+// IdempotentResponse is the stored outcome of a request, replayed to retries that reuse its key.
+type IdempotentResponse struct {
+	// Status is the HTTP status; zero for gRPC responses.
+	Status int
+	Header map[string]string
+	Body   []byte
+	// Retryable marks a response that is answered but not stored, such as a server error, so a retry
+	// runs the request again.
+	Retryable bool `json:"-"`
+}
+
+// IdempotencyRecord ties a key to the request first sent with it. Fingerprint identifies that request;
+// Response is nil while it is still being handled. Until then ExpiresAt is a short lease, after which
+// the claim is taken to belong to a request that died and may be taken over. Token is unique to each
+// claim and fences the completion of a key off from requests whose claim was taken over.
+type IdempotencyRecord struct {
+	Key         string
+	Token       string
+	Fingerprint string
+	Response    *IdempotentResponse
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
+
+type IdempotencyStore interface {
+	// Claim stores rec unless an unexpired record holds its key, in which case it returns that record
+	// and stores nothing. Lookup and insert are atomic, so only one request claims a key.
+	Claim(rec *IdempotencyRecord) (*IdempotencyRecord, error)
+	// Complete stores the response of the claim of key holding token and keeps it until expiresAt. It
+	// returns ErrIdempotencyClaimLost, storing nothing, when the key is no longer claimed with token.
+	Complete(key, token string, resp *IdempotentResponse, expiresAt time.Time) error
+	// Release drops the claim of key holding token without a response, letting a retry run the request
+	// again. A claim with another token is left alone.
+	Release(key, token string) error
+	// Purge deletes the records expired at now and returns how many there were.
+	Purge(now time.Time) (int, error)
+}
@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// Money is an amount in the minor unit of its currency (cents for EUR), so sums never lose precision.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+// minorDigits lists currencies whose minor unit is not 1/100 of the major one (ISO 4217).
+var minorDigits = map[string]int{
+	"JPY": 0, "KRW": 0, "ISK": 0, "CLP": 0, "VND": 0,
+	"BHD": 3, "KWD": 3, "OMR": 3, "JOD": 3, "TND": 3,
+}
+
+// MinorDigits returns how many decimal places the minor unit of currency has.
+func MinorDigits(currency string) int {
+	if d, ok := minorDigits[strings.ToUpper(currency)]; ok {
+		return d
+	}
+	return 2
+}
+
+// MoneyFromMajor converts a major-unit amount such as 15.5 EUR into Money, rounding half away from zero.
+func MoneyFromMajor(major float64, currency string) Money {
+	scale := math.Pow10(MinorDigits(currency))
+	return Money{Amount: int64(math.Round(major * scale)), Currency: strings.ToUpper(currency)}
+}
+
+// Decimal renders the amount in major units with the currency's number of decimals, e.g. "15.50".
+func (m Money) Decimal() string {
+	digits := MinorDigits(m.Currency)
+	if digits == 0 {
+		return fmt.Sprintf("%d", m.Amount)
+	}
+	sign, a := "", m.Amount
+	if a < 0 {
+		sign, a = "-", -a
+	}
+	scale := int64(math.Pow10(digits))
+	return fmt.Sprintf("%s%d.%0*d", sign, a/scale, digits, a%scale)
+}
+
+func (m Money) String() string { return m.Decimal() + " " + m.Currency }
+
+// Add sums two amounts of the same currency; a zero-value Money adopts the other's currency.
+func (m Money) Add(o Money) Money {
+	if m.Currency == "" {
+		m.Currency = o.Currency
+	}
+	m.Amount += o.Amount
+	return m
+}
+
+// mulDiv returns amount*num/den rounded half away from zero; the product may exceed int64.
+func mulDiv(amount, num, den int64) int64 {
+	n := new(big.Int).Mul(big.NewInt(amount), big.NewInt(num))
+	d := big.NewInt(den)
+	q, r := new(big.Int).QuoRem(n, d, new(big.Int))
+	if r.Abs(r).Lsh(r, 1).Cmp(d) >= 0 {
+		q.Add(q, big.NewInt(int64(n.Sign())))
+	}
+	return q.Int64()
+}
@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestMulDiv(t *testing.T) {
+	for _, tt := range []struct {
+		amount, num, den, want int64
+	}{
+		{10, 1, 3, 3},
+		{2, 1, 3, 1},
+		{5, 1, 2, 3}, // half rounds away from zero
+		{7, 1, 2, 4},
+		{-5, 1, 2, -3},
+		{3, -1, 2, -2},
+		{-2, 1, 3, -1},
+		{1, 1, 3, 0},
+		{0, 7, 3, 0},
+		{math.MaxInt64, 3, 3, math.MaxInt64}, // the product exceeds int64
+		{2000, 1200, 3600, 667},
+	} {
+		if got := mulDiv(tt.amount, tt.num, tt.den); got != tt.want {
+			t.Errorf("mulDiv(%d, %d, %d) = %d, want %d", tt.amount, tt.num, tt.den, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyMinorUnits(t *testing.T) {
+	for _, tt := range []struct {
+		major    float64
+		currency string
+		amount   int64
+		decimal  string
+	}{
+		{15.5, "eur", 1550, "15.50"},
+		{-0.05, "EUR", -5, "-0.05"},
+		{0.125, "EUR", 13, "0.13"},
+		{100, "JPY", 100, "100"},
+		{1.2346, "KWD", 1235, "1.235"},
+	} {
+		m := MoneyFromMajor(tt.major, tt.currency)
+		if m.Amount != tt.amount || m.Decimal() != tt.decimal {
+			t.Errorf("%g %s = %d (%s), want %d (%s)", tt.major, tt.currency, m.Amount, m.Decimal(), tt.amount, tt.decimal)
+		}
+	}
+	if got := (Money{Amount: 1550, Currency: "EUR"}).String(); got != "15.50 EUR" {
+		t.Errorf("String() = %q", got)
+	}
+	// a zero value adopts the currency of what is added to it
+	if got := (Money{}).Add(Money{Amount: 5, Currency: "EUR"}); got != (Money{Amount: 5, Currency: "EUR"}) {
+		t.Errorf("zero + 0.05 EUR = %v", got)
+	}
+}
+
+func TestFixedVoucherCurrencyMismatch(t *testing.T) {
+	q := &Quote{Total: Money{Amount: 3000, Currency: "EUR"}}
+	v := &Voucher{Code: "TENUSD", Kind: VoucherFixed, Amount: Money{Amount: 1000, Currency: "USD"}}
+	var verr *VoucherError
+	if err := v.Apply(q); !errors.As(err, &verr) {
+		t.Fatalf("USD voucher on a EUR price: %v", err)
+	}
+	if q.Total.Amount != 3000 || len(q.Lines) != 0 {
+		t.Fatalf("rejected voucher changed the quote: %+v", q)
+	}
+	v.Amount = Money{Amount: 5000, Currency: "EUR"}
+	if err := v.Apply(q); err != nil {
+		t.Fatal(err)
+	}
+	if q.Total.Amount != 0 || q.Lines[0].Amount.Amount != -3000 {
+		t.Fatalf("voucher above the price: %+v, want it capped at the total", q)
+	}
+}
@@ -0,0 +1,14 @@
+package domain
+
+import "templespace/internal/outbox"
+
+// OutboxMessage is an event stored together with the change it reports and published afterwards by
+// the relay, so a crash or broker outage after the write cannot lose it.
+type OutboxMessage = outbox.Message
+
+// OutboxStats describes the unpublished backlog; Oldest is zero when it is empty.
+type OutboxStats = outbox.Stats
+
+// Outbox stores the events of booking writes, which the repositories append in the same transaction
+// as the booking (see Booking.Outbox); Append stores messages that are not part of a booking write.
+type Outbox = outbox.Store
@@ -0,0 +1,52 @@
+package domain
+
+import "time"
+
+type PaymentStatus string
+
+const (
+	PaymentRequiresPayment PaymentStatus = "requires_payment"
+	PaymentSucceeded       PaymentStatus = "succeeded"
+	PaymentFailed          PaymentStatus = "failed"
+)
+
+// PaymentIntent is a payment of Amount for a booking opened at the payment provider. The guest pays
+// it with ClientSecret and the provider reports the outcome through a webhook.
+type PaymentIntent struct {
+	ID           string
+	BookingID    string
+	Amount       Money
+	ClientSecret string
+	Status       PaymentStatus
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// Payment event types sent by the provider.
+const (
+	PaymentEventSucceeded = "payment_intent.succeeded"
+	PaymentEventFailed    = "payment_intent.payment_failed"
+)
+
+// PaymentEvent is a verified webhook delivery. ID identifies the event, so redeliveries share it.
+type PaymentEvent struct {
+	ID            string
+	Type          string
+	IntentID      string
+	BookingID     string
+	Amount        Money
+	FailureReason string
+}
+
+type PaymentRepository interface {
+	// SaveIntent inserts or replaces pi.
+	SaveIntent(pi *PaymentIntent) error
+	GetIntent(id string) (*PaymentIntent, error)
+	// LatestIntent returns the most recent intent of bookingID or ErrNotFound.
+	LatestIntent(bookingID string) (*PaymentIntent, error)
+	// RecordEvent stores the provider event id before it is handled and reports false when it was
+	// stored already, so of concurrent deliveries of one event only one is handled.
+	RecordEvent(id string, at time.Time) (bool, error)
+	// ForgetEvent removes a recorded event whose handling failed, so a redelivery handles it again.
+	ForgetEvent(id string) error
+}
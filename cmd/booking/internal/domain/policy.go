@@ -0,0 +1,145 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrPolicyViolation is matched by every *PolicyError.
+var ErrPolicyViolation = errors.New("booking policy violation")
+
+// PolicyError explains why a requested slot breaks the space's booking policy.
+type PolicyError struct {
+	Reason string
+}
+
+func (e *PolicyError) Error() string { return e.Reason }
+
+func (e *PolicyError) Is(target error) bool { return target == ErrPolicyViolation }
+
+// BookingPolicy constrains how a space may be booked. Zero fields impose no limit.
+type BookingPolicy struct {
+	// BufferBefore and BufferAfter keep the space free for setup before and teardown after a booking.
+	BufferBefore time.Duration
+	BufferAfter  time.Duration
+	MinDuration  time.Duration
+	MaxDuration  time.Duration
+	// Alignment requires both slot ends to fall on multiples of it on the wall clock of Location
+	// (1h means on the local hour, also in zones offset by half an hour).
+	Alignment time.Duration
+	// Location is the space's time zone; nil means UTC.
+	Location *time.Location
+	// Horizon is how far ahead of now a booking may start.
+	Horizon time.Duration
+	// Capacity is how many seats a shared space offers; zero books the space exclusively.
+	Capacity int
+	// Cancellation decides the refund when a paid booking is cancelled.
+	Cancellation CancellationPolicy
+}
+
+// Check reports the first rule a booking of seats over [start, end) breaks as a *PolicyError.
+func (p BookingPolicy) Check(start, end time.Time, seats int, now time.Time) error {
+	d := end.Sub(start)
+	switch {
+	case seats < 1:
+		return &PolicyError{Reason: "seats must be at least 1"}
+	case p.Capacity == 0 && seats > 1:
+		return &PolicyError{Reason: "space is booked exclusively; seats must be 1"}
+	case p.Capacity > 0 && seats > p.Capacity:
+		return &PolicyError{Reason: fmt.Sprintf("space holds at most %d seats", p.Capacity)}
+	case p.MinDuration > 0 && d < p.MinDuration:
+		return &PolicyError{Reason: fmt.Sprintf("booking must last at least %s", p.MinDuration)}
+	case p.MaxDuration > 0 && d > p.MaxDuration:
+		return &PolicyError{Reason: fmt.Sprintf("booking must last at most %s", p.MaxDuration)}
+	case p.Alignment > 0 && (!TruncateIn(start, p.Alignment, p.Location).Equal(start) || !TruncateIn(end, p.Alignment, p.Location).Equal(end)):
+		return &PolicyError{Reason: fmt.Sprintf("booking must start and end on %s boundaries", p.Alignment)}
+	case start.Before(now):
+		return &PolicyError{Reason: "booking must not start in the past"}
+	case p.Horizon > 0 && start.After(now.Add(p.Horizon)):
+		return &PolicyError{Reason: fmt.Sprintf("booking may start at most %s ahead", p.Horizon)}
+	}
+	return nil
+}
+
+// Blocked is the interval a booking of [start, end) keeps the space busy, buffers included.
+func (p BookingPolicy) Blocked(start, end time.Time) Interval {
+	return Interval{Start: start.Add(-p.BufferBefore), End: end.Add(p.BufferAfter)}
+}
+
+// policyKeys maps the attributes.booking_policy keys of a space onto policy fields.
+var policyKeys = map[string]func(*BookingPolicy) *time.Duration{
+	"buffer_before": func(p *BookingPolicy) *time.Duration { return &p.BufferBefore },
+	"buffer_after":  func(p *BookingPolicy) *time.Duration { return &p.BufferAfter },
+	"min_duration":  func(p *BookingPolicy) *time.Duration { return &p.MinDuration },
+	"max_duration":  func(p *BookingPolicy) *time.Duration { return &p.MaxDuration },
+	"alignment":     func(p *BookingPolicy) *time.Duration { return &p.Alignment },
+	"horizon":       func(p *BookingPolicy) *time.Duration { return &p.Horizon },
+}
+
+// PolicyFromAttributes reads the booking policy from a space's attributes. "booking_policy" holds the
+// time rules, e.g. {"buffer_after": "15m", "min_duration": "1h", "alignment": "30m", "horizon": "90d"},
+// as Go durations optionally in whole days ("90d"). "booking_mode": "shared" lets bookings overlap up to
+// the space's "capacity" seats; the default mode is "exclusive". "cancellation_policy" lists refund tiers
+// (see CancellationPolicy). Missing keys yield the zero policy.
+func PolicyFromAttributes(attrs map[string]any) (BookingPolicy, error) {
+	var p BookingPolicy
+	switch mode := attrs["booking_mode"]; mode {
+	case nil, "exclusive":
+	case "shared":
+		c, ok := attrs["capacity"].(float64)
+		if !ok || c < 1 || c != float64(int(c)) {
+			return p, errors.New("shared booking_mode requires a whole capacity of at least 1")
+		}
+		p.Capacity = int(c)
+	default:
+		return p, fmt.Errorf("invalid booking_mode %v", mode)
+	}
+	if raw := attrs["cancellation_policy"]; raw != nil {
+		c, err := cancellationFromAttributes(raw)
+		if err != nil {
+			return p, err
+		}
+		p.Cancellation = c
+	}
+	raw, ok := attrs["booking_policy"]
+	if !ok || raw == nil {
+		return p, nil
+	}
+	m, ok := raw.(map[string]any)
+	if !ok {
+		return p, errors.New("booking_policy must be an object")
+	}
+	for k, v := range m {
+		field, ok := policyKeys[k]
+		if !ok {
+			return p, fmt.Errorf("booking_policy: unknown key %q", k)
+		}
+		s, ok := v.(string)
+		if !ok {
+			return p, fmt.Errorf("booking_policy: %s must be a duration string", k)
+		}
+		d, err := parsePolicyDuration(s)
+		if err != nil || d < 0 {
+			return p, fmt.Errorf("booking_policy: invalid %s %q", k, s)
+		}
+		*field(&p) = d
+	}
+	if p.MinDuration > 0 && p.MaxDuration > 0 && p.MinDuration > p.MaxDuration {
+		return p, errors.New("booking_policy: min_duration exceeds max_duration")
+	}
+	return p, nil
+}
+
+func parsePolicyDuration(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
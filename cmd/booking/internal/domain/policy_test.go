@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPolicyCheckAlignmentAndPast(t *testing.T) {
+	kolkata, err := time.LoadLocation("Asia/Kolkata") // UTC+05:30
+	if err != nil {
+		t.Skip(err)
+	}
+	now := time.Date(2030, 3, 4, 0, 0, 0, 0, time.UTC)
+	local := func(h, m int) time.Time { return time.Date(2030, 3, 5, h, m, 0, 0, kolkata) }
+	tests := []struct {
+		name       string
+		policy     BookingPolicy
+		start, end time.Time
+		ok         bool
+	}{
+		{"on the local hour", BookingPolicy{Alignment: time.Hour, Location: kolkata}, local(10, 0), local(11, 0), true},
+		{"on the UTC hour only", BookingPolicy{Alignment: time.Hour, Location: kolkata}, local(10, 30), local(11, 30), false},
+		{"UTC without a location", BookingPolicy{Alignment: time.Hour}, local(10, 30), local(11, 30), true},
+		{"local days", BookingPolicy{Alignment: 24 * time.Hour, Location: kolkata}, local(0, 0), local(24, 0), true},
+		{"starts now", BookingPolicy{}, now, now.Add(time.Hour), true},
+		{"in the past", BookingPolicy{}, now.Add(-time.Hour), now.Add(time.Hour), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Check(tt.start, tt.end, 1, now)
+			if tt.ok && err != nil {
+				t.Fatalf("got %v", err)
+			}
+			if !tt.ok && !errors.Is(err, ErrPolicyViolation) {
+				t.Fatalf("got %v, want a policy violation", err)
+			}
+		})
+	}
+}
+
+func TestFreeIntervalsSnapInLocation(t *testing.T) {
+	kolkata, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		t.Skip(err)
+	}
+	window := Interval{Start: time.Date(2030, 3, 5, 9, 10, 0, 0, kolkata), End: time.Date(2030, 3, 5, 12, 50, 0, 0, kolkata)}
+	got := FreeIntervals(window, nil, time.Hour, kolkata)
+	want := Interval{Start: time.Date(2030, 3, 5, 10, 0, 0, 0, kolkata), End: time.Date(2030, 3, 5, 12, 0, 0, 0, kolkata)}
+	if len(got) != 1 || !got[0].Start.Equal(want.Start) || !got[0].End.Equal(want.End) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
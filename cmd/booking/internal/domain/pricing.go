@@ -0,0 +1,197 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Pricing is how a space charges for bookings. HourlyRate is per seat in shared spaces; Rules raise or
+// lower it over parts of a booking and the largest applicable Discount comes off the sum.
+type Pricing struct {
+	HourlyRate Money
+	// Location is where the local days and clock times of Rules are read; nil means UTC.
+	Location  *time.Location
+	Rules     []RateRule
+	Discounts []DurationDiscount
+}
+
+// RateRule multiplies the hourly rate while it applies. A dated rule applies throughout Period and
+// overrides weekly rules; any other rule applies on Weekdays (every day when empty) within Hours (the
+// whole day when zero). The first matching rule of each kind wins.
+type RateRule struct {
+	Name       string
+	Multiplier float64
+	Weekdays   []time.Weekday
+	Hours      ClockRange
+	Period     Interval
+}
+
+// DurationDiscount takes Percent off bookings lasting at least MinDuration.
+type DurationDiscount struct {
+	Name        string
+	MinDuration time.Duration
+	Percent     float64
+}
+
+// QuoteLine is one priced component of a quote.
+type QuoteLine struct {
+	Description string
+	Amount      Money
+}
+
+// Quote is the price of booking seats over [SlotStart, SlotEnd); Total is the sum of Lines.
+type Quote struct {
+	SpaceID   string
+	SlotStart time.Time
+	SlotEnd   time.Time
+	Seats     int
+	Lines     []QuoteLine
+	Total     Money
+}
+
+// basisPoints scales multipliers and percentages so they apply in integer arithmetic.
+const basisPoints = 10000
+
+// Quote prices seats over [start, end) with one line for the time at the base rate, one per rule that
+// applied and one for the discount. Time is priced pro rata to the second and each line is rounded
+// half up to the minor unit.
+func (p Pricing) Quote(spaceID string, start, end time.Time, seats int) Quote {
+	q := Quote{SpaceID: spaceID, SlotStart: start, SlotEnd: end, Seats: seats}
+	rate := p.HourlyRate
+	seatNote := ""
+	if seats > 1 {
+		seatNote = fmt.Sprintf(" × %d seats", seats)
+	}
+
+	secs := p.secondsByRule(start, end)
+	if secs[0] > 0 || len(p.Rules) == 0 {
+		q.Lines = append(q.Lines, QuoteLine{
+			Description: fmt.Sprintf("%s at %s/h%s", seconds(secs[0]), rate, seatNote),
+			Amount:      Money{Amount: mulDiv(rate.Amount, secs[0]*int64(seats), 3600), Currency: rate.Currency},
+		})
+	}
+	for i, r := range p.Rules {
+		if secs[i+1] == 0 {
+			continue
+		}
+		bp := int64(math.Round(r.Multiplier * basisPoints))
+		adjusted := Money{Amount: mulDiv(rate.Amount, bp, basisPoints), Currency: rate.Currency}
+		q.Lines = append(q.Lines, QuoteLine{
+			Description: fmt.Sprintf("%s: %s at %s/h (×%g)%s", r.Name, seconds(secs[i+1]), adjusted, r.Multiplier, seatNote),
+			Amount:      Money{Amount: mulDiv(rate.Amount, secs[i+1]*int64(seats)*bp, 3600*basisPoints), Currency: rate.Currency},
+		})
+	}
+	var subtotal Money
+	for _, l := range q.Lines {
+		subtotal = subtotal.Add(l.Amount)
+	}
+	if d, ok := p.discountFor(end.Sub(start)); ok {
+		bp := int64(math.Round(d.Percent * basisPoints / 100))
+		q.Lines = append(q.Lines, QuoteLine{
+			Description: fmt.Sprintf("%s: %g%% off bookings of %s or more", d.Name, d.Percent, d.MinDuration),
+			Amount:      Money{Amount: -mulDiv(subtotal.Amount, bp, basisPoints), Currency: subtotal.Currency},
+		})
+	}
+	for _, l := range q.Lines {
+		q.Total = q.Total.Add(l.Amount)
+	}
+	return q
+}
+
+// secondsByRule splits [start, end) at every rule boundary and counts the seconds priced at the base
+// rate (index 0) and under each rule (index i+1 for Rules[i]).
+func (p Pricing) secondsByRule(start, end time.Time) []int64 {
+	loc := p.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	cuts := []time.Time{start, end}
+	for _, r := range p.Rules {
+		for _, t := range r.boundaries(start, end, loc) {
+			if t.After(start) && t.Before(end) {
+				cuts = append(cuts, t)
+			}
+		}
+	}
+	sort.Slice(cuts, func(i, j int) bool { return cuts[i].Before(cuts[j]) })
+
+	secs := make([]int64, len(p.Rules)+1)
+	for i := 0; i+1 < len(cuts); i++ {
+		if d := cuts[i+1].Sub(cuts[i]); d > 0 {
+			secs[p.ruleAt(cuts[i], loc)+1] += int64(d / time.Second)
+		}
+	}
+	return secs
+}
+
+// ruleAt returns the index of the rule in force at t, or -1 for the base rate.
+func (p Pricing) ruleAt(t time.Time, loc *time.Location) int {
+	for i, r := range p.Rules {
+		if r.dated() && !t.Before(r.Period.Start) && t.Before(r.Period.End) {
+			return i
+		}
+	}
+	for i, r := range p.Rules {
+		if !r.dated() && r.weeklyAt(t.In(loc)) {
+			return i
+		}
+	}
+	return -1
+}
+
+// discountFor returns the discount with the longest threshold that d reaches.
+func (p Pricing) discountFor(d time.Duration) (DurationDiscount, bool) {
+	var best DurationDiscount
+	found := false
+	for _, disc := range p.Discounts {
+		if d >= disc.MinDuration && (!found || disc.MinDuration > best.MinDuration) {
+			best, found = disc, true
+		}
+	}
+	return best, found
+}
+
+func (r RateRule) dated() bool { return !r.Period.Start.IsZero() }
+
+func (r RateRule) hours() ClockRange {
+	if r.Hours == (ClockRange{}) {
+		return ClockRange{Open: 0, Close: 24 * time.Hour}
+	}
+	return r.Hours
+}
+
+// weeklyAt reports whether a weekly rule applies at the local time t.
+func (r RateRule) weeklyAt(t time.Time) bool {
+	if len(r.Weekdays) > 0 {
+		match := false
+		for _, wd := range r.Weekdays {
+			match = match || wd == t.Weekday()
+		}
+		if !match {
+			return false
+		}
+	}
+	h := r.hours()
+	offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	return offset >= h.Open && offset < h.Close
+}
+
+// boundaries returns the instants around [start, end) at which the rule starts or stops applying.
+func (r RateRule) boundaries(start, end time.Time, loc *time.Location) []time.Time {
+	if r.dated() {
+		return []time.Time{r.Period.Start, r.Period.End}
+	}
+	h := r.hours()
+	var out []time.Time
+	first := start.In(loc)
+	day := time.Date(first.Year(), first.Month(), first.Day()-1, 0, 0, 0, 0, loc)
+	for ; day.Before(end); day = day.AddDate(0, 0, 1) {
+		out = append(out, atClock(day, h.Open), atClock(day, h.Close))
+	}
+	return out
+}
+
+// seconds renders a whole number of seconds as a duration such as "1h30m0s".
+func seconds(n int64) time.Duration { return time.Duration(n) * time.Second }
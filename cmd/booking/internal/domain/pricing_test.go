@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestQuote(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip(err)
+	}
+	eur := func(a int64) Money { return Money{Amount: a, Currency: "EUR"} }
+	monday := func(h, m int) time.Time { return time.Date(2030, 3, 4, h, m, 0, 0, time.UTC) }
+	evening := RateRule{Name: "Evening", Multiplier: 1.5, Hours: ClockRange{Open: 18 * time.Hour, Close: 24 * time.Hour}}
+	weekend := RateRule{Name: "Weekend", Multiplier: 2, Weekdays: []time.Weekday{time.Saturday, time.Sunday}}
+	launch := RateRule{Name: "Launch", Multiplier: 0.5, Period: Interval{Start: monday(18, 0), End: monday(19, 0)}}
+	tests := []struct {
+		name       string
+		pricing    Pricing
+		start, end time.Time
+		seats      int
+		lines      []int64
+		total      int64
+	}{
+		{"base rate", Pricing{}, monday(9, 0), monday(10, 30), 1, []int64{3000}, 3000},
+		{"per seat", Pricing{}, monday(9, 0), monday(10, 0), 3, []int64{6000}, 6000},
+		{"pro rata, rounded", Pricing{}, monday(9, 0), monday(9, 20), 1, []int64{667}, 667},
+		{"rule over part of the slot", Pricing{Rules: []RateRule{evening}}, monday(17, 0), monday(19, 0), 1, []int64{2000, 3000}, 5000},
+		{"rule that does not apply", Pricing{Rules: []RateRule{weekend}}, monday(9, 0), monday(10, 0), 1, []int64{2000}, 2000},
+		{"first matching weekly rule wins", Pricing{Rules: []RateRule{evening, {Name: "Late", Multiplier: 3, Hours: evening.Hours}}},
+			monday(18, 0), monday(19, 0), 1, []int64{3000}, 3000},
+		{"dated rule overrides weekly", Pricing{Rules: []RateRule{evening, launch}}, monday(17, 0), monday(20, 0), 1,
+			[]int64{2000, 3000, 1000}, 6000},
+		{"rule hours in the space's zone", Pricing{Location: berlin, Rules: []RateRule{evening}}, monday(17, 0), monday(18, 0), 1,
+			[]int64{3000}, 3000},
+		{"rule across midnight into the weekend", Pricing{Rules: []RateRule{weekend}},
+			time.Date(2030, 3, 8, 23, 0, 0, 0, time.UTC), time.Date(2030, 3, 9, 1, 0, 0, 0, time.UTC), 2, []int64{4000, 8000}, 12000},
+		{"longest reached discount", Pricing{Discounts: []DurationDiscount{{Name: "Half day", MinDuration: 4 * time.Hour, Percent: 20},
+			{Name: "Long", MinDuration: 3 * time.Hour, Percent: 10}, {Name: "Short", MinDuration: 2 * time.Hour, Percent: 5}}},
+			monday(9, 0), monday(12, 0), 1, []int64{6000, -600}, 5400},
+		{"discount off rules too", Pricing{Rules: []RateRule{evening}, Discounts: []DurationDiscount{{Name: "Long", MinDuration: 2 * time.Hour, Percent: 10}}},
+			monday(17, 0), monday(19, 0), 1, []int64{2000, 3000, -500}, 4500},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.pricing.HourlyRate = eur(2000)
+			q := tt.pricing.Quote("s1", tt.start, tt.end, tt.seats)
+			got := make([]int64, len(q.Lines))
+			var sum Money
+			for i, l := range q.Lines {
+				got[i] = l.Amount.Amount
+				sum = sum.Add(l.Amount)
+				if l.Amount.Currency != "EUR" {
+					t.Errorf("line %q in %q", l.Description, l.Amount.Currency)
+				}
+			}
+			if len(got) != len(tt.lines) {
+				t.Fatalf("lines %v, want %v", got, tt.lines)
+			}
+			for i := range got {
+				if got[i] != tt.lines[i] {
+					t.Fatalf("lines %v, want %v", got, tt.lines)
+				}
+			}
+			if q.Total != eur(tt.total) || sum != q.Total {
+				t.Fatalf("total %s, lines sum to %s, want %s", q.Total, sum, eur(tt.total))
+			}
+			if tt.seats > 1 && !strings.Contains(q.Lines[0].Description, "seats") {
+				t.Errorf("line %q does not mention the seats", q.Lines[0].Description)
+			}
+		})
+	}
+}
@@ -0,0 +1,307 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency is the FREQ part of an RRULE.
+type Frequency string
+
+const (
+	FreqDaily   Frequency = "DAILY"
+	FreqWeekly  Frequency = "WEEKLY"
+	FreqMonthly Frequency = "MONTHLY"
+)
+
+// ByDay is one BYDAY entry; Ordinal is only meaningful for MONTHLY rules ("2TU", "-1FR"), 0 means every.
+type ByDay struct {
+	Ordinal int
+	Weekday time.Weekday
+}
+
+// RRule is the subset of RFC 5545 recurrence rules supported for recurring bookings:
+// FREQ (DAILY, WEEKLY, MONTHLY), INTERVAL, COUNT, UNTIL, BYDAY and BYMONTHDAY.
+// Every rule must be bounded by COUNT or UNTIL.
+type RRule struct {
+	Freq       Frequency
+	Interval   int
+	Count      int
+	Until      time.Time
+	ByDay      []ByDay
+	ByMonthDay []int
+	// untilFloating marks an UNTIL given without zone; it is read in the series' own location.
+	untilFloating bool
+	untilDate     bool
+}
+
+var weekdayCodes = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+// ParseRRule parses an RRULE value such as "FREQ=WEEKLY;BYDAY=TU;UNTIL=20260630T000000Z".
+// A leading "RRULE:" is accepted.
+func ParseRRule(s string) (*RRule, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	if s == "" {
+		return nil, errors.New("rrule: empty rule")
+	}
+	r := &RRule{Interval: 1}
+	for _, part := range strings.Split(s, ";") {
+		key, val, ok := strings.Cut(part, "=")
+		if !ok || val == "" {
+			return nil, fmt.Errorf("rrule: malformed part %q", part)
+		}
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			switch f := Frequency(strings.ToUpper(val)); f {
+			case FreqDaily, FreqWeekly, FreqMonthly:
+				r.Freq = f
+			default:
+				return nil, fmt.Errorf("rrule: unsupported FREQ %q", val)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("rrule: invalid INTERVAL %q", val)
+			}
+			r.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("rrule: invalid COUNT %q", val)
+			}
+			r.Count = n
+		case "UNTIL":
+			if err := r.parseUntil(val); err != nil {
+				return nil, err
+			}
+		case "BYDAY":
+			for _, d := range strings.Split(val, ",") {
+				bd, err := parseByDay(d)
+				if err != nil {
+					return nil, err
+				}
+				r.ByDay = append(r.ByDay, bd)
+			}
+		case "BYMONTHDAY":
+			for _, d := range strings.Split(val, ",") {
+				n, err := strconv.Atoi(d)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return nil, fmt.Errorf("rrule: invalid BYMONTHDAY %q", d)
+				}
+				r.ByMonthDay = append(r.ByMonthDay, n)
+			}
+		case "WKST":
+			if strings.ToUpper(val) != "MO" {
+				return nil, errors.New("rrule: only WKST=MO is supported")
+			}
+		default:
+			return nil, fmt.Errorf("rrule: unsupported part %q", key)
+		}
+	}
+	if r.Freq == "" {
+		return nil, errors.New("rrule: FREQ is required")
+	}
+	if r.Count == 0 && r.Until.IsZero() {
+		return nil, errors.New("rrule: COUNT or UNTIL is required")
+	}
+	if r.Count > 0 && !r.Until.IsZero() {
+		return nil, errors.New("rrule: COUNT and UNTIL are mutually exclusive")
+	}
+	if len(r.ByMonthDay) > 0 && r.Freq != FreqMonthly {
+		return nil, errors.New("rrule: BYMONTHDAY requires FREQ=MONTHLY")
+	}
+	for _, bd := range r.ByDay {
+		if bd.Ordinal != 0 && r.Freq != FreqMonthly {
+			return nil, errors.New("rrule: ordinal BYDAY requires FREQ=MONTHLY")
+		}
+	}
+	return r, nil
+}
+
+func (r *RRule) parseUntil(val string) error {
+	for _, f := range []struct {
+		layout   string
+		floating bool
+		date     bool
+	}{
+		{"20060102T150405Z", false, false},
+		{"20060102T150405", true, false},
+		{"20060102", true, true},
+	} {
+		if t, err := time.Parse(f.layout, val); err == nil {
+			r.Until, r.untilFloating, r.untilDate = t, f.floating, f.date
+			return nil
+		}
+	}
+	return fmt.Errorf("rrule: invalid UNTIL %q", val)
+}
+
+func parseByDay(s string) (ByDay, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if len(s) < 2 {
+		return ByDay{}, fmt.Errorf("rrule: invalid BYDAY %q", s)
+	}
+	wd, ok := weekdayCodes[s[len(s)-2:]]
+	if !ok {
+		return ByDay{}, fmt.Errorf("rrule: invalid BYDAY %q", s)
+	}
+	bd := ByDay{Weekday: wd}
+	if prefix := s[:len(s)-2]; prefix != "" {
+		n, err := strconv.Atoi(prefix)
+		if err != nil || n == 0 || n < -5 || n > 5 {
+			return ByDay{}, fmt.Errorf("rrule: invalid BYDAY %q", s)
+		}
+		bd.Ordinal = n
+	}
+	return bd, nil
+}
+
+// Expand returns the occurrence start times of the rule anchored at dtstart, in dtstart's location
+// so wall-clock times survive DST changes. EXDATEs remove occurrences after COUNT is applied, as in
+// RFC 5545. More than limit occurrences is an error.
+func (r *RRule) Expand(dtstart time.Time, exdates []time.Time, limit int) ([]time.Time, error) {
+	loc := dtstart.Location()
+	until := r.Until
+	if r.untilFloating {
+		until = time.Date(until.Year(), until.Month(), until.Day(), until.Hour(), until.Minute(), until.Second(), 0, loc)
+		if r.untilDate {
+			// a DATE value includes the whole day
+			until = until.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+	}
+	excluded := make(map[int64]bool, len(exdates))
+	for _, x := range exdates {
+		excluded[x.UnixNano()] = true
+	}
+
+	var out []time.Time
+	seen := 0
+	// maxPeriods guards against rules whose filters never match (e.g. BYMONTHDAY=31 with INTERVAL=2 from February)
+	const maxPeriods = 10000
+	for period := 0; period < maxPeriods; period++ {
+		for _, t := range r.periodCandidates(dtstart, period) {
+			if t.Before(dtstart) {
+				continue
+			}
+			if !until.IsZero() && t.After(until) {
+				return out, nil
+			}
+			seen++
+			if !excluded[t.UnixNano()] {
+				if len(out) >= limit {
+					return nil, fmt.Errorf("rrule: more than %d occurrences", limit)
+				}
+				out = append(out, t)
+			}
+			if r.Count > 0 && seen >= r.Count {
+				return out, nil
+			}
+		}
+	}
+	return out, nil
+}
+
+// periodCandidates lists the sorted candidate starts of the n-th FREQ*INTERVAL period after dtstart.
+func (r *RRule) periodCandidates(dtstart time.Time, n int) []time.Time {
+	loc := dtstart.Location()
+	h, m, s := dtstart.Clock()
+	at := func(y int, mo time.Month, d int) time.Time {
+		return time.Date(y, mo, d, h, m, s, dtstart.Nanosecond(), loc)
+	}
+	var out []time.Time
+	switch r.Freq {
+	case FreqDaily:
+		t := dtstart.AddDate(0, 0, n*r.Interval)
+		t = at(t.Year(), t.Month(), t.Day())
+		if len(r.ByDay) == 0 || r.matchesWeekday(t.Weekday()) {
+			out = append(out, t)
+		}
+	case FreqWeekly:
+		// weeks start on Monday
+		offset := (int(dtstart.Weekday()) + 6) % 7
+		monday := dtstart.AddDate(0, 0, -offset+7*n*r.Interval)
+		days := r.ByDay
+		if len(days) == 0 {
+			days = []ByDay{{Weekday: dtstart.Weekday()}}
+		}
+		for _, bd := range days {
+			d := monday.AddDate(0, 0, (int(bd.Weekday)+6)%7)
+			out = append(out, at(d.Year(), d.Month(), d.Day()))
+		}
+	case FreqMonthly:
+		first := time.Date(dtstart.Year(), dtstart.Month()+time.Month(n*r.Interval), 1, 0, 0, 0, 0, loc)
+		y, mo := first.Year(), first.Month()
+		daysIn := time.Date(y, mo+1, 0, 0, 0, 0, 0, loc).Day()
+		var byDay []int
+		for _, bd := range r.ByDay {
+			byDay = append(byDay, monthDaysFor(bd, y, mo, daysIn, loc)...)
+		}
+		var days []int
+		for _, md := range r.ByMonthDay {
+			if md < 0 {
+				md = daysIn + md + 1
+			}
+			// BYDAY restricts BYMONTHDAY when both are given (RFC 5545), e.g. Friday the 13th
+			if md >= 1 && md <= daysIn && (len(r.ByDay) == 0 || slices.Contains(byDay, md)) {
+				days = append(days, md)
+			}
+		}
+		if len(r.ByMonthDay) == 0 {
+			days = byDay
+		}
+		if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 && dtstart.Day() <= daysIn {
+			days = append(days, dtstart.Day())
+		}
+		for _, d := range days {
+			out = append(out, at(y, mo, d))
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Before(out[j]) })
+	return dedupeTimes(out)
+}
+
+func (r *RRule) matchesWeekday(wd time.Weekday) bool {
+	for _, bd := range r.ByDay {
+		if bd.Weekday == wd {
+			return true
+		}
+	}
+	return false
+}
+
+// monthDaysFor resolves a BYDAY entry within one month: every matching weekday, or the n-th (from the end when negative).
+func monthDaysFor(bd ByDay, y int, mo time.Month, daysIn int, loc *time.Location) []int {
+	var matches []int
+	for d := 1; d <= daysIn; d++ {
+		if time.Date(y, mo, d, 0, 0, 0, 0, loc).Weekday() == bd.Weekday {
+			matches = append(matches, d)
+		}
+	}
+	switch {
+	case bd.Ordinal == 0:
+		return matches
+	case bd.Ordinal > 0 && bd.Ordinal <= len(matches):
+		return []int{matches[bd.Ordinal-1]}
+	case bd.Ordinal < 0 && -bd.Ordinal <= len(matches):
+		return []int{matches[len(matches)+bd.Ordinal]}
+	}
+	return nil
+}
+
+func dedupeTimes(ts []time.Time) []time.Time {
+	out := ts[:0]
+	for i, t := range ts {
+		if i == 0 || !t.Equal(ts[i-1]) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
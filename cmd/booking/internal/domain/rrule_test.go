@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRRuleExpand(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip(err)
+	}
+	monday := time.Date(2030, 3, 4, 9, 0, 0, 0, time.UTC)
+	newYear := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC) // a Tuesday
+	tests := []struct {
+		name    string
+		rule    string
+		dtstart time.Time
+		exdates []time.Time
+		want    []string // RFC 3339 in dtstart's location
+	}{
+		{"daily", "FREQ=DAILY;COUNT=3", monday, nil,
+			[]string{"2030-03-04T09:00:00Z", "2030-03-05T09:00:00Z", "2030-03-06T09:00:00Z"}},
+		{"daily on weekdays", "FREQ=DAILY;BYDAY=MO,FR;COUNT=3", monday, nil,
+			[]string{"2030-03-04T09:00:00Z", "2030-03-08T09:00:00Z", "2030-03-11T09:00:00Z"}},
+		{"weekly by day", "FREQ=WEEKLY;BYDAY=TU,TH;COUNT=4", monday, nil,
+			[]string{"2030-03-05T09:00:00Z", "2030-03-07T09:00:00Z", "2030-03-12T09:00:00Z", "2030-03-14T09:00:00Z"}},
+		{"fortnightly", "FREQ=WEEKLY;INTERVAL=2;COUNT=3", monday, nil,
+			[]string{"2030-03-04T09:00:00Z", "2030-03-18T09:00:00Z", "2030-04-01T09:00:00Z"}},
+		{"monthly skips short months", "FREQ=MONTHLY;COUNT=3", time.Date(2030, 1, 31, 9, 0, 0, 0, time.UTC), nil,
+			[]string{"2030-01-31T09:00:00Z", "2030-03-31T09:00:00Z", "2030-05-31T09:00:00Z"}},
+		{"last day of month", "FREQ=MONTHLY;BYMONTHDAY=-1;COUNT=3", newYear, nil,
+			[]string{"2030-01-31T09:00:00Z", "2030-02-28T09:00:00Z", "2030-03-31T09:00:00Z"}},
+		{"second tuesday", "FREQ=MONTHLY;BYDAY=2TU;COUNT=3", newYear, nil,
+			[]string{"2030-01-08T09:00:00Z", "2030-02-12T09:00:00Z", "2030-03-12T09:00:00Z"}},
+		{"last friday", "FREQ=MONTHLY;BYDAY=-1FR;COUNT=2", newYear, nil,
+			[]string{"2030-01-25T09:00:00Z", "2030-02-22T09:00:00Z"}},
+		{"friday the 13th", "FREQ=MONTHLY;BYDAY=FR;BYMONTHDAY=13;COUNT=3", newYear, nil,
+			[]string{"2030-09-13T09:00:00Z", "2030-12-13T09:00:00Z", "2031-06-13T09:00:00Z"}},
+		{"until is inclusive", "FREQ=WEEKLY;UNTIL=20300318T090000Z", monday, nil,
+			[]string{"2030-03-04T09:00:00Z", "2030-03-11T09:00:00Z", "2030-03-18T09:00:00Z"}},
+		{"until date covers the day", "FREQ=DAILY;UNTIL=20300306", monday, nil,
+			[]string{"2030-03-04T09:00:00Z", "2030-03-05T09:00:00Z", "2030-03-06T09:00:00Z"}},
+		{"exdate counts towards count", "FREQ=DAILY;COUNT=3", monday, []time.Time{monday.AddDate(0, 0, 1)},
+			[]string{"2030-03-04T09:00:00Z", "2030-03-06T09:00:00Z"}},
+		{"exdate with until", "FREQ=DAILY;UNTIL=20300306T090000Z", monday, []time.Time{monday.AddDate(0, 0, 2)},
+			[]string{"2030-03-04T09:00:00Z", "2030-03-05T09:00:00Z"}},
+		// summer time starts on 31 March 2030: the wall clock stays at 10:00, UTC moves an hour
+		{"across dst", "FREQ=WEEKLY;COUNT=3", time.Date(2030, 3, 24, 10, 0, 0, 0, berlin), nil,
+			[]string{"2030-03-24T10:00:00+01:00", "2030-03-31T10:00:00+02:00", "2030-04-07T10:00:00+02:00"}},
+		{"floating until in dtstart's zone", "FREQ=DAILY;UNTIL=20300325T100000", time.Date(2030, 3, 24, 10, 0, 0, 0, berlin), nil,
+			[]string{"2030-03-24T10:00:00+01:00", "2030-03-25T10:00:00+01:00"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := ParseRRule(tt.rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			starts, err := r.Expand(tt.dtstart, tt.exdates, 100)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make([]string, len(starts))
+			for i, s := range starts {
+				got[i] = s.Format(time.RFC3339)
+			}
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRRuleExpandLimit(t *testing.T) {
+	r, err := ParseRRule("FREQ=DAILY;COUNT=10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Expand(time.Date(2030, 3, 4, 9, 0, 0, 0, time.UTC), nil, 9); err == nil {
+		t.Fatal("expanded more occurrences than the limit")
+	}
+}
+
+func TestParseRRuleRejects(t *testing.T) {
+	for _, rule := range []string{
+		"",
+		"COUNT=3",
+		"FREQ=YEARLY;COUNT=3",
+		"FREQ=DAILY",
+		"FREQ=DAILY;COUNT=3;UNTIL=20300306",
+		"FREQ=DAILY;COUNT=0",
+		"FREQ=DAILY;INTERVAL=0;COUNT=3",
+		"FREQ=DAILY;UNTIL=2030-03-06",
+		"FREQ=WEEKLY;BYMONTHDAY=1;COUNT=3",
+		"FREQ=WEEKLY;BYDAY=2TU;COUNT=3",
+		"FREQ=MONTHLY;BYDAY=XX;COUNT=3",
+		"FREQ=MONTHLY;BYDAY=6MO;COUNT=3",
+		"FREQ=MONTHLY;BYMONTHDAY=32;COUNT=3",
+		"FREQ=MONTHLY;BYMONTHDAY=0;COUNT=3",
+		"FREQ=DAILY;WKST=SU;COUNT=3",
+		"FREQ=DAILY;BYHOUR=9;COUNT=3",
+		"FREQ=DAILY;COUNT",
+	} {
+		if _, err := ParseRRule(rule); err == nil {
+			t.Errorf("%q parsed", rule)
+		}
+	}
+	if r, err := ParseRRule("RRULE:freq=weekly;byday=tu;until=20300630T000000Z"); err != nil || r.Freq != FreqWeekly || r.ByDay[0].Weekday != time.Tuesday {
+		t.Fatalf("prefixed lower-case rule: %+v, %v", r, err)
+	}
+}
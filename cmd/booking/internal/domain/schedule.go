@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// ClockRange is an opening range within one local day, as offsets from midnight; Close may be 24h.
+type ClockRange struct {
+	Open  time.Duration
+	Close time.Duration
+}
+
+// Blackout is a one-off period in which a space is closed.
+type Blackout struct {
+	Interval
+	Reason string
+}
+
+// Schedule is when a space can be booked: its weekly opening hours in its own location minus blackouts.
+type Schedule struct {
+	Location *time.Location
+	// Weekly holds the opening ranges per weekday; nil means open around the clock.
+	Weekly    map[time.Weekday][]ClockRange
+	Blackouts []Blackout
+}
+
+// Check reports a *PolicyError when [start, end) leaves the opening hours or touches a blackout.
+func (s Schedule) Check(start, end time.Time) error {
+	for _, b := range s.Blackouts {
+		if start.Before(b.End) && b.Start.Before(end) {
+			reason := "closed"
+			if b.Reason != "" {
+				reason = "closed: " + b.Reason
+			}
+			return &PolicyError{Reason: fmt.Sprintf("space is %s from %s to %s", reason, b.Start.Format(time.RFC3339), b.End.Format(time.RFC3339))}
+		}
+	}
+	if len(s.closedHours(Interval{Start: start, End: end})) > 0 {
+		return &PolicyError{Reason: "slot is outside the opening hours of the space"}
+	}
+	return nil
+}
+
+// Closed returns the parts of window in which the space is closed, blackouts included, in no particular order.
+func (s Schedule) Closed(window Interval) []Interval {
+	closed := s.closedHours(window)
+	for _, b := range s.Blackouts {
+		if window.Start.Before(b.End) && b.Start.Before(window.End) {
+			closed = append(closed, b.Interval)
+		}
+	}
+	return closed
+}
+
+// closedHours is window minus the opening hours.
+func (s Schedule) closedHours(window Interval) []Interval {
+	if s.Weekly == nil || !window.Start.Before(window.End) {
+		return nil
+	}
+	loc := s.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	var open []Interval
+	first := window.Start.In(loc)
+	// the day before may still be open past window.Start
+	day := time.Date(first.Year(), first.Month(), first.Day()-1, 0, 0, 0, 0, loc)
+	for !day.After(window.End) {
+		for _, r := range s.Weekly[day.Weekday()] {
+			open = append(open, Interval{Start: atClock(day, r.Open), End: atClock(day, r.Close)})
+		}
+		day = time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, loc)
+	}
+	return FreeIntervals(window, open, 0, nil)
+}
+
+// atClock places a clock offset on the wall-clock of day, so 09:00 stays 09:00 across DST changes.
+func atClock(day time.Time, offset time.Duration) time.Time {
+	h := int(offset / time.Hour)
+	m := int(offset % time.Hour / time.Minute)
+	return time.Date(day.Year(), day.Month(), day.Day(), h, m, 0, 0, day.Location())
+}
@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestScheduleCheck(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip(err)
+	}
+	hours := func(open, close int) ClockRange {
+		return ClockRange{Open: time.Duration(open) * time.Hour, Close: time.Duration(close) * time.Hour}
+	}
+	// day is a Monday; the bar is open into Tuesday night and closed on Sundays
+	bar := Schedule{Weekly: map[time.Weekday][]ClockRange{
+		time.Monday:  {hours(9, 12), hours(18, 24)},
+		time.Tuesday: {hours(0, 2), hours(9, 17)},
+	}}
+	closedDay := bar
+	closedDay.Blackouts = []Blackout{{Interval: at(9, 10), Reason: "inventory"}}
+	tests := []struct {
+		name       string
+		schedule   Schedule
+		start, end time.Time
+		ok         bool
+	}{
+		{"open around the clock", Schedule{}, day, day.Add(72 * time.Hour), true},
+		{"within opening hours", bar, at(9, 12).Start, at(9, 12).End, true},
+		{"before opening", bar, at(8, 10).Start, at(8, 10).End, false},
+		{"across the lunch break", bar, at(11, 19).Start, at(11, 19).End, false},
+		{"past closing", bar, at(16, 20).Start.AddDate(0, 0, 1), at(16, 20).End.AddDate(0, 0, 1), false},
+		{"spanning midnight while open", bar, at(23, 25).Start, at(23, 25).End, true},
+		{"past the night's closing", bar, at(23, 27).Start, at(23, 27).End, false},
+		{"on a closed weekday", bar, at(9, 10).Start.AddDate(0, 0, 6), at(9, 10).End.AddDate(0, 0, 6), false},
+		{"on a blackout", closedDay, at(9, 11).Start, at(9, 11).End, false},
+		{"after a blackout", closedDay, at(10, 11).Start, at(10, 11).End, true},
+		{"blackout on an open-all-day schedule", Schedule{Blackouts: closedDay.Blackouts}, at(8, 10).Start, at(8, 10).End, false},
+		// summer time starts on 31 March 2030: 09:00-17:00 local is 07:00-15:00 UTC from then on
+		{"local hours after the dst change", Schedule{Location: berlin, Weekly: map[time.Weekday][]ClockRange{time.Monday: {hours(9, 17)}}},
+			time.Date(2030, 4, 1, 7, 0, 0, 0, time.UTC), time.Date(2030, 4, 1, 15, 0, 0, 0, time.UTC), true},
+		{"utc hours after the dst change", Schedule{Location: berlin, Weekly: map[time.Weekday][]ClockRange{time.Monday: {hours(9, 17)}}},
+			time.Date(2030, 4, 1, 8, 0, 0, 0, time.UTC), time.Date(2030, 4, 1, 16, 0, 0, 0, time.UTC), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.schedule.Check(tt.start, tt.end)
+			var perr *PolicyError
+			if (err == nil) != tt.ok || err != nil && !errors.As(err, &perr) {
+				t.Fatalf("Check() = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestScheduleClosed(t *testing.T) {
+	s := Schedule{
+		Weekly:    map[time.Weekday][]ClockRange{time.Monday: {{Open: 9 * time.Hour, Close: 17 * time.Hour}}},
+		Blackouts: []Blackout{{Interval: at(12, 13)}, {Interval: at(30, 31)}},
+	}
+	closed := s.Closed(at(8, 18))
+	var total time.Duration
+	for _, iv := range closed {
+		total += iv.End.Sub(iv.Start)
+	}
+	// 08-09 and 17-18 outside the hours plus the blackout at noon
+	if len(closed) != 3 || total != 3*time.Hour {
+		t.Fatalf("Closed() = %v", closed)
+	}
+}
@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// BookingEvent is something that happens to a booking and may move it to another status.
+type BookingEvent string
+
+const (
+	EventConfirm    BookingEvent = "confirm"
+	EventPay        BookingEvent = "pay"
+	EventCancel     BookingEvent = "cancel"
+	EventExpire     BookingEvent = "expire"
+	EventComplete   BookingEvent = "complete"
+	EventNoShow     BookingEvent = "no_show"
+	EventReschedule BookingEvent = "reschedule"
+)
+
+// transitions is the booking lifecycle. Statuses without outgoing edges are terminal.
+//
+//	pending   --confirm--> confirmed
+//	pending   --pay------> paid
+//	confirmed --pay------> paid
+//	pending, confirmed, paid --cancel--> cancelled
+//	pending, confirmed --expire--> expired
+//	paid      --complete-> completed
+//	paid      --no_show--> no_show
+//	pending, confirmed, paid --reschedule--> (unchanged)
+var transitions = map[BookingStatus]map[BookingEvent]BookingStatus{
+	StatusPending: {
+		EventConfirm:    StatusConfirmed,
+		EventPay:        StatusPaid,
+		EventCancel:     StatusCancelled,
+		EventExpire:     StatusExpired,
+		EventReschedule: StatusPending,
+	},
+	StatusConfirmed: {
+		EventPay:        StatusPaid,
+		EventCancel:     StatusCancelled,
+		EventExpire:     StatusExpired,
+		EventReschedule: StatusConfirmed,
+	},
+	StatusPaid: {
+		EventCancel:     StatusCancelled,
+		EventComplete:   StatusCompleted,
+		EventNoShow:     StatusNoShow,
+		EventReschedule: StatusPaid,
+	},
+}
+
+var ErrInvalidTransition = errors.New("invalid transition")
+
+// TransitionError reports an event that is not allowed from the booking's current status.
+// It matches ErrInvalidTransition under errors.Is.
+type TransitionError struct {
+	From  BookingStatus
+	Event BookingEvent
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("cannot %s a %s booking", e.Event, e.From)
+}
+
+func (e *TransitionError) Is(target error) bool { return target == ErrInvalidTransition }
+
+// Transition moves the booking to the status ev leads to, or returns a *TransitionError.
+func (b *Booking) Transition(ev BookingEvent) error {
+	next, ok := transitions[b.Status][ev]
+	if !ok {
+		return &TransitionError{From: b.Status, Event: ev}
+	}
+	b.Status = next
+	return nil
+}
+
+// CanTransition reports whether ev is allowed from the booking's current status.
+func (b *Booking) CanTransition(ev BookingEvent) bool {
+	_, ok := transitions[b.Status][ev]
+	return ok
+}
+
+// HoldsSlot reports whether a booking in this status keeps its slot unavailable to others.
+func (s BookingStatus) HoldsSlot() bool {
+	return s != StatusCancelled && s != StatusExpired
+}
+
+// Topic is the event topic published when a booking enters this status, e.g. "booking_paid".
+func (s BookingStatus) Topic() string {
+	return "booking_" + string(s)
+}
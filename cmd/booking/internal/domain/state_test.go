@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTransition(t *testing.T) {
+	const illegal BookingStatus = ""
+	statuses := []BookingStatus{StatusPending, StatusConfirmed, StatusPaid, StatusCancelled, StatusExpired, StatusCompleted, StatusNoShow}
+	events := []BookingEvent{EventConfirm, EventPay, EventCancel, EventExpire, EventComplete, EventNoShow, EventReschedule}
+	// want[from] lists the status each event leads to, in the order of events
+	want := map[BookingStatus][]BookingStatus{
+		StatusPending:   {StatusConfirmed, StatusPaid, StatusCancelled, StatusExpired, illegal, illegal, StatusPending},
+		StatusConfirmed: {illegal, StatusPaid, StatusCancelled, StatusExpired, illegal, illegal, StatusConfirmed},
+		StatusPaid:      {illegal, illegal, StatusCancelled, illegal, StatusCompleted, StatusNoShow, StatusPaid},
+		StatusCancelled: {illegal, illegal, illegal, illegal, illegal, illegal, illegal},
+		StatusExpired:   {illegal, illegal, illegal, illegal, illegal, illegal, illegal},
+		StatusCompleted: {illegal, illegal, illegal, illegal, illegal, illegal, illegal},
+		StatusNoShow:    {illegal, illegal, illegal, illegal, illegal, illegal, illegal},
+	}
+	for _, from := range statuses {
+		for i, ev := range events {
+			next := want[from][i]
+			t.Run(string(from)+" "+string(ev), func(t *testing.T) {
+				b := &Booking{Status: from}
+				if got := b.CanTransition(ev); got != (next != illegal) {
+					t.Errorf("CanTransition = %v", got)
+				}
+				err := b.Transition(ev)
+				if next == illegal {
+					var te *TransitionError
+					if !errors.Is(err, ErrInvalidTransition) || !errors.As(err, &te) || te.From != from || te.Event != ev {
+						t.Fatalf("got %v, want a TransitionError from %s on %s", err, from, ev)
+					}
+					if b.Status != from {
+						t.Fatalf("status changed to %s on an illegal event", b.Status)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				if b.Status != next {
+					t.Fatalf("status %s, want %s", b.Status, next)
+				}
+			})
+		}
+	}
+}
+
+func TestHoldsSlot(t *testing.T) {
+	for status, holds := range map[BookingStatus]bool{
+		StatusPending: true, StatusConfirmed: true, StatusPaid: true, StatusCompleted: true, StatusNoShow: true,
+		StatusCancelled: false, StatusExpired: false,
+	} {
+		if status.HoldsSlot() != holds {
+			t.Errorf("%s.HoldsSlot() = %v, want %v", status, !holds, holds)
+		}
+	}
+}
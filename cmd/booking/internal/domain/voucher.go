@@ -0,0 +1,167 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrVoucherRejected is matched by every *VoucherError.
+	ErrVoucherRejected = errors.New("voucher rejected")
+	ErrVoucherExists   = errors.New("voucher code already exists")
+)
+
+// VoucherError explains why a code cannot be used for a booking.
+type VoucherError struct {
+	Code   string
+	Reason string
+}
+
+func (e *VoucherError) Error() string { return fmt.Sprintf("voucher %s: %s", e.Code, e.Reason) }
+
+func (e *VoucherError) Is(target error) bool { return target == ErrVoucherRejected }
+
+type VoucherKind string
+
+const (
+	VoucherPercent VoucherKind = "percent"
+	VoucherFixed   VoucherKind = "fixed"
+)
+
+// Voucher is a discount code. A percent voucher takes Percent off the quoted price, a fixed one takes
+// Amount off it, never below zero. Zero limits and empty restrictions impose none.
+type Voucher struct {
+	Code    string
+	Kind    VoucherKind
+	Percent float64
+	Amount  Money
+	// ValidFrom and ValidUntil bound when the code can be redeemed.
+	ValidFrom  time.Time
+	ValidUntil time.Time
+	// MaxRedemptions caps active redemptions overall, MaxPerUser those of a single user.
+	MaxRedemptions int
+	MaxPerUser     int
+	// SpaceIDs and Tags restrict the code to the listed spaces or to spaces carrying any of the tags.
+	SpaceIDs  []string
+	Tags      []string
+	CreatedAt time.Time
+}
+
+// NormalizeVoucherCode makes codes case-insensitive.
+func NormalizeVoucherCode(code string) string { return strings.ToUpper(strings.TrimSpace(code)) }
+
+// Validate checks the definition of a new voucher.
+func (v *Voucher) Validate() error {
+	switch {
+	case v.Code == "":
+		return errors.New("code required")
+	case v.Kind == VoucherPercent && (v.Percent <= 0 || v.Percent > 100):
+		return errors.New("percent must be above 0 and at most 100")
+	case v.Kind == VoucherFixed && (v.Amount.Amount <= 0 || v.Amount.Currency == ""):
+		return errors.New("fixed voucher needs a positive amount and a currency")
+	case v.Kind != VoucherPercent && v.Kind != VoucherFixed:
+		return fmt.Errorf("invalid kind %q", v.Kind)
+	case !v.ValidFrom.IsZero() && !v.ValidUntil.IsZero() && !v.ValidFrom.Before(v.ValidUntil):
+		return errors.New("valid_from must be before valid_until")
+	case v.MaxRedemptions < 0 || v.MaxPerUser < 0:
+		return errors.New("limits must not be negative")
+	}
+	return nil
+}
+
+// Check reports a *VoucherError when the code cannot be used at now for a space with the given tags.
+// Usage limits are checked by the repository when the code is redeemed.
+func (v *Voucher) Check(spaceID string, tags []string, now time.Time) error {
+	switch {
+	case !v.ValidFrom.IsZero() && now.Before(v.ValidFrom):
+		return &VoucherError{Code: v.Code, Reason: "not valid yet"}
+	case !v.ValidUntil.IsZero() && !now.Before(v.ValidUntil):
+		return &VoucherError{Code: v.Code, Reason: "expired"}
+	case len(v.SpaceIDs) > 0 && !contains(v.SpaceIDs, spaceID):
+		return &VoucherError{Code: v.Code, Reason: "not valid for this space"}
+	}
+	if len(v.Tags) > 0 {
+		for _, t := range tags {
+			if contains(v.Tags, t) {
+				return nil
+			}
+		}
+		return &VoucherError{Code: v.Code, Reason: "not valid for spaces without tags " + strings.Join(v.Tags, ", ")}
+	}
+	return nil
+}
+
+// CheckLimits reports a *VoucherError when another redemption would exceed the voucher's limits.
+func (v *Voucher) CheckLimits(total, byUser int) error {
+	if v.MaxRedemptions > 0 && total >= v.MaxRedemptions {
+		return &VoucherError{Code: v.Code, Reason: "fully redeemed"}
+	}
+	if v.MaxPerUser > 0 && byUser >= v.MaxPerUser {
+		return &VoucherError{Code: v.Code, Reason: "already used the maximum number of times"}
+	}
+	return nil
+}
+
+// Apply adds the voucher's discount to q as a negative line and lowers the total accordingly.
+func (v *Voucher) Apply(q *Quote) error {
+	var off Money
+	var desc string
+	switch v.Kind {
+	case VoucherPercent:
+		bp := int64(math.Round(v.Percent * basisPoints / 100))
+		off = Money{Amount: mulDiv(q.Total.Amount, bp, basisPoints), Currency: q.Total.Currency}
+		desc = fmt.Sprintf("Voucher %s: %g%% off", v.Code, v.Percent)
+	case VoucherFixed:
+		if q.Total.Currency != "" && v.Amount.Currency != q.Total.Currency {
+			return &VoucherError{Code: v.Code, Reason: "issued in " + v.Amount.Currency + ", price is in " + q.Total.Currency}
+		}
+		off = Money{Amount: min(v.Amount.Amount, q.Total.Amount), Currency: v.Amount.Currency}
+		desc = fmt.Sprintf("Voucher %s: %s off", v.Code, v.Amount)
+	}
+	off.Amount = -off.Amount
+	q.Lines = append(q.Lines, QuoteLine{Description: desc, Amount: off})
+	q.Total = q.Total.Add(off)
+	return nil
+}
+
+// Redemption records a voucher used for a booking. A released redemption no longer counts towards the
+// voucher's limits.
+type Redemption struct {
+	Code      string
+	BookingID string
+	UserID    string
+	// Discount is the amount the voucher took off the booking.
+	Discount   Money
+	RedeemedAt time.Time
+	ReleasedAt time.Time
+}
+
+// Active reports whether the redemption still counts towards the limits.
+func (r *Redemption) Active() bool { return r.ReleasedAt.IsZero() }
+
+// VoucherRepository stores vouchers and their redemptions. Codes are redeemed by the BookingRepository
+// the store belongs to, with the booking they are redeemed for (see Booking.Redemption).
+type VoucherRepository interface {
+	// CreateVoucher stores a new code, failing with ErrVoucherExists when it is taken.
+	CreateVoucher(v *Voucher) error
+	GetVoucher(code string) (*Voucher, error)
+	ListVouchers() ([]*Voucher, error)
+	// Release frees the active redemption of bookingID, if any.
+	Release(bookingID string, at time.Time) error
+	// CountRedemptions returns the active redemptions of code, overall and by userID.
+	CountRedemptions(code, userID string) (total, byUser int, err error)
+	// ListRedemptions returns every redemption of code, released ones included, oldest first.
+	ListRedemptions(code string) ([]*Redemption, error)
+}
+
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
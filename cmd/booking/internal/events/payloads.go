@@ -0,0 +1,89 @@
+package events
+
+import (
+	"time"
+
+	"templespace/cmd/booking/internal/domain"
+)
+
+// The payloads below are the published contract. They are copied from domain types, never shared
+// with them, so a domain change cannot alter an event by accident; changing a payload means a new
+// version with its own schema.
+
+// MoneyV1 is an amount in minor units, e.g. cents.
+type MoneyV1 struct {
+	AmountMinor int64  `json:"amount_minor"`
+	Currency    string `json:"currency"`
+}
+
+// BookingV1 is the payload of booking_created and of the booking_<status> events.
+type BookingV1 struct {
+	ID            string     `json:"id"`
+	SpaceID       string     `json:"space_id"`
+	UserID        string     `json:"user_id"`
+	SeriesID      string     `json:"series_id,omitempty"`
+	SlotStart     time.Time  `json:"slot_start"`
+	SlotEnd       time.Time  `json:"slot_end"`
+	Seats         int        `json:"seats"`
+	Status        string     `json:"status"`
+	Version       int        `json:"version"`
+	Total         MoneyV1    `json:"total"`
+	VoucherCode   string     `json:"voucher_code,omitempty"`
+	Refunded      *MoneyV1   `json:"refunded,omitempty"`
+	HoldExpiresAt *time.Time `json:"hold_expires_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// BookingRescheduledV1 is the booking after a move plus the slot it left.
+type BookingRescheduledV1 struct {
+	Booking      BookingV1 `json:"booking"`
+	OldSlotStart time.Time `json:"old_slot_start"`
+	OldSlotEnd   time.Time `json:"old_slot_end"`
+}
+
+type PaymentFailedV1 struct {
+	BookingID string `json:"booking_id"`
+	IntentID  string `json:"intent_id"`
+	Reason    string `json:"reason"`
+}
+
+// BookingRefundedV1 reports what a cancelled paid booking got back; Percent is of Total.
+type BookingRefundedV1 struct {
+	BookingID string  `json:"booking_id"`
+	RefundID  string  `json:"refund_id"`
+	Percent   float64 `json:"percent"`
+	Total     MoneyV1 `json:"total"`
+	Refund    MoneyV1 `json:"refund"`
+}
+
+func Money(m domain.Money) MoneyV1 {
+	return MoneyV1{AmountMinor: m.Amount, Currency: m.Currency}
+}
+
+func Booking(b *domain.Booking) BookingV1 {
+	out := BookingV1{
+		ID:          b.ID,
+		SpaceID:     b.SpaceID,
+		UserID:      b.UserID,
+		SeriesID:    b.SeriesID,
+		SlotStart:   b.SlotStart.UTC(),
+		SlotEnd:     b.SlotEnd.UTC(),
+		Seats:       b.Seats,
+		Status:      string(b.Status),
+		Version:     b.Version,
+		Total:       Money(b.Total),
+		VoucherCode: b.VoucherCode,
+		CreatedAt:   b.CreatedAt.UTC(),
+		UpdatedAt:   b.UpdatedAt.UTC(),
+	}
+	if b.Refunded.Amount > 0 {
+		r := Money(b.Refunded)
+		out.Refunded = &r
+	}
+	if !b.HoldExpiresAt.IsZero() {
+		t := b.HoldExpiresAt.UTC()
+		out.HoldExpiresAt = &t
+	}
+	return out
+}
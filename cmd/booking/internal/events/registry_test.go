@@ -0,0 +1,80 @@
+package events
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"templespace/cmd/booking/internal/domain"
+	"templespace/internal/cloudevents"
+)
+
+// samples holds a payload for every registered event type, built through the same functions the
+// service uses, so a payload drifting from its schema fails here instead of in production.
+func samples() map[string]any {
+	at := time.Date(2030, 3, 4, 9, 0, 0, 0, time.UTC)
+	b := &domain.Booking{
+		ID:            "b-1",
+		SpaceID:       "space-1",
+		UserID:        "alice",
+		SeriesID:      "series-1",
+		SlotStart:     at.Add(48 * time.Hour),
+		SlotEnd:       at.Add(49 * time.Hour),
+		Seats:         2,
+		Version:       3,
+		Total:         domain.Money{Amount: 4000, Currency: "EUR"},
+		VoucherCode:   "SPRING",
+		Refunded:      domain.Money{Amount: 2000, Currency: "EUR"},
+		HoldExpiresAt: at.Add(15 * time.Minute),
+		CreatedAt:     at,
+		UpdatedAt:     at,
+	}
+	withStatus := func(s domain.BookingStatus) BookingV1 {
+		cp := *b
+		cp.Status = s
+		return Booking(&cp)
+	}
+	return map[string]any{
+		"booking_created":   withStatus(domain.StatusPending),
+		"booking_confirmed": withStatus(domain.StatusConfirmed),
+		"booking_paid":      withStatus(domain.StatusPaid),
+		"booking_cancelled": withStatus(domain.StatusCancelled),
+		"booking_expired":   withStatus(domain.StatusExpired),
+		"booking_completed": withStatus(domain.StatusCompleted),
+		"booking_no_show":   withStatus(domain.StatusNoShow),
+		"booking_rescheduled": BookingRescheduledV1{
+			Booking:      withStatus(domain.StatusPaid),
+			OldSlotStart: at.Add(24 * time.Hour),
+			OldSlotEnd:   at.Add(25 * time.Hour),
+		},
+		"booking_payment_failed": PaymentFailedV1{BookingID: b.ID, IntentID: "pi-1", Reason: "card declined"},
+		"booking_refunded": BookingRefundedV1{
+			BookingID: b.ID, RefundID: "re-1", Percent: 50, Total: Money(b.Total), Refund: Money(b.Refunded),
+		},
+	}
+}
+
+func TestPayloadsMatchSchemas(t *testing.T) {
+	payloads := samples()
+	for _, typ := range Types() {
+		data, ok := payloads[typ]
+		if !ok {
+			t.Errorf("%s: no sample payload", typ)
+			continue
+		}
+		raw, err := Encode(typ, "b-1", time.Now(), data)
+		if err != nil {
+			t.Fatalf("%s: %v", typ, err)
+		}
+		env, err := cloudevents.Decode(raw)
+		if err != nil {
+			t.Fatalf("%s: %v", typ, err)
+		}
+		if env.Type != typ || env.Source != Source || !strings.HasPrefix(env.DataSchema, SchemaPath) {
+			t.Errorf("%s: envelope %+v", typ, env)
+		}
+		if err := Validate(typ, env.Data); err != nil {
+			t.Error(err)
+		}
+	}
+}
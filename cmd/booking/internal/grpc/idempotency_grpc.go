@@ -0,0 +1,49 @@
+//go:build grpc
+
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"templespace/cmd/booking/internal/domain"
+)
+
+// idempotent runs call once per idempotency_key of in, replaying the stored response to retries with
+// the same key and fields (see service.Idempotent). access_token is left out of the comparison so a
+// retry may carry a refreshed token. Failed calls, including errors answered in-band, are not stored.
+func (s *Server) idempotent(ctx context.Context, method string, in *structpb.Struct,
+	call func(context.Context, *structpb.Struct) (*structpb.Struct, error)) (*structpb.Struct, error) {
+	key := in.GetFields()["idempotency_key"].GetStringValue()
+	fields := in.AsMap()
+	delete(fields, "access_token")
+	delete(fields, "idempotency_key")
+	request, _ := json.Marshal(fields) // map keys are sorted, so equal requests encode equally
+
+	var callErr error
+	resp, _, err := s.svc.Idempotent(ctx, in.GetFields()["access_token"].GetStringValue(), key, "grpc "+method, request,
+		func() *domain.IdempotentResponse {
+			out, err := call(ctx, in)
+			if err != nil {
+				callErr = err
+				return &domain.IdempotentResponse{Retryable: true}
+			}
+			body, _ := protojson.Marshal(out)
+			_, failed := out.GetFields()["error"]
+			return &domain.IdempotentResponse{Body: body, Retryable: failed}
+		})
+	if callErr != nil {
+		return nil, callErr
+	}
+	if err != nil {
+		return structpb.NewStruct(map[string]interface{}{"error": err.Error()})
+	}
+	out := &structpb.Struct{}
+	if err := protojson.Unmarshal(resp.Body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -33,6 +33,7 @@ func (s *Server) register() {
 			{MethodName: "CreateBooking", Handler: s.handleCreateBooking},
 			{MethodName: "ConfirmPayment", Handler: s.handleConfirmPayment},
 			{MethodName: "CancelBooking", Handler: s.handleCancelBooking},
+			{MethodName: "GetAvailability", Handler: s.handleGetAvailability},
 		},
 		Streams:  []gogrpc.StreamDesc{},
 		Metadata: "booking.proto",
@@ -46,11 +47,11 @@ func (s *Server) handleCreateBooking(_ interface{}, ctx context.Context, dec fun
 		return nil, err
 	}
 	if interceptor == nil {
-		return s.createBooking(ctx, in)
+		return s.idempotent(ctx, "CreateBooking", in, s.createBooking)
 	}
 	info := &gogrpc.UnaryServerInfo{Server: s, FullMethod: "/booking.BookingService/CreateBooking"}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return s.createBooking(ctx, req.(*structpb.Struct))
+		return s.idempotent(ctx, "CreateBooking", req.(*structpb.Struct), s.createBooking)
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -61,11 +62,11 @@ func (s *Server) handleConfirmPayment(_ interface{}, ctx context.Context, dec fu
 		return nil, err
 	}
 	if interceptor == nil {
-		return s.confirmPayment(ctx, in)
+		return s.idempotent(ctx, "ConfirmPayment", in, s.confirmPayment)
 	}
 	info := &gogrpc.UnaryServerInfo{Server: s, FullMethod: "/booking.BookingService/ConfirmPayment"}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return s.confirmPayment(ctx, req.(*structpb.Struct))
+		return s.idempotent(ctx, "ConfirmPayment", req.(*structpb.Struct), s.confirmPayment)
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -76,11 +77,26 @@ func (s *Server) handleCancelBooking(_ interface{}, ctx context.Context, dec fun
 		return nil, err
 	}
 	if interceptor == nil {
-		return s.cancelBooking(ctx, in)
+		return s.idempotent(ctx, "CancelBooking", in, s.cancelBooking)
 	}
 	info := &gogrpc.UnaryServerInfo{Server: s, FullMethod: "/booking.BookingService/CancelBooking"}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return s.cancelBooking(ctx, req.(*structpb.Struct))
+		return s.idempotent(ctx, "CancelBooking", req.(*structpb.Struct), s.cancelBooking)
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func (s *Server) handleGetAvailability(_ interface{}, ctx context.Context, dec func(interface{}) error, interceptor gogrpc.UnaryServerInterceptor) (interface{}, error) {
+	in := &structpb.Struct{}
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return s.getAvailability(ctx, in)
+	}
+	info := &gogrpc.UnaryServerInfo{Server: s, FullMethod: "/booking.BookingService/GetAvailability"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return s.getAvailability(ctx, req.(*structpb.Struct))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -93,31 +109,68 @@ func (s *Server) createBooking(ctx context.Context, in *structpb.Struct) (*struc
 	endStr := in.GetFields()["slot_end"].GetStringValue()
 	start, _ := time.Parse(time.RFC3339, startStr)
 	end, _ := time.Parse(time.RFC3339, endStr)
-	b, err := s.svc.CreateBooking(ctx, tok, spaceID, userID, start, end)
+	seats := int(in.GetFields()["seats"].GetNumberValue())
+	voucher := in.GetFields()["voucher_code"].GetStringValue()
+	b, err := s.svc.CreateBooking(ctx, tok, spaceID, userID, start, end, seats, voucher)
 	if err != nil {
 		return structpb.NewStruct(map[string]interface{}{"error": err.Error()})
 	}
-	return structpb.NewStruct(map[string]interface{}{"id": b.ID, "status": string(b.Status)})
+	out := map[string]interface{}{"id": b.ID, "status": string(b.Status), "version": b.Version, "seats": b.Seats,
+		"total": b.Total.Decimal(), "currency": b.Total.Currency}
+	if pi, err := s.svc.PaymentIntentOf(ctx, tok, b.ID); err == nil && pi != nil {
+		out["payment_intent_id"] = pi.ID
+		out["client_secret"] = pi.ClientSecret
+	}
+	return structpb.NewStruct(out)
 }
 
 func (s *Server) confirmPayment(ctx context.Context, in *structpb.Struct) (*structpb.Struct, error) {
 	tok := in.GetFields()["access_token"].GetStringValue()
 	id := in.GetFields()["booking_id"].GetStringValue()
-	b, err := s.svc.ConfirmPayment(ctx, tok, id)
+	version := int(in.GetFields()["version"].GetNumberValue())
+	b, err := s.svc.ConfirmPayment(ctx, tok, id, version)
 	if err != nil {
 		return structpb.NewStruct(map[string]interface{}{"error": err.Error()})
 	}
-	return structpb.NewStruct(map[string]interface{}{"id": b.ID, "status": string(b.Status)})
+	return structpb.NewStruct(map[string]interface{}{"id": b.ID, "status": string(b.Status), "version": b.Version})
 }
 
 func (s *Server) cancelBooking(ctx context.Context, in *structpb.Struct) (*structpb.Struct, error) {
 	tok := in.GetFields()["access_token"].GetStringValue()
 	id := in.GetFields()["booking_id"].GetStringValue()
-	b, err := s.svc.CancelBooking(ctx, tok, id)
+	version := int(in.GetFields()["version"].GetNumberValue())
+	b, err := s.svc.CancelBooking(ctx, tok, id, version)
+	if err != nil {
+		return structpb.NewStruct(map[string]interface{}{"error": err.Error()})
+	}
+	return structpb.NewStruct(map[string]interface{}{"id": b.ID, "status": string(b.Status), "version": b.Version,
+		"refunded": b.Refunded.Decimal()})
+}
+
+func (s *Server) getAvailability(ctx context.Context, in *structpb.Struct) (*structpb.Struct, error) {
+	spaceID := in.GetFields()["space_id"].GetStringValue()
+	from, _ := time.Parse(time.RFC3339, in.GetFields()["from"].GetStringValue())
+	to, _ := time.Parse(time.RFC3339, in.GetFields()["to"].GetStringValue())
+	var granularity time.Duration
+	if g := in.GetFields()["granularity"].GetStringValue(); g != "" {
+		var err error
+		if granularity, err = time.ParseDuration(g); err != nil {
+			return structpb.NewStruct(map[string]interface{}{"error": "invalid granularity"})
+		}
+	}
+	free, err := s.svc.FreeSlots(ctx, spaceID, from, to, granularity)
 	if err != nil {
 		return structpb.NewStruct(map[string]interface{}{"error": err.Error()})
 	}
-	return structpb.NewStruct(map[string]interface{}{"id": b.ID, "status": string(b.Status)})
+	list := make([]interface{}, 0, len(free))
+	for _, fs := range free {
+		list = append(list, map[string]interface{}{
+			"start":      fs.Start.Format(time.RFC3339),
+			"end":        fs.End.Format(time.RFC3339),
+			"seats_left": fs.Seats,
+		})
+	}
+	return structpb.NewStruct(map[string]interface{}{"space_id": spaceID, "free": list})
 }
 
 func (s *Server) Listen(addr string) error {
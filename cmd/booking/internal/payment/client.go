@@ -0,0 +1,106 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"templespace/cmd/booking/internal/domain"
+)
+
+// intentJSON is a payment intent as the provider API sends it.
+type intentJSON struct {
+	ID           string `json:"id"`
+	BookingID    string `json:"booking_id"`
+	Amount       int64  `json:"amount"`
+	Currency     string `json:"currency"`
+	ClientSecret string `json:"client_secret"`
+	Status       string `json:"status"`
+	Created      int64  `json:"created"`
+}
+
+// ProviderClient opens intents through the provider's HTTP API; the fake provider speaks the same API.
+type ProviderClient struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+func NewProviderClient(baseURL, apiKey string, timeout time.Duration) *ProviderClient {
+	return &ProviderClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+func (c *ProviderClient) CreateIntent(ctx context.Context, bookingID string, amount domain.Money) (*domain.PaymentIntent, error) {
+	body, _ := json.Marshal(map[string]any{"booking_id": bookingID, "amount": amount.Amount, "currency": amount.Currency})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/v1/payment_intents", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	var in intentJSON
+	if err := c.do(req, &in); err != nil {
+		return nil, err
+	}
+	created := time.Unix(in.Created, 0).UTC()
+	return &domain.PaymentIntent{
+		ID:           in.ID,
+		BookingID:    bookingID,
+		Amount:       domain.Money{Amount: in.Amount, Currency: in.Currency},
+		ClientSecret: in.ClientSecret,
+		Status:       domain.PaymentStatus(in.Status),
+		CreatedAt:    created,
+		UpdatedAt:    created,
+	}, nil
+}
+
+// refundJSON is a refund as the provider API sends it.
+type refundJSON struct {
+	ID       string `json:"id"`
+	IntentID string `json:"intent_id"`
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+func (c *ProviderClient) Refund(ctx context.Context, intent *domain.PaymentIntent, amount domain.Money) (string, error) {
+	body, _ := json.Marshal(map[string]any{"intent_id": intent.ID, "amount": amount.Amount, "currency": amount.Currency})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/v1/refunds", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	// the provider answers a retried refund with the original one
+	req.Header.Set("Idempotency-Key", "refund_"+intent.ID)
+	var out refundJSON
+	if err := c.do(req, &out); err != nil {
+		return "", err
+	}
+	return out.ID, nil
+}
+
+// do sends req and decodes a 2xx JSON answer into out.
+func (c *ProviderClient) do(req *http.Request, out any) error {
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("payment provider: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("payment provider: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("payment provider: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,269 @@
+package payment
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FakeProvider is a local stand-in for the payment provider's API. It opens intents, lets them be
+// paid through a confirm call and reports the outcome with signed webhooks, redelivering until the
+// receiver acknowledges. The rates inject the failures a real provider produces.
+type FakeProvider struct {
+	// WebhookURL receives the events, signed with WebhookSecret.
+	WebhookURL    string
+	WebhookSecret string
+	// APIKey, when set, is required as a bearer token to open and read intents. Confirming is a
+	// client-side call authorized by the intent's client secret.
+	APIKey string
+	// FailureRate is the share of confirmations without an explicit outcome that are declined.
+	FailureRate float64
+	// DuplicateRate is the share of events delivered twice.
+	DuplicateRate float64
+	// ErrorRate is the share of API calls answered with 503.
+	ErrorRate float64
+	// WebhookDelay is how long after a confirmation its event is sent.
+	WebhookDelay time.Duration
+	// AutoConfirm confirms every intent right after it is opened, so bookings get paid without a client.
+	AutoConfirm bool
+
+	client *http.Client
+	mu     sync.Mutex
+	byID   map[string]*intentJSON
+	// refunds holds the refund of each intent; an intent is refunded at most once
+	refunds map[string]*refundJSON
+}
+
+func NewFakeProvider(webhookURL, webhookSecret string) *FakeProvider {
+	return &FakeProvider{
+		WebhookURL:    webhookURL,
+		WebhookSecret: webhookSecret,
+		client:        &http.Client{Timeout: 5 * time.Second},
+		byID:          map[string]*intentJSON{},
+		refunds:       map[string]*refundJSON{},
+	}
+}
+
+// ServeHTTP serves:
+//
+//	POST /v1/payment_intents               {booking_id, amount, currency}
+//	GET  /v1/payment_intents/{id}
+//	POST /v1/payment_intents/{id}/confirm  {client_secret, outcome: "succeeded"|"failed"}
+//	POST /v1/refunds                       {intent_id, amount, currency}
+func (p *FakeProvider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if chance(p.ErrorRate) {
+		http.Error(w, "provider unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if r.URL.Path == "/v1/refunds" && r.Method == http.MethodPost {
+		if p.APIKey != "" && r.Header.Get("Authorization") != "Bearer "+p.APIKey {
+			http.Error(w, "invalid api key", http.StatusUnauthorized)
+			return
+		}
+		p.refund(w, r)
+		return
+	}
+	rest, ok := strings.CutPrefix(r.URL.Path, "/v1/payment_intents")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	rest = strings.Trim(rest, "/")
+	id, action, _ := strings.Cut(rest, "/")
+	if action != "confirm" && p.APIKey != "" && r.Header.Get("Authorization") != "Bearer "+p.APIKey {
+		http.Error(w, "invalid api key", http.StatusUnauthorized)
+		return
+	}
+	switch {
+	case rest == "" && r.Method == http.MethodPost:
+		p.create(w, r)
+	case id != "" && action == "" && r.Method == http.MethodGet:
+		p.get(w, id)
+	case id != "" && action == "confirm" && r.Method == http.MethodPost:
+		p.confirm(w, r, id)
+	default:
+		http.Error(w, "not found", http.StatusNotFound)
+	}
+}
+
+func (p *FakeProvider) create(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		BookingID string `json:"booking_id"`
+		Amount    int64  `json:"amount"`
+		Currency  string `json:"currency"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Amount <= 0 || req.Currency == "" {
+		http.Error(w, "booking_id, a positive amount and a currency are required", http.StatusBadRequest)
+		return
+	}
+	id := "pi_" + randomHex(12)
+	in := &intentJSON{
+		ID:           id,
+		BookingID:    req.BookingID,
+		Amount:       req.Amount,
+		Currency:     req.Currency,
+		ClientSecret: id + "_secret_" + randomHex(12),
+		Status:       "requires_payment",
+		Created:      time.Now().Unix(),
+	}
+	p.mu.Lock()
+	p.byID[id] = in
+	cp := *in
+	p.mu.Unlock()
+	log.Printf("fakepay: intent %s booking=%s amount=%d %s", id, req.BookingID, req.Amount, req.Currency)
+	if p.AutoConfirm {
+		p.settle(id, "")
+	}
+	writeJSON(w, http.StatusCreated, cp)
+}
+
+func (p *FakeProvider) get(w http.ResponseWriter, id string) {
+	p.mu.Lock()
+	in, ok := p.byID[id]
+	var cp intentJSON
+	if ok {
+		cp = *in
+	}
+	p.mu.Unlock()
+	if !ok {
+		http.Error(w, "no such intent", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, cp)
+}
+
+func (p *FakeProvider) confirm(w http.ResponseWriter, r *http.Request, id string) {
+	var req struct {
+		ClientSecret string `json:"client_secret"`
+		Outcome      string `json:"outcome"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&req)
+	p.mu.Lock()
+	in, ok := p.byID[id]
+	p.mu.Unlock()
+	switch {
+	case !ok:
+		http.Error(w, "no such intent", http.StatusNotFound)
+		return
+	case req.ClientSecret != in.ClientSecret:
+		http.Error(w, "client_secret does not match", http.StatusForbidden)
+		return
+	case req.Outcome != "" && req.Outcome != "succeeded" && req.Outcome != "failed":
+		http.Error(w, `outcome must be "succeeded" or "failed"`, http.StatusBadRequest)
+		return
+	}
+	cp, err := p.settle(id, req.Outcome)
+	if err != "" {
+		http.Error(w, err, http.StatusConflict)
+		return
+	}
+	writeJSON(w, http.StatusOK, cp)
+}
+
+// refund pays back part or all of a succeeded intent. A repeated refund of an intent returns the first.
+func (p *FakeProvider) refund(w http.ResponseWriter, r *http.Request) {
+	var req refundJSON
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Amount <= 0 {
+		http.Error(w, "intent_id and a positive amount are required", http.StatusBadRequest)
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	in, ok := p.byID[req.IntentID]
+	switch {
+	case !ok:
+		http.Error(w, "no such intent", http.StatusNotFound)
+		return
+	case p.refunds[in.ID] != nil:
+		writeJSON(w, http.StatusOK, p.refunds[in.ID])
+		return
+	case in.Status != "succeeded":
+		http.Error(w, "intent is "+in.Status+", only succeeded intents can be refunded", http.StatusConflict)
+		return
+	case req.Amount > in.Amount || req.Currency != in.Currency:
+		http.Error(w, "refund exceeds the payment", http.StatusBadRequest)
+		return
+	}
+	ref := &refundJSON{ID: "re_" + randomHex(12), IntentID: in.ID, Amount: req.Amount, Currency: req.Currency}
+	p.refunds[in.ID] = ref
+	log.Printf("fakepay: refund %s intent=%s amount=%d %s", ref.ID, in.ID, ref.Amount, ref.Currency)
+	writeJSON(w, http.StatusCreated, ref)
+}
+
+// settle decides the outcome of an unpaid intent, at random per FailureRate when outcome is empty,
+// and schedules its webhook.
+func (p *FakeProvider) settle(id, outcome string) (intentJSON, string) {
+	p.mu.Lock()
+	in := p.byID[id]
+	if in.Status != "requires_payment" {
+		p.mu.Unlock()
+		return intentJSON{}, "intent is already " + in.Status
+	}
+	if outcome == "" {
+		outcome = "succeeded"
+		if chance(p.FailureRate) {
+			outcome = "failed"
+		}
+	}
+	in.Status = outcome
+	cp := *in
+	p.mu.Unlock()
+
+	ev := webhookEvent{ID: "evt_" + randomHex(12), Type: "payment_intent.succeeded", Created: time.Now().Unix()}
+	if outcome == "failed" {
+		ev.Type = "payment_intent.payment_failed"
+		ev.Data.FailureReason = "card_declined"
+	}
+	ev.Data.IntentID, ev.Data.BookingID = cp.ID, cp.BookingID
+	ev.Data.Amount, ev.Data.Currency = cp.Amount, cp.Currency
+	go func() {
+		time.Sleep(p.WebhookDelay)
+		p.deliver(ev)
+		if chance(p.DuplicateRate) {
+			p.deliver(ev)
+		}
+	}()
+	return cp, ""
+}
+
+// deliver posts ev until the receiver answers 2xx, backing off between attempts. Each attempt is
+// signed afresh so redeliveries stay within the receiver's tolerance.
+func (p *FakeProvider) deliver(ev webhookEvent) {
+	if p.WebhookURL == "" {
+		return
+	}
+	body, _ := json.Marshal(ev)
+	backoff := time.Second
+	for attempt := 1; attempt <= 6; attempt++ {
+		req, _ := http.NewRequest(http.MethodPost, p.WebhookURL, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(SignatureHeader, Sign(p.WebhookSecret, body, time.Now()))
+		resp, err := p.client.Do(req)
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode/100 == 2 {
+				log.Printf("fakepay: delivered %s %s for %s", ev.ID, ev.Type, ev.Data.IntentID)
+				return
+			}
+			log.Printf("fakepay: webhook %s attempt %d: %s", ev.ID, attempt, resp.Status)
+		} else {
+			log.Printf("fakepay: webhook %s attempt %d: %v", ev.ID, attempt, err)
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+	log.Printf("fakepay: gave up delivering %s", ev.ID)
+}
+
+func chance(rate float64) bool { return rate > 0 && rand.Float64() < rate }
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,44 @@
+package payment
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"time"
+
+	"templespace/cmd/booking/internal/domain"
+)
+
+// StubGateway opens intents without a provider; they are never paid, so bookings are confirmed
+// through the pay action during development.
+type StubGateway struct{}
+
+func NewStubGateway() *StubGateway { return &StubGateway{} }
+
+func (g *StubGateway) CreateIntent(ctx context.Context, bookingID string, amount domain.Money) (*domain.PaymentIntent, error) {
+	id := "pi_" + randomHex(12)
+	now := time.Now().UTC()
+	log.Printf("payment intent %s booking=%s amount=%s (stub)", id, bookingID, amount)
+	return &domain.PaymentIntent{
+		ID:           id,
+		BookingID:    bookingID,
+		Amount:       amount,
+		ClientSecret: id + "_secret_" + randomHex(12),
+		Status:       domain.PaymentRequiresPayment,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}, nil
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func (g *StubGateway) Refund(ctx context.Context, intent *domain.PaymentIntent, amount domain.Money) (string, error) {
+	id := "re_" + randomHex(12)
+	log.Printf("payment refund %s intent=%s amount=%s (stub)", id, intent.ID, amount)
+	return id, nil
+}
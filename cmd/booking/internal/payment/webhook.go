@@ -0,0 +1,108 @@
+package payment
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"templespace/cmd/booking/internal/domain"
+)
+
+// SignatureHeader carries "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>">" on every webhook.
+const SignatureHeader = "Payment-Signature"
+
+// SignatureTolerance bounds how old a signed timestamp may be, limiting replays of captured deliveries.
+const SignatureTolerance = 5 * time.Minute
+
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// webhookEvent is the JSON body of a webhook delivery.
+type webhookEvent struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Created int64  `json:"created"`
+	Data    struct {
+		IntentID      string `json:"intent_id"`
+		BookingID     string `json:"booking_id"`
+		Amount        int64  `json:"amount"`
+		Currency      string `json:"currency"`
+		FailureReason string `json:"failure_reason,omitempty"`
+	} `json:"data"`
+}
+
+// Sign returns the signature header value for body sent at t.
+func Sign(secret string, body []byte, t time.Time) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	return "t=" + ts + ",v1=" + hex.EncodeToString(mac(secret, ts, body))
+}
+
+// VerifySignature checks header against body and rejects timestamps further than SignatureTolerance from now.
+// An empty secret verifies nothing, since anyone could sign with it.
+func VerifySignature(secret string, body []byte, header string, now time.Time) error {
+	if secret == "" {
+		return fmt.Errorf("%w: no webhook secret configured", ErrInvalidSignature)
+	}
+	var ts string
+	var sigs [][]byte
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch k {
+		case "t":
+			ts = v
+		case "v1":
+			if sig, err := hex.DecodeString(v); err == nil {
+				sigs = append(sigs, sig)
+			}
+		}
+	}
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || len(sigs) == 0 {
+		return fmt.Errorf("%w: malformed %s header", ErrInvalidSignature, SignatureHeader)
+	}
+	if d := now.Sub(time.Unix(unix, 0)); d > SignatureTolerance || d < -SignatureTolerance {
+		return fmt.Errorf("%w: timestamp outside tolerance", ErrInvalidSignature)
+	}
+	want := mac(secret, ts, body)
+	for _, sig := range sigs {
+		if hmac.Equal(sig, want) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+// ParseWebhook verifies a delivery and decodes its event.
+func ParseWebhook(secret string, body []byte, header string, now time.Time) (domain.PaymentEvent, error) {
+	if err := VerifySignature(secret, body, header, now); err != nil {
+		return domain.PaymentEvent{}, err
+	}
+	var ev webhookEvent
+	if err := json.Unmarshal(body, &ev); err != nil {
+		return domain.PaymentEvent{}, fmt.Errorf("decode webhook: %w", err)
+	}
+	if ev.ID == "" || ev.Data.IntentID == "" {
+		return domain.PaymentEvent{}, errors.New("webhook event needs an id and an intent_id")
+	}
+	return domain.PaymentEvent{
+		ID:            ev.ID,
+		Type:          ev.Type,
+		IntentID:      ev.Data.IntentID,
+		BookingID:     ev.Data.BookingID,
+		Amount:        domain.Money{Amount: ev.Data.Amount, Currency: ev.Data.Currency},
+		FailureReason: ev.Data.FailureReason,
+	}, nil
+}
+
+func mac(secret, ts string, body []byte) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(ts))
+	h.Write([]byte("."))
+	h.Write(body)
+	return h.Sum(nil)
+}
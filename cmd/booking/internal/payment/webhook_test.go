@@ -0,0 +1,60 @@
+package payment
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestVerifySignature(t *testing.T) {
+	now := time.Date(2030, 3, 4, 9, 0, 0, 0, time.UTC)
+	body := []byte(`{"id": "evt_1"}`)
+	for _, tt := range []struct {
+		name   string
+		secret string
+		header string
+		ok     bool
+	}{
+		{"valid", "s3cret", Sign("s3cret", body, now), true},
+		{"within tolerance", "s3cret", Sign("s3cret", body, now.Add(-SignatureTolerance+time.Second)), true},
+		{"one of several signatures", "s3cret", Sign("s3cret", body, now) + ",v1=00ff", true},
+		{"wrong secret", "s3cret", Sign("other", body, now), false},
+		{"other body", "s3cret", Sign("s3cret", []byte(`{"id": "evt_2"}`), now), false},
+		{"expired", "s3cret", Sign("s3cret", body, now.Add(-SignatureTolerance-time.Second)), false},
+		{"from the future", "s3cret", Sign("s3cret", body, now.Add(SignatureTolerance+time.Second)), false},
+		{"no secret configured", "", Sign("", body, now), false},
+		{"no timestamp", "s3cret", "v1=00ff", false},
+		{"no signature", "s3cret", "t=1900000000", false},
+		{"empty", "s3cret", "", false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifySignature(tt.secret, body, tt.header, now)
+			if (err == nil) != tt.ok {
+				t.Fatalf("got %v, want ok %v", err, tt.ok)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidSignature) {
+				t.Fatalf("%v is not ErrInvalidSignature", err)
+			}
+		})
+	}
+}
+
+func TestParseWebhook(t *testing.T) {
+	now := time.Date(2030, 3, 4, 9, 0, 0, 0, time.UTC)
+	body := []byte(`{"id": "evt_1", "type": "payment_intent.succeeded", "created": 1900000000,
+		"data": {"intent_id": "pi_1", "booking_id": "b-1", "amount": 3000, "currency": "EUR"}}`)
+	ev, err := ParseWebhook("s3cret", body, Sign("s3cret", body, now), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.ID != "evt_1" || ev.IntentID != "pi_1" || ev.BookingID != "b-1" || ev.Amount.Amount != 3000 || ev.Amount.Currency != "EUR" {
+		t.Fatalf("parsed %+v", ev)
+	}
+	if _, err := ParseWebhook("s3cret", body, Sign("s3cret", body, now), now.Add(time.Hour)); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("replayed an hour later: %v", err)
+	}
+	noIntent := []byte(`{"id": "evt_2", "type": "payment_intent.succeeded", "data": {}}`)
+	if _, err := ParseWebhook("s3cret", noIntent, Sign("s3cret", noIntent, now), now); err == nil {
+		t.Fatal("accepted an event without intent_id")
+	}
+}
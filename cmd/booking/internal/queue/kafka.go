@@ -0,0 +1,124 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"templespace/cmd/booking/internal/events"
+)
+
+// EventTypeHeader carries the event type, e.g. booking_paid, when events share one Kafka topic.
+const EventTypeHeader = "event_type"
+
+// KafkaConfig configures the Kafka producer.
+type KafkaConfig struct {
+	Brokers []string
+	// Acks is "none", "leader" or "all"
+	Acks string
+	// BatchSize and Linger bound how many messages are sent together and how long a batch waits
+	BatchSize int
+	Linger    time.Duration
+	// Compression is "none", "gzip", "snappy", "lz4" or "zstd"
+	Compression  string
+	WriteTimeout time.Duration
+}
+
+// MessageWriter is the part of *kafka.Writer the publisher uses; kafkatest.Broker provides an
+// in-process one.
+type MessageWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// NewKafkaWriter builds a writer that partitions by message key with the murmur2 hash of the Java
+// client, so other producers keyed by booking ID land on the same partitions.
+func NewKafkaWriter(cfg KafkaConfig) (*kafka.Writer, error) {
+	acks, err := parseAcks(cfg.Acks)
+	if err != nil {
+		return nil, err
+	}
+	codec, err := parseCompression(cfg.Compression)
+	if err != nil {
+		return nil, err
+	}
+	return &kafka.Writer{
+		Addr:                   kafka.TCP(cfg.Brokers...),
+		Balancer:               kafka.Murmur2Balancer{},
+		RequiredAcks:           acks,
+		BatchSize:              cfg.BatchSize,
+		BatchTimeout:           cfg.Linger,
+		Compression:            codec,
+		WriteTimeout:           cfg.WriteTimeout,
+		AllowAutoTopicCreation: true,
+	}, nil
+}
+
+func parseAcks(s string) (kafka.RequiredAcks, error) {
+	switch s {
+	case "none", "0":
+		return kafka.RequireNone, nil
+	case "leader", "1":
+		return kafka.RequireOne, nil
+	case "all", "-1", "":
+		return kafka.RequireAll, nil
+	}
+	return 0, fmt.Errorf("kafka: invalid acks %q", s)
+}
+
+func parseCompression(s string) (kafka.Compression, error) {
+	switch strings.ToLower(s) {
+	case "none", "":
+		return 0, nil
+	case "gzip":
+		return kafka.Gzip, nil
+	case "snappy":
+		return kafka.Snappy, nil
+	case "lz4":
+		return kafka.Lz4, nil
+	case "zstd":
+		return kafka.Zstd, nil
+	}
+	return 0, fmt.Errorf("kafka: invalid compression %q", s)
+}
+
+// KafkaPublisher publishes events keyed by aggregate ID, so the events of one booking go to one
+// partition in order. Publish returns once the broker acknowledged the message as configured;
+// concurrent calls share batches.
+type KafkaPublisher struct {
+	w       MessageWriter
+	topic   string
+	timeout time.Duration
+}
+
+// NewKafkaPublisher publishes through w to topic. With an empty topic each event type is its own
+// topic, which loses the ordering between the events of one booking.
+func NewKafkaPublisher(w MessageWriter, topic string, timeout time.Duration) *KafkaPublisher {
+	return &KafkaPublisher{w: w, topic: topic, timeout: timeout}
+}
+
+func (p *KafkaPublisher) Publish(topic string, key string, payload []byte) error {
+	msg := kafka.Message{
+		Topic: topic,
+		Key:   []byte(key),
+		Value: payload,
+		Headers: []kafka.Header{
+			{Key: EventTypeHeader, Value: []byte(topic)},
+			{Key: "content-type", Value: []byte(events.ContentType)},
+		},
+	}
+	if p.topic != "" {
+		msg.Topic = p.topic
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+	return p.w.WriteMessages(ctx, msg)
+}
+
+// Close flushes messages still being batched and closes the writer. Call it after the relay stopped.
+func (p *KafkaPublisher) Close() error {
+	return p.w.Close()
+}
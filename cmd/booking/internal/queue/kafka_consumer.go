@@ -0,0 +1,164 @@
+package queue
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// MessageReader is the part of *kafka.Reader the consumer uses; kafkatest.Broker provides an
+// in-process one.
+type MessageReader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// Handler processes one event. Delivery is at least once, so handlers must tolerate repeats.
+type Handler func(ctx context.Context, eventType, key string, payload []byte) error
+
+// NewKafkaReader reads topics as member groupID of a consumer group, committing offsets explicitly.
+func NewKafkaReader(brokers []string, groupID string, topics ...string) *kafka.Reader {
+	return kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     brokers,
+		GroupID:     groupID,
+		GroupTopics: topics,
+		StartOffset: kafka.FirstOffset,
+	})
+}
+
+// DefaultMaxAttempts is how often a handler is tried on one event when DeadLetter sets no limit.
+const DefaultMaxAttempts = 5
+
+// Headers added to an event written to the dead-letter topic.
+const (
+	DeadLetterTopicHeader     = "dead_letter_topic"
+	DeadLetterPartitionHeader = "dead_letter_partition"
+	DeadLetterOffsetHeader    = "dead_letter_offset"
+	DeadLetterErrorHeader     = "dead_letter_error"
+)
+
+// DeadLetter receives the events a handler keeps failing on.
+type DeadLetter struct {
+	Writer MessageWriter
+	Topic  string
+	// MaxAttempts is how often the handler is tried before the event is dead-lettered; zero means
+	// DefaultMaxAttempts.
+	MaxAttempts int
+}
+
+// KafkaConsumer hands events to a Handler and commits each one after it was handled. A failing
+// handler is retried with backoff; an event it still fails on after MaxAttempts tries is written to
+// the dead-letter topic and committed, so one poison event cannot stall its partition. Events of a
+// key stay in order, except that those after a dead-lettered one go ahead without it.
+type KafkaConsumer struct {
+	r       MessageReader
+	handle  Handler
+	dead    DeadLetter
+	backoff func(attempt int) time.Duration
+}
+
+func NewKafkaConsumer(r MessageReader, handle Handler, dead DeadLetter) *KafkaConsumer {
+	if dead.MaxAttempts <= 0 {
+		dead.MaxAttempts = DefaultMaxAttempts
+	}
+	return &KafkaConsumer{r: r, handle: handle, dead: dead, backoff: backoff}
+}
+
+// Run consumes until ctx is cancelled and then closes the reader; uncommitted events are
+// delivered again to the next member of the group.
+func (c *KafkaConsumer) Run(ctx context.Context) error {
+	defer c.r.Close()
+	for {
+		msg, err := c.r.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		if err := c.deliver(ctx, msg); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+func (c *KafkaConsumer) deliver(ctx context.Context, msg kafka.Message) error {
+	eventType := msg.Topic
+	for _, h := range msg.Headers {
+		if h.Key == EventTypeHeader {
+			eventType = string(h.Value)
+		}
+	}
+	var err error
+	for attempt := 0; attempt < c.dead.MaxAttempts; attempt++ {
+		if attempt > 0 {
+			if err := c.wait(ctx, attempt-1); err != nil {
+				return err
+			}
+		}
+		if err = c.handle(ctx, eventType, string(msg.Key), msg.Value); err == nil {
+			return c.r.CommitMessages(ctx, msg)
+		}
+		log.Printf("kafka consumer: %s %s at %d/%d: %v", eventType, msg.Key, msg.Partition, msg.Offset, err)
+	}
+	if err := c.deadLetter(ctx, msg, err); err != nil {
+		return err
+	}
+	return c.r.CommitMessages(ctx, msg)
+}
+
+// deadLetter writes msg to the dead-letter topic, retrying until it goes through or ctx ends, so
+// the event is never committed without being kept somewhere.
+func (c *KafkaConsumer) deadLetter(ctx context.Context, msg kafka.Message, cause error) error {
+	dl := kafka.Message{
+		Topic: c.dead.Topic,
+		Key:   msg.Key,
+		Value: msg.Value,
+		Headers: append(append([]kafka.Header(nil), msg.Headers...),
+			kafka.Header{Key: DeadLetterTopicHeader, Value: []byte(msg.Topic)},
+			kafka.Header{Key: DeadLetterPartitionHeader, Value: []byte(strconv.Itoa(msg.Partition))},
+			kafka.Header{Key: DeadLetterOffsetHeader, Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+			kafka.Header{Key: DeadLetterErrorHeader, Value: []byte(cause.Error())}),
+	}
+	for attempt := 0; ; attempt++ {
+		err := c.dead.Writer.WriteMessages(ctx, dl)
+		if err == nil {
+			log.Printf("kafka consumer: dead-lettered %s at %d/%d to %s", msg.Key, msg.Partition, msg.Offset, c.dead.Topic)
+			return nil
+		}
+		log.Printf("kafka consumer: dead-letter %s at %d/%d: %v", msg.Key, msg.Partition, msg.Offset, err)
+		if err := c.wait(ctx, attempt); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *KafkaConsumer) wait(ctx context.Context, attempt int) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(c.backoff(attempt)):
+		return nil
+	}
+}
+
+const (
+	consumerMinBackoff = time.Second
+	consumerMaxBackoff = 5 * time.Minute
+)
+
+// backoff doubles the wait after each failed attempt, up to consumerMaxBackoff.
+func backoff(attempts int) time.Duration {
+	d := consumerMinBackoff
+	for i := 0; i < attempts && d < consumerMaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, consumerMaxBackoff)
+}
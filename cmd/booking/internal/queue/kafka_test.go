@@ -0,0 +1,229 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"templespace/cmd/booking/internal/domain"
+	"templespace/cmd/booking/internal/queue/kafkatest"
+	"templespace/cmd/booking/internal/storage"
+	"templespace/internal/outbox"
+)
+
+// These tests run the outbox relay, the producer and the consumer against the in-process broker of
+// kafkatest, so event delivery is checked without a real Kafka.
+
+const (
+	testTopic  = "booking.events"
+	deadTopic  = "booking.events.dead"
+	bookings   = 20
+	perBooking = 10
+	partitions = 3
+)
+
+var eventTypes = []string{"booking_created", "booking_confirmed", "booking_paid", "booking_rescheduled", "booking_cancelled"}
+
+// publishAll stores the events of every booking in an outbox and relays them to broker, which
+// fails its first writes.
+func publishAll(t *testing.T, broker *kafkatest.Broker) {
+	t.Helper()
+	store := storage.NewMemoryOutbox()
+	for i := 0; i < perBooking; i++ {
+		for b := 0; b < bookings; b++ {
+			_ = store.Append(context.Background(), domain.OutboxMessage{
+				Topic:     eventTypes[i%len(eventTypes)],
+				Key:       "booking-" + strconv.Itoa(b),
+				Payload:   []byte(strconv.Itoa(i)),
+				CreatedAt: time.Now(),
+			})
+		}
+	}
+	broker.FailWrites(5)
+	pub := NewKafkaPublisher(broker.Writer(), testTopic, time.Second)
+	defer pub.Close()
+	relay := outbox.NewRelay(store, pub, 100*time.Millisecond)
+	deadline := time.Now().Add(30 * time.Second)
+	for {
+		if _, err := relay.Drain(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		st, _ := store.Stats(context.Background())
+		if st.Pending == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d events still pending", st.Pending)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// noBackoff retries at once, so the tests do not wait out the production backoff.
+func noBackoff(int) time.Duration { return time.Millisecond }
+
+func TestRelayPublishesThroughOutageInKeyOrder(t *testing.T) {
+	broker := kafkatest.NewBroker(partitions)
+	publishAll(t, broker)
+	partitionOf := map[string]int{}
+	next := map[string]int{}
+	total := 0
+	for p := 0; p < partitions; p++ {
+		for _, m := range broker.Messages(testTopic, p) {
+			key := string(m.Key)
+			if q, ok := partitionOf[key]; ok && q != p {
+				t.Fatalf("%s on partitions %d and %d", key, q, p)
+			}
+			partitionOf[key] = p
+			if err := expectNext(next, key, m.Value); err != nil {
+				t.Fatal(err)
+			}
+			if got, want := string(m.Headers[0].Value), eventTypes[(next[key]-1)%len(eventTypes)]; got != want {
+				t.Fatalf("%s event %d has type %s, want %s", key, next[key]-1, got, want)
+			}
+			total++
+		}
+	}
+	if total != bookings*perBooking {
+		t.Fatalf("broker holds %d events, want %d", total, bookings*perBooking)
+	}
+}
+
+func TestConsumerRetriesAndCommitsInKeyOrder(t *testing.T) {
+	broker := kafkatest.NewBroker(partitions)
+	publishAll(t, broker)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	var (
+		mu      sync.Mutex
+		next    = map[string]int{}
+		seen    int
+		failOne = true
+		order   error
+	)
+	handle := func(ctx context.Context, eventType, key string, payload []byte) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if failOne && key == "booking-3" && string(payload) == "4" {
+			failOne = false
+			return errors.New("handler unavailable")
+		}
+		if err := expectNext(next, key, payload); err != nil && order == nil {
+			order = err
+		}
+		if seen++; seen == bookings*perBooking {
+			cancel()
+		}
+		return nil
+	}
+	c := NewKafkaConsumer(broker.Reader("check", testTopic), handle, DeadLetter{Writer: broker.Writer(), Topic: deadTopic})
+	c.backoff = noBackoff
+	if err := c.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if order != nil {
+		t.Fatal(order)
+	}
+	if seen != bookings*perBooking {
+		t.Fatalf("handled %d events, want %d", seen, bookings*perBooking)
+	}
+	if lag := broker.Lag("check", testTopic); lag != 0 {
+		t.Fatalf("group lags %d events behind", lag)
+	}
+	for p := 0; p < partitions; p++ {
+		if dead := broker.Messages(deadTopic, p); len(dead) != 0 {
+			t.Fatalf("%d events dead-lettered after a single failure", len(dead))
+		}
+	}
+}
+
+func TestConsumerDeadLettersPoisonEvent(t *testing.T) {
+	broker := kafkatest.NewBroker(1)
+	pub := NewKafkaPublisher(broker.Writer(), testTopic, time.Second)
+	for i, payload := range []string{"0", "poison", "2"} {
+		if err := pub.Publish(eventTypes[i], "booking-1", []byte(payload)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var handled []string
+	tries := 0
+	handle := func(ctx context.Context, eventType, key string, payload []byte) error {
+		if string(payload) == "poison" {
+			tries++
+			return errors.New("cannot decode")
+		}
+		if handled = append(handled, string(payload)); len(handled) == 2 {
+			cancel()
+		}
+		return nil
+	}
+	c := NewKafkaConsumer(broker.Reader("check", testTopic), handle, DeadLetter{Writer: broker.Writer(), Topic: deadTopic, MaxAttempts: 3})
+	c.backoff = noBackoff
+	// the first dead-letter write fails and is retried
+	broker.FailWrites(1)
+	if err := c.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if tries != 3 || len(handled) != 2 || handled[1] != "2" {
+		t.Fatalf("poison tried %d times, handled %v; want 3 tries and the events around it", tries, handled)
+	}
+	if lag := broker.Lag("check", testTopic); lag != 0 {
+		t.Fatalf("group lags %d events behind", lag)
+	}
+	dead := broker.Messages(deadTopic, 0)
+	if len(dead) != 1 || string(dead[0].Value) != "poison" {
+		t.Fatalf("dead-letter topic holds %v, want the poison event", dead)
+	}
+	headers := map[string]string{}
+	for _, h := range dead[0].Headers {
+		headers[h.Key] = string(h.Value)
+	}
+	want := map[string]string{
+		EventTypeHeader:           "booking_confirmed",
+		DeadLetterTopicHeader:     testTopic,
+		DeadLetterPartitionHeader: "0",
+		DeadLetterOffsetHeader:    "1",
+		DeadLetterErrorHeader:     "cannot decode",
+	}
+	for k, v := range want {
+		if headers[k] != v {
+			t.Errorf("header %s = %q, want %q", k, headers[k], v)
+		}
+	}
+}
+
+func TestPublisherCloseFlushesAndRejectsLaterEvents(t *testing.T) {
+	broker := kafkatest.NewBroker(partitions)
+	pub := NewKafkaPublisher(broker.Writer(), testTopic, time.Second)
+	if err := pub.Publish("booking_created", "booking-1", []byte("0")); err != nil {
+		t.Fatal(err)
+	}
+	if err := pub.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := pub.Publish("booking_paid", "booking-1", []byte("1")); err == nil {
+		t.Fatal("publish after close succeeded")
+	}
+	total := 0
+	for p := 0; p < partitions; p++ {
+		total += len(broker.Messages(testTopic, p))
+	}
+	if total != 1 {
+		t.Fatalf("broker holds %d events, want the one published before close", total)
+	}
+}
+
+// expectNext checks that payload is the next sequence number of key.
+func expectNext(next map[string]int, key string, payload []byte) error {
+	if got := string(payload); got != strconv.Itoa(next[key]) {
+		return fmt.Errorf("%s: got event %s, want %d", key, got, next[key])
+	}
+	next[key]++
+	return nil
+}
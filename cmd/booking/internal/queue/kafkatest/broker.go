@@ -0,0 +1,206 @@
+// Package kafkatest provides an in-process stand-in for a Kafka cluster, so the producer and
+// consumer can be exercised without a real broker.
+package kafkatest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// ErrUnavailable is returned by writes the broker was told to fail.
+var ErrUnavailable = errors.New("kafkatest: broker unavailable")
+
+// Broker keeps topics in memory. Topics are created on first write with the broker's partition
+// count, keyed messages are partitioned like queue.NewKafkaWriter does, and consumer groups track
+// committed offsets per partition.
+type Broker struct {
+	mu         sync.Mutex
+	changed    chan struct{} // closed and replaced on every write
+	partitions int
+	topics     map[string][][]kafka.Message
+	committed  map[string]map[string][]int64 // group -> topic -> next offset per partition
+	failWrites int
+}
+
+func NewBroker(partitions int) *Broker {
+	return &Broker{
+		changed:    make(chan struct{}),
+		partitions: partitions,
+		topics:     map[string][][]kafka.Message{},
+		committed:  map[string]map[string][]int64{},
+	}
+}
+
+// FailWrites makes the next n writes fail with ErrUnavailable.
+func (b *Broker) FailWrites(n int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failWrites = n
+}
+
+// Messages returns the messages of partition p of topic in offset order.
+func (b *Broker) Messages(topic string, p int) []kafka.Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if p >= len(b.topics[topic]) {
+		return nil
+	}
+	return append([]kafka.Message(nil), b.topics[topic][p]...)
+}
+
+func (b *Broker) write(msgs []kafka.Message) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failWrites > 0 {
+		b.failWrites--
+		return ErrUnavailable
+	}
+	all := make([]int, b.partitions)
+	for i := range all {
+		all[i] = i
+	}
+	balancer := kafka.Murmur2Balancer{}
+	for _, m := range msgs {
+		parts := b.topic(m.Topic)
+		p := balancer.Balance(m, all...)
+		m.Partition = p
+		m.Offset = int64(len(parts[p]))
+		m.Time = time.Now()
+		parts[p] = append(parts[p], m)
+	}
+	close(b.changed)
+	b.changed = make(chan struct{})
+	return nil
+}
+
+func (b *Broker) topic(name string) [][]kafka.Message {
+	if b.topics[name] == nil {
+		b.topics[name] = make([][]kafka.Message, b.partitions)
+	}
+	return b.topics[name]
+}
+
+// Writer returns a writer producing to the broker. Messages handed to one WriteMessages call are
+// stored together or not at all; Close flushes nothing since every write is synchronous.
+func (b *Broker) Writer() *Writer {
+	return &Writer{b: b}
+}
+
+type Writer struct {
+	b *Broker
+
+	mu     sync.Mutex
+	closed bool
+}
+
+func (w *Writer) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	w.mu.Lock()
+	closed := w.closed
+	w.mu.Unlock()
+	if closed {
+		return io.ErrClosedPipe
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return w.b.write(msgs)
+}
+
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	return nil
+}
+
+// Reader returns a member of consumer group that reads all partitions of topics, starting at the
+// group's committed offsets. Fetched but uncommitted messages are fetched again by the next reader
+// of the group.
+func (b *Broker) Reader(group string, topics ...string) *Reader {
+	return &Reader{b: b, group: group, topics: topics, next: map[string][]int64{}}
+}
+
+type Reader struct {
+	b      *Broker
+	group  string
+	topics []string
+	next   map[string][]int64 // fetch position per topic and partition
+}
+
+func (r *Reader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	for {
+		r.b.mu.Lock()
+		msg, ok := r.poll()
+		changed := r.b.changed
+		r.b.mu.Unlock()
+		if ok {
+			return msg, nil
+		}
+		select {
+		case <-ctx.Done():
+			return kafka.Message{}, ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// poll returns the next unfetched message, taking topics and partitions in turn.
+func (r *Reader) poll() (kafka.Message, bool) {
+	for _, t := range r.topics {
+		parts := r.b.topic(t)
+		next := r.next[t]
+		if next == nil {
+			next = append([]int64(nil), r.b.offsets(r.group, t)...)
+			r.next[t] = next
+		}
+		for p, msgs := range parts {
+			if next[p] < int64(len(msgs)) {
+				msg := msgs[next[p]]
+				next[p]++
+				return msg, true
+			}
+		}
+	}
+	return kafka.Message{}, false
+}
+
+// offsets returns the committed offsets of group on topic; b.mu must be held.
+func (b *Broker) offsets(group, topic string) []int64 {
+	if b.committed[group] == nil {
+		b.committed[group] = map[string][]int64{}
+	}
+	if b.committed[group][topic] == nil {
+		b.committed[group][topic] = make([]int64, b.partitions)
+	}
+	return b.committed[group][topic]
+}
+
+func (r *Reader) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	r.b.mu.Lock()
+	defer r.b.mu.Unlock()
+	for _, m := range msgs {
+		offsets := r.b.offsets(r.group, m.Topic)
+		if m.Offset+1 > offsets[m.Partition] {
+			offsets[m.Partition] = m.Offset + 1
+		}
+	}
+	return nil
+}
+
+// Lag returns how many messages of topic group has not committed yet.
+func (b *Broker) Lag(group, topic string) int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var lag int64
+	for p, msgs := range b.topic(topic) {
+		lag += int64(len(msgs)) - b.offsets(group, topic)[p]
+	}
+	return lag
+}
+
+func (r *Reader) Close() error { return nil }
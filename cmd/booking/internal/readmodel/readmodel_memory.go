@@ -3,22 +3,78 @@ package readmodel
 import (
 	"sync"
 	"time"
+
+	"templespace/cmd/booking/internal/domain"
 )
 
+// freeSlotsTTL caps staleness of cached free slots when another instance changed the space.
+const freeSlotsTTL = 30 * time.Second
+
+type freeSlotsEntry struct {
+	free   []domain.FreeSlot
+	expiry time.Time
+}
+
+// MemoryReadModel caches the free slots computed for a space until a write to the space invalidates
+// them or they age past freeSlotsTTL.
 type MemoryReadModel struct {
+	now func() time.Time
+
 	mu sync.Mutex
-	// key: spaceID|start|end
-	availability map[string]bool
+	// spaceID -> key: from|to|granularity
+	freeSlots map[string]map[string]freeSlotsEntry
 }
 
 func NewMemoryReadModel() *MemoryReadModel {
-	return &MemoryReadModel{availability: map[string]bool{}}
+	return &MemoryReadModel{now: time.Now, freeSlots: map[string]map[string]freeSlotsEntry{}}
 }
 
-func (m *MemoryReadModel) CacheAvailability(spaceID string, start, end time.Time, available bool) error {
+func (m *MemoryReadModel) Invalidate(spaceID string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	key := spaceID + "|" + start.UTC().Format(time.RFC3339) + "|" + end.UTC().Format(time.RFC3339)
-	m.availability[key] = available
+	delete(m.freeSlots, spaceID)
 	return nil
 }
+
+func (m *MemoryReadModel) FreeSlots(spaceID string, from, to time.Time, granularity time.Duration) ([]domain.FreeSlot, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.freeSlots[spaceID][freeSlotsKey(from, to, granularity)]
+	if !ok || m.now().After(e.expiry) {
+		return nil, false
+	}
+	out := make([]domain.FreeSlot, len(e.free))
+	copy(out, e.free)
+	return out, true
+}
+
+func (m *MemoryReadModel) CacheFreeSlots(spaceID string, from, to time.Time, granularity time.Duration, free []domain.FreeSlot) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	bySpace := m.freeSlots[spaceID]
+	if bySpace == nil {
+		bySpace = map[string]freeSlotsEntry{}
+		m.freeSlots[spaceID] = bySpace
+	}
+	now := m.now()
+	for k, e := range bySpace {
+		if now.After(e.expiry) {
+			delete(bySpace, k)
+		}
+	}
+	cp := make([]domain.FreeSlot, len(free))
+	copy(cp, free)
+	bySpace[freeSlotsKey(from, to, granularity)] = freeSlotsEntry{free: cp, expiry: now.Add(freeSlotsTTL)}
+	return nil
+}
+
+func (m *MemoryReadModel) Reset() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.freeSlots = map[string]map[string]freeSlotsEntry{}
+	return nil
+}
+
+func freeSlotsKey(from, to time.Time, granularity time.Duration) string {
+	return from.UTC().Format(time.RFC3339) + "|" + to.UTC().Format(time.RFC3339) + "|" + granularity.String()
+}
@@ -0,0 +1,71 @@
+package readmodel
+
+import (
+	"testing"
+	"time"
+
+	"templespace/cmd/booking/internal/domain"
+)
+
+func TestMemoryReadModelFreeSlots(t *testing.T) {
+	now := time.Date(2030, 3, 4, 9, 0, 0, 0, time.UTC)
+	m := NewMemoryReadModel()
+	m.now = func() time.Time { return now }
+	from, to := now, now.Add(8*time.Hour)
+	free := []domain.FreeSlot{{Interval: domain.Interval{Start: from, End: to}, Seats: 1}}
+	if err := m.CacheFreeSlots("space-1", from, to, time.Hour, free); err != nil {
+		t.Fatal(err)
+	}
+
+	got, ok := m.FreeSlots("space-1", from, to, time.Hour)
+	if !ok || len(got) != 1 || got[0] != free[0] {
+		t.Fatalf("cached slots %v, %v", got, ok)
+	}
+	// callers get a copy they may change
+	got[0].Seats = 0
+	if again, _ := m.FreeSlots("space-1", from, to, time.Hour); again[0].Seats != 1 {
+		t.Fatal("changing the returned slots changed the cache")
+	}
+	if _, ok := m.FreeSlots("space-1", from, to, 30*time.Minute); ok {
+		t.Fatal("hit for another granularity")
+	}
+	if _, ok := m.FreeSlots("space-2", from, to, time.Hour); ok {
+		t.Fatal("hit for another space")
+	}
+
+	now = now.Add(freeSlotsTTL)
+	if _, ok := m.FreeSlots("space-1", from, to, time.Hour); !ok {
+		t.Fatal("miss at the end of the TTL")
+	}
+	now = now.Add(time.Second)
+	if _, ok := m.FreeSlots("space-1", from, to, time.Hour); ok {
+		t.Fatal("hit past the TTL")
+	}
+}
+
+func TestMemoryReadModelInvalidate(t *testing.T) {
+	now := time.Date(2030, 3, 4, 9, 0, 0, 0, time.UTC)
+	m := NewMemoryReadModel()
+	m.now = func() time.Time { return now }
+	from, to := now, now.Add(8*time.Hour)
+	for _, space := range []string{"space-1", "space-2"} {
+		if err := m.CacheFreeSlots(space, from, to, time.Hour, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := m.Invalidate("space-1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m.FreeSlots("space-1", from, to, time.Hour); ok {
+		t.Fatal("hit after invalidating the space")
+	}
+	if _, ok := m.FreeSlots("space-2", from, to, time.Hour); !ok {
+		t.Fatal("invalidating space-1 dropped space-2")
+	}
+	if err := m.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m.FreeSlots("space-2", from, to, time.Hour); ok {
+		t.Fatal("hit after a reset")
+	}
+}
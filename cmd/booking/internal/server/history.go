@@ -0,0 +1,107 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"templespace/cmd/booking/internal/domain"
+)
+
+// changeResponse lists the fields a history event set; the first event of a booking sets all of them.
+type changeResponse struct {
+	SpaceID       *string        `json:"space_id,omitempty"`
+	UserID        *string        `json:"user_id,omitempty"`
+	SeriesID      *string        `json:"series_id,omitempty"`
+	SlotStart     *time.Time     `json:"slot_start,omitempty"`
+	SlotEnd       *time.Time     `json:"slot_end,omitempty"`
+	BufferBefore  string         `json:"buffer_before,omitempty"`
+	BufferAfter   string         `json:"buffer_after,omitempty"`
+	Seats         *int           `json:"seats,omitempty"`
+	Capacity      *int           `json:"capacity,omitempty"`
+	Total         *moneyResponse `json:"total,omitempty"`
+	VoucherCode   *string        `json:"voucher_code,omitempty"`
+	Refunded      *moneyResponse `json:"refunded,omitempty"`
+	Status        *string        `json:"status,omitempty"`
+	HoldExpiresAt *time.Time     `json:"hold_expires_at,omitempty"`
+}
+
+type historyEventResponse struct {
+	Version int            `json:"version"`
+	Type    string         `json:"type"`
+	Actor   string         `json:"actor,omitempty"`
+	At      time.Time      `json:"at"`
+	Change  changeResponse `json:"change"`
+}
+
+type historyResponse struct {
+	BookingID string                 `json:"booking_id"`
+	Events    []historyEventResponse `json:"events"`
+}
+
+func toChange(c domain.BookingChange) changeResponse {
+	out := changeResponse{
+		SpaceID:       c.SpaceID,
+		UserID:        c.UserID,
+		SeriesID:      c.SeriesID,
+		SlotStart:     c.SlotStart,
+		SlotEnd:       c.SlotEnd,
+		Seats:         c.Seats,
+		Capacity:      c.Capacity,
+		VoucherCode:   c.VoucherCode,
+		HoldExpiresAt: c.HoldExpiresAt,
+	}
+	if c.BufferBefore != nil {
+		out.BufferBefore = c.BufferBefore.String()
+	}
+	if c.BufferAfter != nil {
+		out.BufferAfter = c.BufferAfter.String()
+	}
+	if c.Total != nil {
+		total := toMoney(*c.Total)
+		out.Total = &total
+	}
+	if c.Refunded != nil {
+		refunded := toMoney(*c.Refunded)
+		out.Refunded = &refunded
+	}
+	if c.Status != nil {
+		status := string(*c.Status)
+		out.Status = &status
+	}
+	return out
+}
+
+// handleHistory serves GET /booking/{id}/history.
+func (s *HTTPServer) handleHistory(w http.ResponseWriter, r *http.Request) {
+	id := bookingID(r.URL.Path, "history")
+	events, err := s.svc.History(r.Context(), bearerToken(r), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	out := historyResponse{BookingID: id, Events: make([]historyEventResponse, 0, len(events))}
+	for _, e := range events {
+		out.Events = append(out.Events, historyEventResponse{
+			Version: e.Version,
+			Type:    e.Type,
+			Actor:   e.Actor,
+			At:      e.At,
+			Change:  toChange(e.Change),
+		})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// handleRebuildReadModel serves POST /booking/read-model/rebuild.
+func (s *HTTPServer) handleRebuildReadModel(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	n, err := s.svc.RebuildReadModel(r.Context(), bearerToken(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]int{"replayed": n})
+}
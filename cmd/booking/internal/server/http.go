@@ -1,27 +1,64 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"expvar"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"templespace/cmd/booking/internal/config"
+	"templespace/cmd/booking/internal/domain"
+	"templespace/cmd/booking/internal/events"
+	"templespace/cmd/booking/internal/service"
 )
 
 type HTTPServer struct {
 	cfg *config.Config
+	svc *service.Service
+
+	mu  sync.Mutex
+	srv *http.Server
 }
 
-func NewHTTPServer(cfg *config.Config) *HTTPServer {
-	return &HTTPServer{cfg: cfg}
+func NewHTTPServer(cfg *config.Config, svc *service.Service) *HTTPServer {
+	return &HTTPServer{cfg: cfg, svc: svc}
 }
 
 func (s *HTTPServer) Listen(addr string) error {
-	mux := http.NewServeMux()
-	mux.HandleFunc("/booking", s.handleCreateBooking)
-	mux.HandleFunc("/booking/", s.handleBookingActions)
+	s.mu.Lock()
+	s.srv = &http.Server{Addr: addr, Handler: s.Handler()}
+	s.mu.Unlock()
 	log.Printf("http listening on %s", addr)
-	return http.ListenAndServe(addr, mux)
+	return s.srv.ListenAndServe()
+}
+
+// Handler routes the booking API.
+func (s *HTTPServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/booking", s.idempotent(s.handleCreateBooking))
+	mux.HandleFunc("/booking/", s.idempotent(s.handleBookingActions))
+	mux.HandleFunc("/spaces/", s.handleAvailability) // expects GET /spaces/{id}/availability
+	mux.HandleFunc("/vouchers", s.handleVouchers)
+	mux.HandleFunc("/vouchers/", s.handleRedemptions) // expects GET /vouchers/{code}/redemptions
+	mux.Handle("/debug/vars", expvar.Handler())       // outbox relay metrics among others
+	mux.Handle(events.SchemaPath, events.SchemaHandler())
+	return mux
+}
+
+// Shutdown stops accepting requests and waits for running ones to finish.
+func (s *HTTPServer) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.srv == nil {
+		return nil
+	}
+	return s.srv.Shutdown(ctx)
 }
 
 type createBookingRequest struct {
@@ -29,6 +66,58 @@ type createBookingRequest struct {
 	UserID    string `json:"user_id"`
 	SlotStart string `json:"slot_start"`
 	SlotEnd   string `json:"slot_end"`
+	// Seats defaults to 1; more than one needs a shared space
+	Seats       int    `json:"seats"`
+	VoucherCode string `json:"voucher_code"`
+}
+
+type bookingResponse struct {
+	ID          string        `json:"id"`
+	SpaceID     string        `json:"space_id"`
+	UserID      string        `json:"user_id"`
+	SeriesID    string        `json:"series_id,omitempty"`
+	SlotStart   time.Time     `json:"slot_start"`
+	SlotEnd     time.Time     `json:"slot_end"`
+	Seats       int           `json:"seats"`
+	Total       moneyResponse `json:"total"`
+	VoucherCode string        `json:"voucher_code,omitempty"`
+	// Refunded is set once a cancelled paid booking was refunded
+	Refunded *moneyResponse `json:"refunded,omitempty"`
+	Status   string         `json:"status"`
+	Version  int            `json:"version"`
+	// HoldExpiresAt is set while an unpaid booking is held
+	HoldExpiresAt *time.Time `json:"hold_expires_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	// Payment is the intent opened for a new priced booking
+	Payment *paymentResponse `json:"payment,omitempty"`
+}
+
+func toBookingResponse(b *domain.Booking) bookingResponse {
+	out := bookingResponse{
+		ID:          b.ID,
+		SpaceID:     b.SpaceID,
+		UserID:      b.UserID,
+		SeriesID:    b.SeriesID,
+		SlotStart:   b.SlotStart,
+		SlotEnd:     b.SlotEnd,
+		Seats:       max(b.Seats, 1),
+		Total:       toMoney(b.Total),
+		VoucherCode: b.VoucherCode,
+		Status:      string(b.Status),
+		Version:     b.Version,
+		CreatedAt:   b.CreatedAt,
+		UpdatedAt:   b.UpdatedAt,
+	}
+	if b.Refunded.Amount > 0 {
+		refunded := toMoney(b.Refunded)
+		out.Refunded = &refunded
+	}
+	if b.Status == domain.StatusPending && !b.HoldExpiresAt.IsZero() {
+		hold := b.HoldExpiresAt
+		out.HoldExpiresAt = &hold
+	}
+	return out
 }
 
 func (s *HTTPServer) handleCreateBooking(w http.ResponseWriter, r *http.Request) {
@@ -41,23 +130,272 @@ func (s *HTTPServer) handleCreateBooking(w http.ResponseWriter, r *http.Request)
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	// TODO: integrate with domain service
-	_ = req
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusAccepted)
-	_ = json.NewEncoder(w).Encode(map[string]any{"status": "pending", "message": "stub"})
+	start, err := time.Parse(time.RFC3339, req.SlotStart)
+	if err != nil {
+		http.Error(w, "invalid slot_start", http.StatusBadRequest)
+		return
+	}
+	end, err := time.Parse(time.RFC3339, req.SlotEnd)
+	if err != nil {
+		http.Error(w, "invalid slot_end", http.StatusBadRequest)
+		return
+	}
+	b, err := s.svc.CreateBooking(r.Context(), bearerToken(r), req.SpaceID, req.UserID, start, end, req.Seats, req.VoucherCode)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	resp := toBookingResponse(b)
+	if pi, err := s.svc.PaymentIntentOf(r.Context(), bearerToken(r), b.ID); err == nil {
+		resp.Payment = toPaymentResponse(pi)
+	}
+	w.Header().Set("ETag", etag(b.Version))
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+type createRecurringRequest struct {
+	createBookingRequest
+	RRule        string   `json:"rrule"`
+	ExDates      []string `json:"exdates"`
+	TimeZone     string   `json:"timezone"`
+	AllowPartial bool     `json:"allow_partial"`
+}
+
+type seriesResponse struct {
+	SeriesID  string             `json:"series_id,omitempty"`
+	Bookings  []bookingResponse  `json:"bookings"`
+	Conflicts []intervalResponse `json:"conflicts"`
+}
+
+func (s *HTTPServer) handleCreateRecurring(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var req createRecurringRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	start, err := time.Parse(time.RFC3339, req.SlotStart)
+	if err != nil {
+		http.Error(w, "invalid slot_start", http.StatusBadRequest)
+		return
+	}
+	end, err := time.Parse(time.RFC3339, req.SlotEnd)
+	if err != nil {
+		http.Error(w, "invalid slot_end", http.StatusBadRequest)
+		return
+	}
+	exdates := make([]time.Time, 0, len(req.ExDates))
+	for _, x := range req.ExDates {
+		t, err := time.Parse(time.RFC3339, x)
+		if err != nil {
+			http.Error(w, "invalid exdate "+x, http.StatusBadRequest)
+			return
+		}
+		exdates = append(exdates, t)
+	}
+	res, err := s.svc.CreateRecurringBooking(r.Context(), bearerToken(r), service.RecurringRequest{
+		SpaceID:      req.SpaceID,
+		UserID:       req.UserID,
+		SlotStart:    start,
+		SlotEnd:      end,
+		RRule:        req.RRule,
+		ExDates:      exdates,
+		TimeZone:     req.TimeZone,
+		AllowPartial: req.AllowPartial,
+		Seats:        req.Seats,
+	})
+	var conflict *domain.SeriesConflictError
+	if errors.As(err, &conflict) {
+		writeJSON(w, http.StatusConflict, map[string]any{"error": err.Error(), "conflicts": toIntervals(conflict.Conflicts)})
+		return
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	out := seriesResponse{SeriesID: res.SeriesID, Bookings: make([]bookingResponse, 0, len(res.Bookings)), Conflicts: toIntervals(res.Conflicts)}
+	for _, b := range res.Bookings {
+		out.Bookings = append(out.Bookings, toBookingResponse(b))
+	}
+	writeJSON(w, http.StatusCreated, out)
+}
+
+// handleCancelFollowing serves POST /booking/{id}/cancel?scope=following.
+func (s *HTTPServer) handleCancelFollowing(w http.ResponseWriter, r *http.Request) {
+	version, ok := ifMatchVersion(r)
+	if !ok {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+	cancelled, err := s.svc.CancelFollowing(r.Context(), bearerToken(r), bookingID(r.URL.Path, "cancel"), version)
+	if err != nil {
+		writeVersionedError(w, err, version)
+		return
+	}
+	out := seriesResponse{Bookings: make([]bookingResponse, 0, len(cancelled)), Conflicts: []intervalResponse{}}
+	for _, b := range cancelled {
+		out.SeriesID = b.SeriesID
+		out.Bookings = append(out.Bookings, toBookingResponse(b))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+type rescheduleRequest struct {
+	SlotStart string `json:"slot_start"`
+	SlotEnd   string `json:"slot_end"`
+}
+
+// handleReschedule serves POST /booking/{id}/reschedule.
+func (s *HTTPServer) handleReschedule(w http.ResponseWriter, r *http.Request) {
+	version, ok := ifMatchVersion(r)
+	if !ok {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+	var req rescheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	start, err := time.Parse(time.RFC3339, req.SlotStart)
+	if err != nil {
+		http.Error(w, "invalid slot_start", http.StatusBadRequest)
+		return
+	}
+	end, err := time.Parse(time.RFC3339, req.SlotEnd)
+	if err != nil {
+		http.Error(w, "invalid slot_end", http.StatusBadRequest)
+		return
+	}
+	b, err := s.svc.RescheduleBooking(r.Context(), bearerToken(r), bookingID(r.URL.Path, "reschedule"), start, end, version)
+	if err != nil {
+		writeVersionedError(w, err, version)
+		return
+	}
+	writeBooking(w, http.StatusOK, b)
+}
+
+func toIntervals(ivs []domain.Interval) []intervalResponse {
+	out := make([]intervalResponse, 0, len(ivs))
+	for _, iv := range ivs {
+		out = append(out, intervalResponse{Start: iv.Start, End: iv.End})
+	}
+	return out
 }
 
+// bookingAction is a service call that moves a booking along its lifecycle.
+type bookingAction func(ctx context.Context, accessToken, bookingID string, expectedVersion int) (*domain.Booking, error)
+
 func (s *HTTPServer) handleBookingActions(w http.ResponseWriter, r *http.Request) {
-	// naive routing for /booking/{id}/pay and /booking/{id}/cancel
-	switch {
-	case r.Method == http.MethodPost && hasSuffix(r.URL.Path, "/pay"):
-		s.handlePay(w, r)
-	case r.Method == http.MethodPost && hasSuffix(r.URL.Path, "/cancel"):
-		s.handleCancel(w, r)
-	default:
+	if r.URL.Path == "/booking/payments/webhook" {
+		// without a secret no delivery could be told from a forgery
+		if s.cfg.PaymentWebhookSecret == "" {
+			http.NotFound(w, r)
+			return
+		}
+		s.handlePaymentWebhook(w, r)
+		return
+	}
+	if r.URL.Path == "/booking/recurring" {
+		s.handleCreateRecurring(w, r)
+		return
+	}
+	if r.URL.Path == "/booking/quote" {
+		s.handleQuote(w, r)
+		return
+	}
+	if r.URL.Path == "/booking/read-model/rebuild" {
+		s.handleRebuildReadModel(w, r)
+		return
+	}
+	if r.Method == http.MethodGet && hasSuffix(r.URL.Path, "/history") {
+		s.handleHistory(w, r)
+		return
+	}
+	if r.Method == http.MethodPost && hasSuffix(r.URL.Path, "/cancel") && r.URL.Query().Get("scope") == "following" {
+		s.handleCancelFollowing(w, r)
+		return
+	}
+	if r.Method == http.MethodPost && hasSuffix(r.URL.Path, "/reschedule") {
+		s.handleReschedule(w, r)
+		return
+	}
+	if r.Method == http.MethodPost && hasSuffix(r.URL.Path, "/payment") {
+		s.handleStartPayment(w, r)
+		return
+	}
+	// naive routing for /booking/{id}/{action}
+	actions := map[string]bookingAction{
+		"pay":      s.svc.ConfirmPayment,
+		"cancel":   s.svc.CancelBooking,
+		"confirm":  s.svc.ConfirmBooking,
+		"complete": s.svc.CompleteBooking,
+		"no-show":  s.svc.MarkNoShow,
+	}
+	for name, action := range actions {
+		if r.Method == http.MethodPost && hasSuffix(r.URL.Path, "/"+name) {
+			s.handleAction(w, r, name, action)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNotFound)
+}
+
+type intervalResponse struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	// SeatsLeft is set on free slots only
+	SeatsLeft int `json:"seats_left,omitempty"`
+}
+
+type availabilityResponse struct {
+	SpaceID     string             `json:"space_id"`
+	From        time.Time          `json:"from"`
+	To          time.Time          `json:"to"`
+	Granularity string             `json:"granularity,omitempty"`
+	Free        []intervalResponse `json:"free"`
+}
+
+func (s *HTTPServer) handleAvailability(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet || !hasSuffix(r.URL.Path, "/availability") {
 		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	spaceID := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/spaces/"), "/availability")
+	q := r.URL.Query()
+	from, err := time.Parse(time.RFC3339, q.Get("from"))
+	if err != nil {
+		http.Error(w, "invalid from", http.StatusBadRequest)
+		return
 	}
+	to, err := time.Parse(time.RFC3339, q.Get("to"))
+	if err != nil {
+		http.Error(w, "invalid to", http.StatusBadRequest)
+		return
+	}
+	var granularity time.Duration
+	if g := q.Get("granularity"); g != "" {
+		if granularity, err = time.ParseDuration(g); err != nil {
+			http.Error(w, "invalid granularity", http.StatusBadRequest)
+			return
+		}
+	}
+	free, err := s.svc.FreeSlots(r.Context(), spaceID, from, to, granularity)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	out := availabilityResponse{SpaceID: spaceID, From: from.UTC(), To: to.UTC(), Free: make([]intervalResponse, 0, len(free))}
+	for _, fs := range free {
+		out.Free = append(out.Free, intervalResponse{Start: fs.Start, End: fs.End, SeatsLeft: fs.Seats})
+	}
+	if granularity > 0 {
+		out.Granularity = granularity.String()
+	}
+	writeJSON(w, http.StatusOK, out)
 }
 
 func hasSuffix(path, suffix string) bool {
@@ -67,14 +405,98 @@ func hasSuffix(path, suffix string) bool {
 	return path[len(path)-len(suffix):] == suffix
 }
 
-func (s *HTTPServer) handlePay(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusAccepted)
-	_ = json.NewEncoder(w).Encode(map[string]any{"status": "paid", "message": "stub"})
+// bookingID extracts {id} from /booking/{id}/{action}.
+func bookingID(path, action string) string {
+	id := strings.TrimPrefix(path, "/booking/")
+	return strings.TrimSuffix(id, "/"+action)
+}
+
+// bearerToken returns the token from an "Authorization: Bearer <token>" header.
+func bearerToken(r *http.Request) string {
+	h := r.Header.Get("Authorization")
+	if len(h) > 7 && strings.EqualFold(h[:7], "Bearer ") {
+		return strings.TrimSpace(h[7:])
+	}
+	return ""
 }
 
-func (s *HTTPServer) handleCancel(w http.ResponseWriter, r *http.Request) {
+func (s *HTTPServer) handleAction(w http.ResponseWriter, r *http.Request, name string, action bookingAction) {
+	version, ok := ifMatchVersion(r)
+	if !ok {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+	b, err := action(r.Context(), bearerToken(r), bookingID(r.URL.Path, name), version)
+	if err != nil {
+		writeVersionedError(w, err, version)
+		return
+	}
+	writeBooking(w, http.StatusOK, b)
+}
+
+// etag renders a booking version as a strong entity tag.
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// ifMatchVersion parses If-Match into the expected booking version; 0 means unconditional.
+// ok is false when the header is present but cannot name a booking version.
+func ifMatchVersion(r *http.Request) (version int, ok bool) {
+	h := strings.TrimSpace(r.Header.Get("If-Match"))
+	if h == "" || h == "*" {
+		return 0, true
+	}
+	h = strings.TrimPrefix(h, "W/")
+	n, err := strconv.Atoi(strings.Trim(h, `"`))
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+	return n, true
+}
+
+func writeBooking(w http.ResponseWriter, status int, b *domain.Booking) {
+	w.Header().Set("ETag", etag(b.Version))
+	writeJSON(w, status, toBookingResponse(b))
+}
+
+// writeVersionedError reports a stale If-Match as 412 rather than a plain conflict.
+func writeVersionedError(w http.ResponseWriter, err error, expectedVersion int) {
+	if expectedVersion != 0 && errors.Is(err, domain.ErrConflict) {
+		writeJSON(w, http.StatusPreconditionFailed, map[string]string{"error": err.Error()})
+		return
+	}
+	writeError(w, err)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusAccepted)
-	_ = json.NewEncoder(w).Encode(map[string]any{"status": "cancelled", "message": "stub"})
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError maps domain errors onto HTTP status codes. Any other error is a failure of the service
+// or its infrastructure: it is logged and answered with 500 without its details, which also keeps
+// idempotent requests that hit it retryable.
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput), errors.Is(err, domain.ErrPolicyViolation), errors.Is(err, domain.ErrVoucherRejected):
+		status = http.StatusBadRequest
+	case errors.Is(err, domain.ErrUnauthorized):
+		status = http.StatusUnauthorized
+	case errors.Is(err, domain.ErrForbidden):
+		status = http.StatusForbidden
+	case errors.Is(err, domain.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, domain.ErrSlotNotAvailable), errors.Is(err, domain.ErrConflict), errors.Is(err, domain.ErrInvalidTransition),
+		errors.Is(err, domain.ErrVoucherExists), errors.Is(err, domain.ErrIdempotencyMismatch), errors.Is(err, domain.ErrIdempotencyInFlight):
+		status = http.StatusConflict
+	case errors.Is(err, domain.ErrNotEnabled):
+		status = http.StatusNotImplemented
+	default:
+		log.Printf("internal error: %v", err)
+		writeJSON(w, status, map[string]string{"error": "internal error"})
+		return
+	}
+	writeJSON(w, status, map[string]string{"error": err.Error()})
 }
@@ -0,0 +1,163 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"templespace/cmd/booking/internal/config"
+	"templespace/cmd/booking/internal/payment"
+	"templespace/cmd/booking/internal/readmodel"
+	"templespace/cmd/booking/internal/service"
+	"templespace/cmd/booking/internal/storage"
+)
+
+// tokens accepts the user ID as access token.
+type tokens struct{}
+
+func (tokens) Verify(ctx context.Context, token string) (service.Principal, error) {
+	if token == "" {
+		return service.Principal{}, errors.New("missing token")
+	}
+	return service.Principal{UserID: token}, nil
+}
+
+type discard struct{}
+
+func (discard) Publish(topic, key string, payload []byte) error { return nil }
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	svc := service.New(storage.NewMemoryRepo(), readmodel.NewMemoryReadModel(), discard{}, tokens{}, nil)
+	srv := httptest.NewServer(NewHTTPServer(&config.Config{}, svc).Handler())
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func do(t *testing.T, srv *httptest.Server, method, path, token, ifMatch, body string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if ifMatch != "" {
+		req.Header.Set("If-Match", ifMatch)
+	}
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+// createBooking books a slot for alice and returns the booking ID.
+func createBooking(t *testing.T, srv *httptest.Server) string {
+	t.Helper()
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Hour).UTC()
+	body := `{"space_id": "space-1", "slot_start": "` + start.Format(time.RFC3339) + `", "slot_end": "` + start.Add(time.Hour).Format(time.RFC3339) + `"}`
+	resp := do(t, srv, http.MethodPost, "/booking", "alice", "", body)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create: status %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("ETag"); got != `"1"` {
+		t.Fatalf("create: ETag %s, want \"1\"", got)
+	}
+	var b bookingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&b); err != nil {
+		t.Fatal(err)
+	}
+	return b.ID
+}
+
+func TestIfMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		ifMatch string
+		// confirm moves the booking to version 2 before the cancel is sent
+		confirm    bool
+		wantStatus int
+		wantETag   string
+	}{
+		{name: "missing is unconditional, never 428", ifMatch: "", wantStatus: http.StatusOK, wantETag: `"2"`},
+		{name: "wildcard is unconditional", ifMatch: "*", wantStatus: http.StatusOK, wantETag: `"2"`},
+		{name: "matching", ifMatch: `"1"`, wantStatus: http.StatusOK, wantETag: `"2"`},
+		{name: "weak matching", ifMatch: `W/"1"`, wantStatus: http.StatusOK, wantETag: `"2"`},
+		{name: "matching after a change", ifMatch: `"2"`, confirm: true, wantStatus: http.StatusOK, wantETag: `"3"`},
+		{name: "stale", ifMatch: `"1"`, confirm: true, wantStatus: http.StatusPreconditionFailed},
+		{name: "ahead", ifMatch: `"7"`, wantStatus: http.StatusPreconditionFailed},
+		{name: "malformed", ifMatch: `"abc"`, wantStatus: http.StatusPreconditionFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newTestServer(t)
+			id := createBooking(t, srv)
+			if tt.confirm {
+				// the guest may confirm the payment of a free booking
+				if resp := do(t, srv, http.MethodPost, "/booking/"+id+"/pay", "alice", "", ""); resp.StatusCode != http.StatusOK {
+					t.Fatalf("pay: status %d", resp.StatusCode)
+				}
+			}
+			resp := do(t, srv, http.MethodPost, "/booking/"+id+"/cancel", "alice", tt.ifMatch, "")
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("cancel: status %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if got := resp.Header.Get("ETag"); got != tt.wantETag {
+				t.Errorf("cancel: ETag %q, want %q", got, tt.wantETag)
+			}
+		})
+	}
+}
+
+func TestStalePreconditionLeavesBookingUnchanged(t *testing.T) {
+	srv := newTestServer(t)
+	id := createBooking(t, srv)
+	if resp := do(t, srv, http.MethodPost, "/booking/"+id+"/pay", "alice", `"1"`, ""); resp.StatusCode != http.StatusOK {
+		t.Fatalf("pay: status %d", resp.StatusCode)
+	}
+	if resp := do(t, srv, http.MethodPost, "/booking/"+id+"/cancel", "alice", `"1"`, ""); resp.StatusCode != http.StatusPreconditionFailed {
+		t.Fatalf("stale cancel: status %d", resp.StatusCode)
+	}
+	resp := do(t, srv, http.MethodGet, "/booking/"+id+"/history", "alice", "", "")
+	var h historyResponse
+	if err := json.NewDecoder(resp.Body).Decode(&h); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(h.Events); n != 2 || h.Events[n-1].Type != "paid" {
+		t.Errorf("history after a stale cancel: %+v", h.Events)
+	}
+}
+
+func TestPaymentWebhookNeedsSecret(t *testing.T) {
+	body := `{"id": "evt_1", "type": "payment_intent.succeeded", "data": {"intent_id": "pi_1"}}`
+	for _, tt := range []struct {
+		name       string
+		secret     string
+		wantStatus int
+	}{
+		{"unset", "", http.StatusNotFound},
+		{"set", "s3cret", http.StatusBadRequest}, // the delivery is signed with the empty secret
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := service.New(storage.NewMemoryRepo(), readmodel.NewMemoryReadModel(), discard{}, tokens{}, nil)
+			srv := httptest.NewServer(NewHTTPServer(&config.Config{PaymentWebhookSecret: tt.secret}, svc).Handler())
+			defer srv.Close()
+			req, _ := http.NewRequest(http.MethodPost, srv.URL+"/booking/payments/webhook", strings.NewReader(body))
+			req.Header.Set(payment.SignatureHeader, payment.Sign("", []byte(body), time.Now()))
+			resp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package server
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"templespace/cmd/booking/internal/domain"
+)
+
+// maxIdempotentBody bounds request bodies buffered for fingerprinting.
+const maxIdempotentBody = 1 << 20
+
+// replayedHeaders are the response headers stored with an idempotent response.
+var replayedHeaders = []string{"Content-Type", "ETag"}
+
+// idempotent lets POSTs carrying an Idempotency-Key run once: retries with the same key and body get
+// the first response again, marked with Idempotent-Replayed, while other requests pass straight to next.
+func (s *HTTPServer) idempotent(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("Idempotency-Key")
+		// provider webhooks are deduplicated by their event ID instead
+		if key == "" || r.Method != http.MethodPost || r.URL.Path == "/booking/payments/webhook" {
+			next(w, r)
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentBody))
+		if err != nil {
+			http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		scope := r.Method + " " + r.URL.RequestURI() + " " + r.Header.Get("If-Match")
+		resp, replayed, err := s.svc.Idempotent(r.Context(), bearerToken(r), key, scope, body, func() *domain.IdempotentResponse {
+			rec := &recorder{header: http.Header{}, status: http.StatusOK}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			next(rec, r)
+			return rec.response()
+		})
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		for k, v := range resp.Header {
+			w.Header().Set(k, v)
+		}
+		if replayed {
+			w.Header().Set("Idempotent-Replayed", "true")
+		}
+		w.WriteHeader(resp.Status)
+		_, _ = w.Write(resp.Body)
+	}
+}
+
+// recorder captures a handler's response so it can be stored before it is sent.
+type recorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *recorder) Header() http.Header { return r.header }
+
+func (r *recorder) Write(b []byte) (int, error) { return r.body.Write(b) }
+
+func (r *recorder) WriteHeader(status int) { r.status = status }
+
+func (r *recorder) response() *domain.IdempotentResponse {
+	resp := &domain.IdempotentResponse{
+		Status:    r.status,
+		Header:    map[string]string{},
+		Body:      r.body.Bytes(),
+		Retryable: r.status >= http.StatusInternalServerError,
+	}
+	for _, k := range replayedHeaders {
+		if v := r.header.Get(k); v != "" {
+			resp.Header[k] = v
+		}
+	}
+	return resp
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"templespace/cmd/booking/internal/domain"
+	"templespace/cmd/booking/internal/payment"
+)
+
+// maxWebhookBody bounds provider deliveries read before their signature is checked.
+const maxWebhookBody = 64 << 10
+
+type paymentResponse struct {
+	IntentID string `json:"intent_id"`
+	// ClientSecret lets the guest pay the intent with the provider directly
+	ClientSecret string        `json:"client_secret"`
+	Amount       moneyResponse `json:"amount"`
+	Status       string        `json:"status"`
+}
+
+func toPaymentResponse(pi *domain.PaymentIntent) *paymentResponse {
+	if pi == nil {
+		return nil
+	}
+	return &paymentResponse{
+		IntentID:     pi.ID,
+		ClientSecret: pi.ClientSecret,
+		Amount:       toMoney(pi.Amount),
+		Status:       string(pi.Status),
+	}
+}
+
+// handleStartPayment serves POST /booking/{id}/payment, returning the intent the guest pays.
+func (s *HTTPServer) handleStartPayment(w http.ResponseWriter, r *http.Request) {
+	pi, err := s.svc.StartPayment(r.Context(), bearerToken(r), bookingID(r.URL.Path, "payment"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toPaymentResponse(pi))
+}
+
+// handlePaymentWebhook serves POST /booking/payments/webhook. Any 2xx acknowledges the delivery;
+// other statuses make the provider redeliver it.
+func (s *HTTPServer) handlePaymentWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBody))
+	if err != nil {
+		http.Error(w, "unreadable body", http.StatusBadRequest)
+		return
+	}
+	ev, err := payment.ParseWebhook(s.cfg.PaymentWebhookSecret, body, r.Header.Get(payment.SignatureHeader), time.Now())
+	if err != nil {
+		if errors.Is(err, payment.ErrInvalidSignature) {
+			log.Printf("payment webhook rejected: %v", err)
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := s.svc.ApplyPaymentEvent(r.Context(), ev); err != nil {
+		log.Printf("payment webhook %s: %v", ev.ID, err)
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
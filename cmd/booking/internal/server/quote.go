@@ -0,0 +1,74 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"templespace/cmd/booking/internal/domain"
+)
+
+// moneyResponse carries both the exact minor-unit amount and its decimal rendering.
+type moneyResponse struct {
+	AmountMinor int64  `json:"amount_minor"`
+	Amount      string `json:"amount"`
+	Currency    string `json:"currency"`
+}
+
+func toMoney(m domain.Money) moneyResponse {
+	return moneyResponse{AmountMinor: m.Amount, Amount: m.Decimal(), Currency: m.Currency}
+}
+
+type quoteLineResponse struct {
+	Description string        `json:"description"`
+	Amount      moneyResponse `json:"amount"`
+}
+
+type quoteResponse struct {
+	SpaceID   string              `json:"space_id"`
+	SlotStart time.Time           `json:"slot_start"`
+	SlotEnd   time.Time           `json:"slot_end"`
+	Seats     int                 `json:"seats"`
+	Lines     []quoteLineResponse `json:"lines"`
+	Total     moneyResponse       `json:"total"`
+}
+
+// handleQuote serves POST /booking/quote with the body of a booking request.
+func (s *HTTPServer) handleQuote(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var req createBookingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	start, err := time.Parse(time.RFC3339, req.SlotStart)
+	if err != nil {
+		http.Error(w, "invalid slot_start", http.StatusBadRequest)
+		return
+	}
+	end, err := time.Parse(time.RFC3339, req.SlotEnd)
+	if err != nil {
+		http.Error(w, "invalid slot_end", http.StatusBadRequest)
+		return
+	}
+	q, err := s.svc.Quote(r.Context(), bearerToken(r), req.SpaceID, start, end, req.Seats, req.VoucherCode)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	out := quoteResponse{
+		SpaceID:   q.SpaceID,
+		SlotStart: q.SlotStart,
+		SlotEnd:   q.SlotEnd,
+		Seats:     q.Seats,
+		Lines:     make([]quoteLineResponse, 0, len(q.Lines)),
+		Total:     toMoney(q.Total),
+	}
+	for _, l := range q.Lines {
+		out.Lines = append(out.Lines, quoteLineResponse{Description: l.Description, Amount: toMoney(l.Amount)})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
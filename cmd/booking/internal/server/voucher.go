@@ -0,0 +1,165 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"templespace/cmd/booking/internal/domain"
+)
+
+type voucherRequest struct {
+	Code    string  `json:"code"`
+	Kind    string  `json:"kind"`
+	Percent float64 `json:"percent"`
+	// AmountMinor and Currency give the discount of a fixed voucher
+	AmountMinor    int64    `json:"amount_minor"`
+	Currency       string   `json:"currency"`
+	ValidFrom      string   `json:"valid_from"`
+	ValidUntil     string   `json:"valid_until"`
+	MaxRedemptions int      `json:"max_redemptions"`
+	MaxPerUser     int      `json:"max_per_user"`
+	SpaceIDs       []string `json:"space_ids"`
+	Tags           []string `json:"tags"`
+}
+
+type voucherResponse struct {
+	Code           string         `json:"code"`
+	Kind           string         `json:"kind"`
+	Percent        float64        `json:"percent,omitempty"`
+	Amount         *moneyResponse `json:"amount,omitempty"`
+	ValidFrom      *time.Time     `json:"valid_from,omitempty"`
+	ValidUntil     *time.Time     `json:"valid_until,omitempty"`
+	MaxRedemptions int            `json:"max_redemptions"`
+	MaxPerUser     int            `json:"max_per_user"`
+	SpaceIDs       []string       `json:"space_ids,omitempty"`
+	Tags           []string       `json:"tags,omitempty"`
+	Redeemed       int            `json:"redeemed"`
+	CreatedAt      time.Time      `json:"created_at"`
+}
+
+func toVoucherResponse(v *domain.Voucher, redeemed int) voucherResponse {
+	out := voucherResponse{
+		Code:           v.Code,
+		Kind:           string(v.Kind),
+		Percent:        v.Percent,
+		MaxRedemptions: v.MaxRedemptions,
+		MaxPerUser:     v.MaxPerUser,
+		SpaceIDs:       v.SpaceIDs,
+		Tags:           v.Tags,
+		Redeemed:       redeemed,
+		CreatedAt:      v.CreatedAt,
+	}
+	if v.Kind == domain.VoucherFixed {
+		amount := toMoney(v.Amount)
+		out.Amount = &amount
+	}
+	if !v.ValidFrom.IsZero() {
+		out.ValidFrom = &v.ValidFrom
+	}
+	if !v.ValidUntil.IsZero() {
+		out.ValidUntil = &v.ValidUntil
+	}
+	return out
+}
+
+// handleVouchers serves POST /vouchers (create a code) and GET /vouchers (list codes with usage).
+func (s *HTTPServer) handleVouchers(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		list, err := s.svc.ListVouchers(r.Context(), bearerToken(r))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		out := make([]voucherResponse, 0, len(list))
+		for _, u := range list {
+			out = append(out, toVoucherResponse(u.Voucher, u.Redeemed))
+		}
+		writeJSON(w, http.StatusOK, out)
+	case http.MethodPost:
+		var req voucherRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		v := &domain.Voucher{
+			Code:           req.Code,
+			Kind:           domain.VoucherKind(req.Kind),
+			Percent:        req.Percent,
+			Amount:         domain.Money{Amount: req.AmountMinor, Currency: req.Currency},
+			MaxRedemptions: req.MaxRedemptions,
+			MaxPerUser:     req.MaxPerUser,
+			SpaceIDs:       req.SpaceIDs,
+			Tags:           req.Tags,
+		}
+		var err error
+		if req.ValidFrom != "" {
+			if v.ValidFrom, err = time.Parse(time.RFC3339, req.ValidFrom); err != nil {
+				http.Error(w, "invalid valid_from", http.StatusBadRequest)
+				return
+			}
+		}
+		if req.ValidUntil != "" {
+			if v.ValidUntil, err = time.Parse(time.RFC3339, req.ValidUntil); err != nil {
+				http.Error(w, "invalid valid_until", http.StatusBadRequest)
+				return
+			}
+		}
+		v, err = s.svc.CreateVoucher(r.Context(), bearerToken(r), v)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, toVoucherResponse(v, 0))
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+type redemptionResponse struct {
+	BookingID  string        `json:"booking_id"`
+	UserID     string        `json:"user_id"`
+	Discount   moneyResponse `json:"discount"`
+	RedeemedAt time.Time     `json:"redeemed_at"`
+	ReleasedAt *time.Time    `json:"released_at,omitempty"`
+}
+
+type redemptionReport struct {
+	Code        string               `json:"code"`
+	Active      int                  `json:"active"`
+	Released    int                  `json:"released"`
+	Discounted  moneyResponse        `json:"discounted"`
+	Redemptions []redemptionResponse `json:"redemptions"`
+}
+
+// handleRedemptions serves GET /vouchers/{code}/redemptions. Discounted sums the active redemptions.
+func (s *HTTPServer) handleRedemptions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet || !hasSuffix(r.URL.Path, "/redemptions") {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	code := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/vouchers/"), "/redemptions")
+	list, err := s.svc.ListRedemptions(r.Context(), bearerToken(r), code)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	out := redemptionReport{Code: domain.NormalizeVoucherCode(code), Redemptions: make([]redemptionResponse, 0, len(list))}
+	var discounted domain.Money
+	for _, red := range list {
+		rr := redemptionResponse{BookingID: red.BookingID, UserID: red.UserID, Discount: toMoney(red.Discount), RedeemedAt: red.RedeemedAt}
+		if red.Active() {
+			out.Active++
+			discounted = discounted.Add(red.Discount)
+		} else {
+			out.Released++
+			released := red.ReleasedAt
+			rr.ReleasedAt = &released
+		}
+		out.Redemptions = append(out.Redemptions, rr)
+	}
+	out.Discounted = toMoney(discounted)
+	writeJSON(w, http.StatusOK, out)
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"templespace/cmd/booking/internal/domain"
+)
+
+// SpaceOwners resolves the owner of a space so owners can manage bookings made for it.
+type SpaceOwners interface {
+	OwnerOf(ctx context.Context, spaceID string) (ownerID string, err error)
+}
+
+// bookerFor resolves who a new booking is for: the caller by default, another user only for booking admins.
+func bookerFor(p Principal, userID string) (string, error) {
+	if userID == "" {
+		return p.UserID, nil
+	}
+	if userID != p.UserID && !p.HasScope(ScopeBookingAdmin) {
+		return "", fmt.Errorf("%w: cannot book on behalf of another user", domain.ErrForbidden)
+	}
+	return userID, nil
+}
+
+// authorize allows p to act on b when p booked it, holds ScopeBookingAdmin or owns the booked space.
+func (s *Service) authorize(ctx context.Context, p Principal, b *domain.Booking) error {
+	if p.UserID != "" && p.UserID == b.UserID {
+		return nil
+	}
+	return s.authorizeManager(ctx, p, b)
+}
+
+// authorizeManager allows only the owner of the booked space and holders of ScopeBookingAdmin.
+func (s *Service) authorizeManager(ctx context.Context, p Principal, b *domain.Booking) error {
+	if p.HasScope(ScopeBookingAdmin) {
+		return nil
+	}
+	if s.spaces != nil {
+		owner, err := s.spaces.OwnerOf(ctx, b.SpaceID)
+		if err != nil {
+			return err
+		}
+		if owner != "" && owner == p.UserID {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: not allowed to act on booking %s", domain.ErrForbidden, b.ID)
+}
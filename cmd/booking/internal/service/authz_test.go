@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"templespace/cmd/booking/internal/domain"
+	"templespace/cmd/booking/internal/readmodel"
+	"templespace/cmd/booking/internal/storage"
+)
+
+// tokens accepts "<user>" or "admin" as access token; admin holds ScopeBookingAdmin.
+type tokens struct{}
+
+func (tokens) Verify(ctx context.Context, token string) (Principal, error) {
+	switch token {
+	case "":
+		return Principal{}, errors.New("missing token")
+	case "admin":
+		return Principal{UserID: "admin", Scopes: []string{ScopeBookingAdmin}}, nil
+	}
+	return Principal{UserID: token}, nil
+}
+
+type discard struct{}
+
+func (discard) Publish(topic, key string, payload []byte) error { return nil }
+
+type owners map[string]string
+
+func (o owners) OwnerOf(ctx context.Context, spaceID string) (string, error) { return o[spaceID], nil }
+
+func newTestService(opts ...Option) *Service {
+	return New(storage.NewMemoryRepo(), readmodel.NewMemoryReadModel(), discard{}, tokens{}, nil, opts...)
+}
+
+// book creates a booking of space-1 for alice two days from now.
+func book(t *testing.T, s *Service) *domain.Booking {
+	t.Helper()
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+	b, err := s.CreateBooking(context.Background(), "alice", "space-1", "", start, start.Add(time.Hour), 1, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestBookingAuthorization(t *testing.T) {
+	actions := map[string]func(s *Service, token, id string) error{
+		"pay": func(s *Service, token, id string) error {
+			_, err := s.ConfirmPayment(context.Background(), token, id, 0)
+			return err
+		},
+		"cancel": func(s *Service, token, id string) error {
+			_, err := s.CancelBooking(context.Background(), token, id, 0)
+			return err
+		},
+		"reschedule": func(s *Service, token, id string) error {
+			start := time.Now().Add(72 * time.Hour).Truncate(time.Hour)
+			_, err := s.RescheduleBooking(context.Background(), token, id, start, start.Add(time.Hour), 0)
+			return err
+		},
+		"history": func(s *Service, token, id string) error {
+			_, err := s.History(context.Background(), token, id)
+			return err
+		},
+	}
+	callers := []struct {
+		token   string
+		allowed bool
+	}{
+		{"alice", true},
+		{"admin", true},
+		{"olga", true}, // owns space-1
+		{"bob", false},
+	}
+	for name, act := range actions {
+		for _, c := range callers {
+			t.Run(name+" by "+c.token, func(t *testing.T) {
+				s := newTestService(WithSpaceOwners(owners{"space-1": "olga"}))
+				b := book(t, s)
+				err := act(s, c.token, b.ID)
+				if c.allowed && err != nil {
+					t.Fatalf("want allowed, got %v", err)
+				}
+				if !c.allowed && !errors.Is(err, domain.ErrForbidden) {
+					t.Fatalf("want ErrForbidden, got %v", err)
+				}
+			})
+		}
+	}
+}
+
+func TestManagerOnlyActions(t *testing.T) {
+	for _, c := range []struct {
+		token   string
+		allowed bool
+	}{{"alice", false}, {"bob", false}, {"olga", true}, {"admin", true}} {
+		t.Run(c.token, func(t *testing.T) {
+			s := newTestService(WithSpaceOwners(owners{"space-1": "olga"}))
+			b := book(t, s)
+			_, err := s.ConfirmBooking(context.Background(), c.token, b.ID, 0)
+			if c.allowed != (err == nil) || !c.allowed && !errors.Is(err, domain.ErrForbidden) {
+				t.Fatalf("confirm by %s: %v", c.token, err)
+			}
+		})
+	}
+}
+
+func TestBookOnBehalf(t *testing.T) {
+	s := newTestService()
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+	_, err := s.CreateBooking(context.Background(), "bob", "space-1", "alice", start, start.Add(time.Hour), 1, "")
+	if !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("bob booking for alice: %v", err)
+	}
+	b, err := s.CreateBooking(context.Background(), "admin", "space-1", "alice", start, start.Add(time.Hour), 1, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.UserID != "alice" {
+		t.Errorf("booked for %s, want alice", b.UserID)
+	}
+}
+
+func TestUnauthenticated(t *testing.T) {
+	s := newTestService()
+	b := book(t, s)
+	if _, err := s.CancelBooking(context.Background(), "", b.ID, 0); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Fatalf("cancel without token: %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"templespace/cmd/booking/internal/domain"
+)
+
+// maxAvailabilityWindow bounds a single free-slot query.
+const maxAvailabilityWindow = 93 * 24 * time.Hour
+
+// FreeSlots returns the intervals of [from, to) in which spaceID has seats left, with how many (one
+// for exclusive spaces), snapped to granularity when it is positive (else to the space's alignment).
+// Closed hours and blackouts are excluded and the space's policy is applied: room is left for
+// buffers, intervals shorter than the minimum duration and anything past the booking horizon are
+// dropped. Results are served from the read model when cached.
+func (s *Service) FreeSlots(ctx context.Context, spaceID string, from, to time.Time, granularity time.Duration) ([]domain.FreeSlot, error) {
+	if spaceID == "" {
+		return nil, domain.Invalid(errors.New("space_id required"))
+	}
+	if !from.Before(to) {
+		return nil, domain.Invalid(errors.New("from must be before to"))
+	}
+	if to.Sub(from) > maxAvailabilityWindow {
+		return nil, domain.Invalid(errors.New("availability window too large"))
+	}
+	if granularity < 0 {
+		return nil, domain.Invalid(errors.New("granularity must not be negative"))
+	}
+	from, to = from.UTC(), to.UTC()
+	if free, ok := s.readModel.FreeSlots(spaceID, from, to, granularity); ok {
+		return free, nil
+	}
+	policy, err := s.policyOf(ctx, spaceID)
+	if err != nil {
+		return nil, err
+	}
+	// a new booking's own buffers must fit next to existing blocked intervals, so widen those by them
+	bookings, err := s.repo.ListOverlapping(spaceID, from.Add(-policy.BufferAfter), to.Add(policy.BufferBefore))
+	if err != nil {
+		return nil, err
+	}
+	capacity := max(policy.Capacity, 1)
+	busy := make([]domain.Occupancy, 0, len(bookings))
+	for _, b := range bookings {
+		occ := b.Occupancy(policy.Capacity)
+		occ.Start, occ.End = occ.Start.Add(-policy.BufferAfter), occ.End.Add(policy.BufferBefore)
+		busy = append(busy, occ)
+	}
+	window := domain.Interval{Start: from, End: to}
+	if policy.Horizon > 0 {
+		if limit := s.now().Add(policy.Horizon); limit.Before(window.End) {
+			window.End = limit
+		}
+	}
+	sched, err := s.scheduleOf(ctx, spaceID, window.Start, window.End)
+	if err != nil {
+		return nil, err
+	}
+	for _, iv := range sched.Closed(window) {
+		busy = append(busy, domain.Occupancy{Interval: iv, Seats: capacity})
+	}
+	step := granularity
+	if step == 0 {
+		step = policy.Alignment
+	}
+	free := make([]domain.FreeSlot, 0)
+	if window.Start.Before(window.End) {
+		for _, fs := range domain.FreeSeats(window, busy, capacity, step, policy.Location) {
+			if fs.End.Sub(fs.Start) >= policy.MinDuration {
+				free = append(free, fs)
+			}
+		}
+	}
+	_ = s.readModel.CacheFreeSlots(spaceID, from, to, granularity, free)
+	return free, nil
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"templespace/cmd/booking/internal/domain"
+)
+
+func TestFreeSlotsCachedUntilWrite(t *testing.T) {
+	s := newTestService()
+	b := book(t, s)
+	from, to := b.SlotStart.Add(-2*time.Hour), b.SlotEnd.Add(2*time.Hour)
+	free, err := s.FreeSlots(context.Background(), "space-1", from, to, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a booking written past the service is not seen while the cached slots last
+	start := b.SlotEnd
+	other := &domain.Booking{ID: "direct", SpaceID: "space-1", UserID: "bob", SlotStart: start, SlotEnd: start.Add(time.Hour),
+		Status: domain.StatusConfirmed, Seats: 1, CreatedAt: time.Now()}
+	other.Track(nil, "bob")
+	if err := s.repo.CreateIfAvailable(other); err != nil {
+		t.Fatal(err)
+	}
+	cached, err := s.FreeSlots(context.Background(), "space-1", from, to, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !freeAt(cached, other.SlotStart) || len(cached) != len(free) {
+		t.Fatalf("free slots %v, want the cached %v", cached, free)
+	}
+	// a write through the service invalidates the space
+	if _, err := s.CancelBooking(context.Background(), "alice", b.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	fresh, err := s.FreeSlots(context.Background(), "space-1", from, to, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if freeAt(fresh, other.SlotStart) || !freeAt(fresh, b.SlotStart) {
+		t.Fatalf("free slots %v after cancelling, want the direct booking taken and the cancelled slot free", fresh)
+	}
+}
+
+// freeAt reports whether one of free covers t.
+func freeAt(free []domain.FreeSlot, t time.Time) bool {
+	for _, f := range free {
+		if !t.Before(f.Start) && t.Before(f.End) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"templespace/cmd/booking/internal/domain"
+)
+
+type flatRate struct{}
+
+func (flatRate) PricingOf(ctx context.Context, spaceID string) (domain.Pricing, error) {
+	return domain.Pricing{HourlyRate: domain.Money{Amount: 2000, Currency: "EUR"}}, nil
+}
+
+func TestCancelFollowingRefundsPaidOccurrences(t *testing.T) {
+	s := newTestService(WithSpacePricing(flatRate{}))
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+	series, err := s.CreateRecurringBooking(context.Background(), "alice", RecurringRequest{
+		SpaceID: "space-1", SlotStart: start, SlotEnd: start.Add(time.Hour), RRule: "FREQ=DAILY;COUNT=3",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	paid := series.Bookings[1]
+	if _, err := s.ConfirmPayment(context.Background(), "admin", paid.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	cancelled, err := s.CancelFollowing(context.Background(), "admin", series.Bookings[0].ID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cancelled) != 3 {
+		t.Fatalf("%d occurrences cancelled, want 3", len(cancelled))
+	}
+	for _, b := range cancelled {
+		want := domain.Money{Currency: "EUR"}
+		if b.ID == paid.ID {
+			want = b.Total // cancelled by an admin: refunded in full
+		}
+		if b.Status != domain.StatusCancelled || b.Refunded.Amount != want.Amount {
+			t.Errorf("occurrence %s: %s, refunded %s, want %s", b.ID, b.Status, b.Refunded, want)
+		}
+	}
+}
+
+func TestCancelRefundsPaymentAfterLoad(t *testing.T) {
+	s := newTestService(WithSpacePricing(flatRate{}))
+	b := book(t, s)
+	stale, err := s.repo.GetByID(b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the payment lands between the cancellation loading the booking and writing it
+	if _, err := s.ConfirmPayment(context.Background(), "admin", b.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	p := Principal{UserID: "admin", Scopes: []string{ScopeBookingAdmin}}
+
+	pinned := *stale
+	if _, err := s.cancel(context.Background(), p, &pinned, stale.Version); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("cancel pinned to the unpaid version: %v", err)
+	}
+	out, err := s.cancel(context.Background(), p, stale, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Status != domain.StatusCancelled || out.Refunded != out.Total || out.Total.Amount == 0 {
+		t.Fatalf("got %s refunded %s of %s, want a full refund", out.Status, out.Refunded, out.Total)
+	}
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"templespace/cmd/booking/internal/domain"
+)
+
+// expireBatch bounds how many holds one sweep loads at a time.
+const expireBatch = 100
+
+var errHoldActive = errors.New("hold still active")
+
+// actorExpirer is the actor of expiries in booking histories.
+const actorExpirer = "system:expirer"
+
+// ExpireHolds moves every pending booking whose hold has lapsed to expired, freeing its slot
+// and publishing booking_expired; holds with a payment underway are extended instead. It returns how many bookings were expired.
+func (s *Service) ExpireHolds(ctx context.Context) (int, error) {
+	expired := 0
+	for {
+		now := s.now()
+		batch, err := s.repo.ListExpiredHolds(now, expireBatch)
+		if err != nil {
+			return expired, err
+		}
+		progressed := false
+		for _, b := range batch {
+			if ctx.Err() != nil {
+				return expired, ctx.Err()
+			}
+			if err := s.expireHold(b, now); err != nil {
+				// paid, cancelled or extended concurrently: nothing to expire
+				if !errors.Is(err, errHoldActive) && !errors.Is(err, domain.ErrInvalidTransition) {
+					log.Printf("expire booking %s: %v", b.ID, err)
+				}
+				continue
+			}
+			expired++
+			progressed = true
+		}
+		if len(batch) < expireBatch || !progressed {
+			return expired, nil
+		}
+	}
+}
+
+// expireHold expires b unless its hold is still active. A hold with a payment underway at the
+// provider is extended to the end of the payment's own hold instead, so a guest who is paying does
+// not lose the slot to the expirer and get a late-payment refund.
+func (s *Service) expireHold(b *domain.Booking, now time.Time) error {
+	paying, err := s.paymentHold(b)
+	if err != nil {
+		return err
+	}
+	extended := false
+	b, err = s.updateWithRetry(b, actorExpirer, 0, func(b *domain.Booking) error {
+		// re-checked on every retry against the freshly loaded booking
+		if b.Status != domain.StatusPending || b.HoldExpiresAt.IsZero() || b.HoldExpiresAt.After(now) {
+			return errHoldActive
+		}
+		if paying.After(now) {
+			b.HoldExpiresAt, extended = paying, true
+			return nil
+		}
+		return b.Transition(domain.EventExpire)
+	})
+	if err != nil {
+		return err
+	}
+	if extended {
+		return errHoldActive
+	}
+	s.afterTransition(b)
+	return nil
+}
+
+// paymentHold returns when the hold of b's open payment intent lapses: one hold TTL after the guest
+// last started paying it. It is zero when b has no intent awaiting payment of its current total.
+func (s *Service) paymentHold(b *domain.Booking) (time.Time, error) {
+	if s.intents == nil {
+		return time.Time{}, nil
+	}
+	pi, err := s.intents.LatestIntent(b.ID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	if pi.Status != domain.PaymentRequiresPayment || pi.Amount != b.Total {
+		return time.Time{}, nil
+	}
+	started := pi.CreatedAt
+	if pi.UpdatedAt.After(started) {
+		started = pi.UpdatedAt
+	}
+	if started.IsZero() {
+		return time.Time{}, nil
+	}
+	return started.Add(s.holdTTL), nil
+}
+
+// Expirer runs ExpireHolds, when holds expire at all, and PurgeIdempotencyKeys periodically until its
+// context is cancelled.
+type Expirer struct {
+	svc      *Service
+	interval time.Duration
+}
+
+func NewExpirer(svc *Service, interval time.Duration) *Expirer {
+	return &Expirer{svc: svc, interval: interval}
+}
+
+func (e *Expirer) Run(ctx context.Context) {
+	t := time.NewTicker(e.interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if e.svc.holdTTL > 0 {
+				if n, err := e.svc.ExpireHolds(ctx); err != nil {
+					log.Printf("hold expirer: %v", err)
+				} else if n > 0 {
+					log.Printf("hold expirer: expired %d bookings", n)
+				}
+			}
+			if _, err := e.svc.PurgeIdempotencyKeys(); err != nil {
+				log.Printf("hold expirer: purge idempotency keys: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"templespace/cmd/booking/internal/domain"
+	"templespace/cmd/booking/internal/readmodel"
+	"templespace/cmd/booking/internal/storage"
+)
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+func TestExpirer(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2030, 3, 4, 9, 0, 0, 0, time.UTC)}
+	repo := storage.NewMemoryRepo()
+	s := New(repo, readmodel.NewMemoryReadModel(), discard{}, tokens{}, nil, WithClock(clock), WithHoldTTL(15*time.Minute))
+	start := clock.Now().Add(48 * time.Hour)
+	held, err := s.CreateBooking(context.Background(), "alice", "space-1", "", start, start.Add(time.Hour), 1, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	paid, err := s.CreateBooking(context.Background(), "alice", "space-1", "", start.Add(time.Hour), start.Add(2*time.Hour), 1, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ConfirmPayment(context.Background(), "alice", paid.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	status := func(id string) domain.BookingStatus {
+		t.Helper()
+		b, err := repo.GetByID(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b.Status
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		NewExpirer(s, time.Millisecond).Run(ctx)
+	}()
+
+	clock.Advance(14 * time.Minute)
+	time.Sleep(20 * time.Millisecond) // many sweeps
+	if got := status(held.ID); got != domain.StatusPending {
+		t.Fatalf("booking %s before its hold lapsed", got)
+	}
+
+	clock.Advance(2 * time.Minute)
+	deadline := time.Now().Add(5 * time.Second)
+	for status(held.ID) != domain.StatusExpired {
+		if time.Now().After(deadline) {
+			t.Fatal("hold not expired by the expirer")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if got := status(paid.ID); got != domain.StatusPaid {
+		t.Errorf("paid booking is %s after its hold lapsed", got)
+	}
+	events, err := s.History(context.Background(), "alice", held.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := events[len(events)-1]; last.Actor != actorExpirer || !last.At.Equal(clock.Now()) {
+		t.Errorf("expiry recorded as %+v", last)
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expirer still running after its context was cancelled")
+	}
+}
+
+func TestExpireHoldsExtendsHoldWhilePaying(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2030, 3, 4, 9, 0, 0, 0, time.UTC)}
+	s := New(storage.NewMemoryRepo(), readmodel.NewMemoryReadModel(), discard{}, tokens{}, &gateway{clock: clock},
+		WithClock(clock), WithHoldTTL(15*time.Minute), WithPaymentIntents(storage.NewMemoryPayments()), WithSpacePricing(flatRate{}))
+	start := clock.Now().Add(48 * time.Hour)
+	b, err := s.CreateBooking(context.Background(), "alice", "space-1", "", start, start.Add(time.Hour), 1, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the guest starts paying ten minutes into the hold
+	clock.Advance(10 * time.Minute)
+	if _, err := s.StartPayment(context.Background(), "alice", b.ID); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(6 * time.Minute)
+	if n, err := s.ExpireHolds(context.Background()); err != nil || n != 0 {
+		t.Fatalf("expired %d holds, %v; want the paying one kept", n, err)
+	}
+	got, err := s.repo.GetByID(b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := clock.Now().Add(9 * time.Minute); got.Status != domain.StatusPending || !got.HoldExpiresAt.Equal(want) {
+		t.Fatalf("booking %s held until %s, want pending until %s", got.Status, got.HoldExpiresAt, want)
+	}
+	// the payment's own hold lapses too
+	clock.Advance(10 * time.Minute)
+	if n, err := s.ExpireHolds(context.Background()); err != nil || n != 1 {
+		t.Fatalf("expired %d holds, %v; want 1", n, err)
+	}
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"templespace/cmd/booking/internal/domain"
+)
+
+// History returns every change made to a booking, oldest first, with who made it and when. Like the
+// booking itself it is visible to the guest, the space owner and booking admins.
+func (s *Service) History(ctx context.Context, accessToken, bookingID string) ([]domain.HistoryEvent, error) {
+	p, err := s.verify(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.repo.GetByID(bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, p, b); err != nil {
+		return nil, err
+	}
+	return s.repo.History(bookingID)
+}
+
+// RebuildReadModel derives the booking rows that availability, free slots and listings are read from
+// again from the event log, then drops the cached free slots so they are computed from the rebuilt
+// rows. It returns how many bookings were replayed and is reserved for booking admins.
+func (s *Service) RebuildReadModel(ctx context.Context, accessToken string) (int, error) {
+	p, err := s.verify(ctx, accessToken)
+	if err != nil {
+		return 0, err
+	}
+	if !p.HasScope(ScopeBookingAdmin) {
+		return 0, fmt.Errorf("%w: rebuilding the read model needs %s", domain.ErrForbidden, ScopeBookingAdmin)
+	}
+	replayed := 0
+	err = s.repo.ReplayAll(func(b *domain.Booking) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		replayed++
+		return s.repo.Reproject(b)
+	})
+	// dropped even after a failure, so rows rebuilt so far are not hidden behind stale free slots
+	if rerr := s.readModel.Reset(); err == nil {
+		err = rerr
+	}
+	return replayed, err
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"templespace/cmd/booking/internal/domain"
+	"templespace/cmd/booking/internal/readmodel"
+	"templespace/cmd/booking/internal/storage"
+)
+
+func TestRebuildReadModel(t *testing.T) {
+	rm := readmodel.NewMemoryReadModel()
+	s := New(storage.NewMemoryRepo(), rm, discard{}, tokens{}, nil)
+	b := book(t, s)
+	from, to := b.SlotStart.Add(-time.Hour), b.SlotEnd.Add(time.Hour)
+	if _, err := s.FreeSlots(context.Background(), "space-1", from, to, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := rm.FreeSlots("space-1", from.UTC(), to.UTC(), 0); !ok {
+		t.Fatal("free slots not cached")
+	}
+
+	if _, err := s.RebuildReadModel(context.Background(), "alice"); !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("rebuild by a guest: %v", err)
+	}
+	n, err := s.RebuildReadModel(context.Background(), "admin")
+	if err != nil || n != 1 {
+		t.Fatalf("replayed %d bookings, %v", n, err)
+	}
+	if _, ok := rm.FreeSlots("space-1", from.UTC(), to.UTC(), 0); ok {
+		t.Fatal("free slots still cached after the rebuild")
+	}
+	free, err := s.FreeSlots(context.Background(), "space-1", from, to, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range free {
+		if f.Start.Before(b.SlotEnd) && b.SlotStart.Before(f.End) {
+			t.Fatalf("free slot %v over the rebuilt booking", f)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"time"
+
+	"templespace/cmd/booking/internal/domain"
+)
+
+// DefaultIdempotencyTTL is how long responses stay replayable when WithIdempotency gets no TTL.
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+const (
+	maxIdempotencyKey = 255
+	// idempotencyWait bounds how long a retry waits for the first request with its key to finish.
+	idempotencyWait = 30 * time.Second
+	idempotencyPoll = 50 * time.Millisecond
+	// idempotencyLease is how long a claim without a response holds its key: longer than any request
+	// should run, and short enough that a retry waiting on a request that died takes the key over.
+	idempotencyLease = 20 * time.Second
+)
+
+// WithIdempotency makes requests carrying an idempotency key run once per key and caller: retries of
+// the same request within ttl get the stored response. Without it keys are ignored.
+func WithIdempotency(store domain.IdempotencyStore, ttl time.Duration) Option {
+	return func(s *Service) {
+		if ttl <= 0 {
+			ttl = DefaultIdempotencyTTL
+		}
+		s.idem, s.idemTTL = store, ttl
+	}
+}
+
+// Idempotent runs do for the first request with key and stores its response; later requests with the
+// key replay it and report replayed. The request is identified by scope (the endpoint) and its body:
+// reusing the key for another request fails with domain.ErrIdempotencyMismatch. A retry arriving while
+// the first request runs waits for its response. Keys belong to the caller behind accessToken, and
+// Retryable responses are not stored so the request can be retried.
+func (s *Service) Idempotent(ctx context.Context, accessToken, key, scope string, request []byte,
+	do func() *domain.IdempotentResponse) (resp *domain.IdempotentResponse, replayed bool, err error) {
+	if key == "" || s.idem == nil {
+		return do(), false, nil
+	}
+	if len(key) > maxIdempotencyKey {
+		return nil, false, domain.Invalid(errors.New("idempotency key is longer than 255 characters"))
+	}
+	p, err := s.verify(ctx, accessToken)
+	if err != nil {
+		return nil, false, err
+	}
+	sum := sha256.Sum256(append([]byte(scope+"\n"), request...))
+	rec := &domain.IdempotencyRecord{
+		Key:         p.UserID + "/" + key,
+		Token:       generateID(),
+		Fingerprint: hex.EncodeToString(sum[:]),
+	}
+	deadline := time.Now().Add(idempotencyWait)
+	for {
+		// stamped on every attempt, so a waiting retry takes over a claim whose lease ran out
+		rec.CreatedAt = s.now()
+		rec.ExpiresAt = rec.CreatedAt.Add(idempotencyLease)
+		cur, err := s.idem.Claim(rec)
+		if err != nil {
+			return nil, false, err
+		}
+		if cur == nil {
+			return s.runIdempotent(rec, do)
+		}
+		if cur.Fingerprint != rec.Fingerprint {
+			return nil, false, domain.ErrIdempotencyMismatch
+		}
+		if cur.Response != nil {
+			return cur.Response, true, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, false, domain.ErrIdempotencyInFlight
+		}
+		select {
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		case <-time.After(idempotencyPoll):
+		}
+	}
+}
+
+// runIdempotent runs do for a claimed key and stores its response for the idempotency TTL, or
+// releases the key when do panics or its response is Retryable.
+func (s *Service) runIdempotent(rec *domain.IdempotencyRecord, do func() *domain.IdempotentResponse) (*domain.IdempotentResponse, bool, error) {
+	stored := false
+	defer func() {
+		if !stored {
+			_ = s.idem.Release(rec.Key, rec.Token)
+		}
+	}()
+	resp := do()
+	if resp.Retryable {
+		return resp, false, nil
+	}
+	if err := s.idem.Complete(rec.Key, rec.Token, resp, rec.CreatedAt.Add(s.idemTTL)); err != nil {
+		// the request took effect; answer it and let a retry run again rather than wait on the key. A
+		// claim taken over by a retry is the retry's to complete.
+		log.Printf("store idempotent response %s: %v", rec.Key, err)
+		return resp, false, nil
+	}
+	stored = true
+	return resp, false, nil
+}
+
+// PurgeIdempotencyKeys deletes expired idempotency records.
+func (s *Service) PurgeIdempotencyKeys() (int, error) {
+	if s.idem == nil {
+		return 0, nil
+	}
+	return s.idem.Purge(s.now())
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"templespace/cmd/booking/internal/domain"
+	"templespace/cmd/booking/internal/storage"
+)
+
+func newIdempotentService() (*Service, *storage.MemoryIdempotency, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2030, 3, 4, 9, 0, 0, 0, time.UTC)}
+	store := storage.NewMemoryIdempotency()
+	return newTestService(WithClock(clock), WithIdempotency(store, time.Hour)), store, clock
+}
+
+// counting returns a do func answering resp and counting its runs.
+func counting(runs *int, resp domain.IdempotentResponse) func() *domain.IdempotentResponse {
+	return func() *domain.IdempotentResponse {
+		*runs++
+		r := resp
+		return &r
+	}
+}
+
+func TestIdempotentStoresForTTL(t *testing.T) {
+	s, _, clock := newIdempotentService()
+	runs := 0
+	do := counting(&runs, domain.IdempotentResponse{Status: 201, Body: []byte("ok")})
+	if _, replayed, err := s.Idempotent(context.Background(), "alice", "k", "create", nil, do); err != nil || replayed {
+		t.Fatalf("first request: replayed %v, %v", replayed, err)
+	}
+	// completed responses outlive the lease of the claim
+	clock.Advance(idempotencyLease + time.Minute)
+	resp, replayed, err := s.Idempotent(context.Background(), "alice", "k", "create", nil, do)
+	if err != nil || !replayed || string(resp.Body) != "ok" || runs != 1 {
+		t.Fatalf("retry: replayed %v, body %q, %d runs, %v", replayed, resp.Body, runs, err)
+	}
+	clock.Advance(time.Hour)
+	if n, err := s.PurgeIdempotencyKeys(); err != nil || n != 1 {
+		t.Fatalf("purged %d keys, %v", n, err)
+	}
+	if _, replayed, _ := s.Idempotent(context.Background(), "alice", "k", "create", nil, do); replayed || runs != 2 {
+		t.Fatalf("after purge: replayed %v, %d runs", replayed, runs)
+	}
+}
+
+func TestIdempotentSkipsRetryableResponses(t *testing.T) {
+	s, _, _ := newIdempotentService()
+	runs := 0
+	do := counting(&runs, domain.IdempotentResponse{Body: []byte(`{"error":"slot taken"}`), Retryable: true})
+	for i := 0; i < 2; i++ {
+		if _, replayed, err := s.Idempotent(context.Background(), "alice", "k", "create", nil, do); err != nil || replayed {
+			t.Fatalf("attempt %d: replayed %v, %v", i, replayed, err)
+		}
+	}
+	if runs != 2 {
+		t.Fatalf("%d runs, want 2", runs)
+	}
+}
+
+func TestIdempotentTakesOverExpiredLease(t *testing.T) {
+	s, store, clock := newIdempotentService()
+	// the outer request holds the key while a retry arrives, then stalls past its lease
+	if _, _, err := s.Idempotent(context.Background(), "alice", "k", "create", nil, func() *domain.IdempotentResponse {
+		cur, err := store.Claim(&domain.IdempotencyRecord{Key: "alice/k", CreatedAt: clock.Now()})
+		if err != nil || cur == nil || cur.Response != nil {
+			t.Fatalf("claim while running: %+v, %v", cur, err)
+		}
+		if cur.ExpiresAt.After(clock.Now().Add(idempotencyLease)) {
+			t.Fatalf("in-flight claim expires at %s, want a lease of %s", cur.ExpiresAt, idempotencyLease)
+		}
+		// a retry within the lease waits on the running request
+		ctx, cancel := context.WithTimeout(context.Background(), 3*idempotencyPoll)
+		defer cancel()
+		if _, _, err := s.Idempotent(ctx, "alice", "k", "create", nil, nil); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("retry within the lease: %v", err)
+		}
+		// once the lease runs out, a retry takes the key over
+		clock.Advance(idempotencyLease)
+		runs := 0
+		if _, replayed, err := s.Idempotent(context.Background(), "alice", "k", "create", nil,
+			counting(&runs, domain.IdempotentResponse{Status: 201, Body: []byte("retry")})); err != nil || replayed || runs != 1 {
+			t.Fatalf("retry after the lease: replayed %v, %d runs, %v", replayed, runs, err)
+		}
+		return &domain.IdempotentResponse{Status: 201, Body: []byte("stale")}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// the stalled request finishing last does not overwrite the retry's response
+	resp, replayed, err := s.Idempotent(context.Background(), "alice", "k", "create", nil, nil)
+	if err != nil || !replayed || string(resp.Body) != "retry" {
+		t.Fatalf("replayed %v %q, %v; want the retry's response", replayed, resp.Body, err)
+	}
+}
+
+func TestIdempotentStaleReleaseKeepsNewClaim(t *testing.T) {
+	s, store, clock := newIdempotentService()
+	if _, _, err := s.Idempotent(context.Background(), "alice", "k", "create", nil, func() *domain.IdempotentResponse {
+		// another request takes the key over once the lease ran out and is still running
+		clock.Advance(idempotencyLease)
+		if cur, err := store.Claim(&domain.IdempotencyRecord{Key: "alice/k", Token: "new", CreatedAt: clock.Now(),
+			ExpiresAt: clock.Now().Add(idempotencyLease)}); err != nil || cur != nil {
+			t.Fatalf("takeover: %+v, %v", cur, err)
+		}
+		// the stalled request fails and releases its claim
+		return &domain.IdempotentResponse{Retryable: true}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	cur, err := store.Claim(&domain.IdempotencyRecord{Key: "alice/k", Token: "third", CreatedAt: clock.Now()})
+	if err != nil || cur == nil || cur.Token != "new" {
+		t.Fatalf("key held by %+v, %v; want the new claim untouched", cur, err)
+	}
+	if err := store.Complete("alice/k", "stale", &domain.IdempotentResponse{Status: 201}, clock.Now().Add(time.Hour)); !errors.Is(err, domain.ErrIdempotencyClaimLost) {
+		t.Fatalf("completing with a stale token: %v", err)
+	}
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"templespace/cmd/booking/internal/domain"
+	"templespace/cmd/booking/internal/events"
+)
+
+// WithOutbox stores events that are not part of a booking write in o, so the relay publishes them
+// with the same retries as the rest. Without it they go straight to the EventPublisher.
+func WithOutbox(o domain.Outbox) Option {
+	return func(s *Service) { s.outbox = o }
+}
+
+// record adds event typ with data, one of the events payloads, to the events stored with the next
+// write of b.
+func (s *Service) record(b *domain.Booking, typ string, at time.Time, data any) error {
+	payload, err := events.Encode(typ, b.ID, at, data)
+	if err != nil {
+		return err
+	}
+	b.Record(typ, payload, at)
+	return nil
+}
+
+// publish emits an event that no booking write carries, e.g. a failed payment.
+func (s *Service) publish(ctx context.Context, typ, bookingID string, data any) error {
+	now := s.now()
+	payload, err := events.Encode(typ, bookingID, now, data)
+	if err != nil {
+		return err
+	}
+	if s.outbox == nil {
+		return s.events.Publish(typ, bookingID, payload)
+	}
+	return s.outbox.Append(ctx, domain.OutboxMessage{Topic: typ, Key: bookingID, Payload: payload, CreatedAt: now})
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"templespace/cmd/booking/internal/domain"
+	"templespace/cmd/booking/internal/events"
+)
+
+// WithPaymentIntents keeps the payment intents opened at the gateway so provider webhooks can be
+// matched to bookings. Without it bookings are only paid through ConfirmPayment.
+func WithPaymentIntents(r domain.PaymentRepository) Option {
+	return func(s *Service) { s.intents = r }
+}
+
+// StartPayment returns the open payment intent of a booking, opening a new one at the gateway when
+// there is none, the last one failed or the booking's total has changed since. The hold of the booking
+// lasts at least one hold TTL from the call.
+func (s *Service) StartPayment(ctx context.Context, accessToken, bookingID string) (*domain.PaymentIntent, error) {
+	p, err := s.verify(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.repo.GetByID(bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, p, b); err != nil {
+		return nil, err
+	}
+	if s.intents == nil {
+		return nil, fmt.Errorf("payments are %w", domain.ErrNotEnabled)
+	}
+	if !b.CanTransition(domain.EventPay) {
+		return nil, &domain.TransitionError{From: b.Status, Event: domain.EventPay}
+	}
+	if b.Total.Amount <= 0 {
+		return nil, &domain.PolicyError{Reason: "booking is free; confirm it without a payment"}
+	}
+	pi, err := s.startPayment(ctx, b)
+	if err != nil {
+		return nil, err
+	}
+	// the guest is paying now, so the expirer keeps the hold for another hold TTL
+	pi.UpdatedAt = s.now()
+	if err := s.intents.SaveIntent(pi); err != nil {
+		return nil, err
+	}
+	return pi, nil
+}
+
+// startPayment reuses the latest intent of b while it is still payable, else opens a new one. Free
+// bookings and services without an intent store get none.
+func (s *Service) startPayment(ctx context.Context, b *domain.Booking) (*domain.PaymentIntent, error) {
+	if s.intents == nil || b.Total.Amount <= 0 {
+		return nil, nil
+	}
+	pi, err := s.intents.LatestIntent(b.ID)
+	if err == nil && pi.Status != domain.PaymentFailed && pi.Amount == b.Total {
+		return pi, nil
+	}
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+	if pi, err = s.payment.CreateIntent(ctx, b.ID, b.Total); err != nil {
+		return nil, err
+	}
+	if err := s.intents.SaveIntent(pi); err != nil {
+		return nil, err
+	}
+	return pi, nil
+}
+
+// PaymentIntentOf returns the latest payment intent of a booking to a caller allowed to act on it,
+// or nil when it has none.
+func (s *Service) PaymentIntentOf(ctx context.Context, accessToken, bookingID string) (*domain.PaymentIntent, error) {
+	p, err := s.verify(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.repo.GetByID(bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, p, b); err != nil {
+		return nil, err
+	}
+	if s.intents == nil {
+		return nil, nil
+	}
+	pi, err := s.intents.LatestIntent(b.ID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, nil
+	}
+	return pi, err
+}
+
+var (
+	errAlreadyPaid = errors.New("booking already paid")
+	// errStaleIntent stops a payment of an intent opened for another total than the booking has now.
+	errStaleIntent = errors.New("payment intent is for an outdated total")
+)
+
+// actorPayments is the actor of changes reported by the payment provider in booking histories.
+const actorPayments = "system:payments"
+
+// ApplyPaymentEvent handles a verified provider webhook. A succeeded payment moves its booking to
+// paid, a failed one is recorded and published as booking_payment_failed. Redeliveries of an event
+// and events that no longer change anything are acknowledged without effect. The event is recorded
+// before it is handled, so concurrent deliveries apply it once, and forgotten again when handling
+// fails, so the provider's redelivery retries it.
+func (s *Service) ApplyPaymentEvent(ctx context.Context, ev domain.PaymentEvent) error {
+	if s.intents == nil {
+		return fmt.Errorf("payments are %w", domain.ErrNotEnabled)
+	}
+	if fresh, err := s.intents.RecordEvent(ev.ID, s.now()); err != nil || !fresh {
+		return err
+	}
+	if err := s.applyPaymentEvent(ctx, ev); err != nil {
+		if ferr := s.intents.ForgetEvent(ev.ID); ferr != nil {
+			log.Printf("forget payment event %s: %v", ev.ID, ferr)
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *Service) applyPaymentEvent(ctx context.Context, ev domain.PaymentEvent) error {
+	pi, err := s.intents.GetIntent(ev.IntentID)
+	if err != nil {
+		return err
+	}
+	switch ev.Type {
+	case domain.PaymentEventSucceeded:
+		if ev.Amount != pi.Amount {
+			return domain.Invalid(fmt.Errorf("payment %s: paid %s, intent is for %s", pi.ID, ev.Amount, pi.Amount))
+		}
+		pi.Status = domain.PaymentSucceeded
+		pi.UpdatedAt = s.now()
+		if err := s.intents.SaveIntent(pi); err != nil {
+			return err
+		}
+		if err := s.markPaid(ctx, pi); err != nil {
+			return err
+		}
+	case domain.PaymentEventFailed:
+		if pi.Status == domain.PaymentRequiresPayment {
+			data := events.PaymentFailedV1{BookingID: pi.BookingID, IntentID: pi.ID, Reason: ev.FailureReason}
+			// stored before the intent, so a redelivered webhook may repeat the event but never drops it
+			if err := s.publish(ctx, "booking_payment_failed", pi.BookingID, data); err != nil {
+				return err
+			}
+			pi.Status = domain.PaymentFailed
+			pi.UpdatedAt = s.now()
+			if err := s.intents.SaveIntent(pi); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// markPaid moves the booking of a succeeded intent to paid. A payment that cannot pay its booking,
+// because the booking was cancelled or expired while the guest paid or its total changed since the
+// intent was opened, is refunded in full.
+func (s *Service) markPaid(ctx context.Context, pi *domain.PaymentIntent) error {
+	b, err := s.repo.GetByID(pi.BookingID)
+	if err != nil {
+		return err
+	}
+	b, err = s.updateWithRetry(b, actorPayments, 0, func(b *domain.Booking) error {
+		if b.Status == domain.StatusPaid {
+			return errAlreadyPaid
+		}
+		if pi.Amount != b.Total {
+			return errStaleIntent
+		}
+		return b.Transition(domain.EventPay)
+	})
+	switch {
+	case errors.Is(err, errAlreadyPaid):
+		return nil
+	case errors.Is(err, domain.ErrInvalidTransition), errors.Is(err, errStaleIntent):
+		return s.refundUnapplied(ctx, pi, err)
+	case err != nil:
+		return err
+	}
+	s.afterTransition(b)
+	return nil
+}
+
+// refundUnapplied pays back a succeeded intent that could not pay its booking and publishes
+// booking_refunded, so the guest is told. The gateway refunds an intent once, so a redelivered
+// webhook does not pay back twice.
+func (s *Service) refundUnapplied(ctx context.Context, pi *domain.PaymentIntent, reason error) error {
+	refundID, err := s.payment.Refund(ctx, pi, pi.Amount)
+	if err != nil {
+		return err
+	}
+	log.Printf("payment %s refunded: it cannot pay booking %s: %v", pi.ID, pi.BookingID, reason)
+	return s.publish(ctx, "booking_refunded", pi.BookingID, events.BookingRefundedV1{
+		BookingID: pi.BookingID,
+		RefundID:  refundID,
+		Percent:   100,
+		Total:     events.Money(pi.Amount),
+		Refund:    events.Money(pi.Amount),
+	})
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"templespace/cmd/booking/internal/domain"
+	"templespace/cmd/booking/internal/readmodel"
+	"templespace/cmd/booking/internal/storage"
+)
+
+// gateway opens intents in memory at the time of clock, or now without one, and counts the refunds
+// asked of it; failRefunds fails that many refunds first.
+type gateway struct {
+	clock       Clock
+	mu          sync.Mutex
+	opened      int
+	refunds     map[string]int
+	failRefunds int
+}
+
+func (g *gateway) CreateIntent(ctx context.Context, bookingID string, amount domain.Money) (*domain.PaymentIntent, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.opened++
+	created := time.Now()
+	if g.clock != nil {
+		created = g.clock.Now()
+	}
+	return &domain.PaymentIntent{
+		ID:        "pi-" + strconv.Itoa(g.opened),
+		BookingID: bookingID,
+		Amount:    amount,
+		Status:    domain.PaymentRequiresPayment,
+		CreatedAt: created,
+	}, nil
+}
+
+func (g *gateway) Refund(ctx context.Context, intent *domain.PaymentIntent, amount domain.Money) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.failRefunds > 0 {
+		g.failRefunds--
+		return "", errors.New("provider unavailable")
+	}
+	if g.refunds == nil {
+		g.refunds = map[string]int{}
+	}
+	g.refunds[intent.ID]++
+	return "re-" + intent.ID, nil
+}
+
+func newPaymentService(gw *gateway) (*Service, *storage.MemoryOutbox) {
+	out := storage.NewMemoryOutbox()
+	s := New(storage.NewMemoryRepo(), readmodel.NewMemoryReadModel(), discard{}, tokens{}, gw,
+		WithSpacePricing(flatRate{}), WithPaymentIntents(storage.NewMemoryPayments()), WithOutbox(out), WithHoldTTL(15*time.Minute))
+	return s, out
+}
+
+// succeeded is the provider's event for a payment of pi in full.
+func succeeded(id string, pi *domain.PaymentIntent) domain.PaymentEvent {
+	return domain.PaymentEvent{ID: id, Type: domain.PaymentEventSucceeded, IntentID: pi.ID, BookingID: pi.BookingID, Amount: pi.Amount}
+}
+
+// published counts the events of type typ waiting in out.
+func published(t *testing.T, out *storage.MemoryOutbox, typ string) int {
+	t.Helper()
+	msgs, err := out.Pending(context.Background(), 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for _, m := range msgs {
+		if m.Topic == typ {
+			n++
+		}
+	}
+	return n
+}
+
+func TestApplyPaymentEventPaysBookingOnce(t *testing.T) {
+	gw := &gateway{}
+	s, out := newPaymentService(gw)
+	b := book(t, s)
+	pi, err := s.StartPayment(context.Background(), "alice", b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := s.ApplyPaymentEvent(context.Background(), succeeded("evt-1", pi)); err != nil {
+			t.Fatalf("delivery %d: %v", i, err)
+		}
+	}
+	got, err := s.repo.GetByID(b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != domain.StatusPaid || got.Version != b.Version+1 {
+		t.Fatalf("booking %s at version %d, want paid at %d", got.Status, got.Version, b.Version+1)
+	}
+	if n := published(t, out, "booking_refunded"); n != 0 || len(gw.refunds) != 0 {
+		t.Fatalf("%d refunds published, %v refunded", n, gw.refunds)
+	}
+}
+
+func TestApplyPaymentEventRefundsLatePayment(t *testing.T) {
+	for name, end := range map[string]func(s *Service, b *domain.Booking) error{
+		"cancelled": func(s *Service, b *domain.Booking) error {
+			_, err := s.CancelBooking(context.Background(), "alice", b.ID, 0)
+			return err
+		},
+		"expired": func(s *Service, b *domain.Booking) error {
+			return s.expireHold(b, b.HoldExpiresAt.Add(time.Hour))
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			gw := &gateway{}
+			s, out := newPaymentService(gw)
+			b := book(t, s)
+			pi, err := s.StartPayment(context.Background(), "alice", b.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := end(s, b); err != nil {
+				t.Fatal(err)
+			}
+			// concurrent deliveries of the payment refund it once
+			var wg sync.WaitGroup
+			errs := make(chan error, 8)
+			for i := 0; i < 8; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					errs <- s.ApplyPaymentEvent(context.Background(), succeeded("evt-1", pi))
+				}()
+			}
+			wg.Wait()
+			close(errs)
+			for err := range errs {
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			if gw.refunds[pi.ID] != 1 {
+				t.Fatalf("refunded %d times, want once", gw.refunds[pi.ID])
+			}
+			if n := published(t, out, "booking_refunded"); n != 1 {
+				t.Fatalf("%d booking_refunded events, want 1", n)
+			}
+			if got, _ := s.repo.GetByID(b.ID); got.Status == domain.StatusPaid {
+				t.Fatal("late payment paid the booking")
+			}
+		})
+	}
+}
+
+func TestApplyPaymentEventRefundsStaleIntent(t *testing.T) {
+	gw := &gateway{}
+	s, out := newPaymentService(gw)
+	b := book(t, s)
+	pi, err := s.StartPayment(context.Background(), "alice", b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a longer slot raises the total after the guest opened the payment
+	moved, err := s.RescheduleBooking(context.Background(), "alice", b.ID, b.SlotStart, b.SlotEnd.Add(time.Hour), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moved.Total == pi.Amount {
+		t.Fatalf("total %s unchanged by the reschedule", moved.Total)
+	}
+	if err := s.ApplyPaymentEvent(context.Background(), succeeded("evt-1", pi)); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.repo.GetByID(b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != domain.StatusPending {
+		t.Fatalf("booking %s, want it still pending for the new total", got.Status)
+	}
+	if gw.refunds[pi.ID] != 1 || published(t, out, "booking_refunded") != 1 {
+		t.Fatalf("refunds %v, want the stale payment refunded and published", gw.refunds)
+	}
+}
+
+func TestApplyPaymentEventRetriesFailedHandling(t *testing.T) {
+	gw := &gateway{failRefunds: 1}
+	s, out := newPaymentService(gw)
+	b := book(t, s)
+	pi, err := s.StartPayment(context.Background(), "alice", b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CancelBooking(context.Background(), "alice", b.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ApplyPaymentEvent(context.Background(), succeeded("evt-1", pi)); err == nil {
+		t.Fatal("refund failure acknowledged")
+	}
+	// the provider redelivers the event it got no 200 for
+	if err := s.ApplyPaymentEvent(context.Background(), succeeded("evt-1", pi)); err != nil {
+		t.Fatal(err)
+	}
+	if gw.refunds[pi.ID] != 1 || published(t, out, "booking_refunded") != 1 {
+		t.Fatalf("refunds %v after the redelivery, want one", gw.refunds)
+	}
+}
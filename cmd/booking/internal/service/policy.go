@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"templespace/cmd/booking/internal/domain"
+)
+
+// SpacePolicies resolves the booking policy of a space.
+type SpacePolicies interface {
+	PolicyOf(ctx context.Context, spaceID string) (domain.BookingPolicy, error)
+}
+
+// WithSpacePolicies enforces per-space buffers, durations, alignment, horizon and capacity.
+// Without it every space has the zero policy.
+func WithSpacePolicies(p SpacePolicies) Option {
+	return func(s *Service) { s.policies = p }
+}
+
+// SpaceSchedules resolves the opening hours and blackouts of a space.
+type SpaceSchedules interface {
+	// ScheduleOf returns the schedule with the blackouts overlapping [from, to).
+	ScheduleOf(ctx context.Context, spaceID string, from, to time.Time) (domain.Schedule, error)
+}
+
+// WithSpaceSchedules rejects bookings outside opening hours or inside blackouts.
+// Without it every space is always open.
+func WithSpaceSchedules(sc SpaceSchedules) Option {
+	return func(s *Service) { s.schedules = sc }
+}
+
+func (s *Service) scheduleOf(ctx context.Context, spaceID string, from, to time.Time) (domain.Schedule, error) {
+	if s.schedules == nil {
+		return domain.Schedule{}, nil
+	}
+	return s.schedules.ScheduleOf(ctx, spaceID, from, to)
+}
+
+// checkOpen rejects a slot that falls outside the opening hours of spaceID or inside a blackout.
+func (s *Service) checkOpen(ctx context.Context, spaceID string, start, end time.Time) error {
+	sched, err := s.scheduleOf(ctx, spaceID, start, end)
+	if err != nil {
+		return err
+	}
+	return sched.Check(start, end)
+}
+
+func (s *Service) policyOf(ctx context.Context, spaceID string) (domain.BookingPolicy, error) {
+	if s.policies == nil {
+		return domain.BookingPolicy{}, nil
+	}
+	return s.policies.PolicyOf(ctx, spaceID)
+}
+
+// checkSlot rejects an empty or reversed slot and a booking of seats that breaks policy.
+func (s *Service) checkSlot(policy domain.BookingPolicy, start, end time.Time, seats int) error {
+	if !start.Before(end) {
+		return domain.Invalid(errors.New("slot_start must be before slot_end"))
+	}
+	return policy.Check(start, end, seats, s.now())
+}
+
+// applyPolicy copies the parts of policy a booking keeps for its lifetime.
+func applyPolicy(b *domain.Booking, policy domain.BookingPolicy) {
+	b.BufferBefore, b.BufferAfter = policy.BufferBefore, policy.BufferAfter
+	b.Capacity = policy.Capacity
+}
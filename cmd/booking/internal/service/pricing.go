@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"templespace/cmd/booking/internal/domain"
+)
+
+// SpacePricing resolves how a space charges for bookings.
+type SpacePricing interface {
+	PricingOf(ctx context.Context, spaceID string) (domain.Pricing, error)
+}
+
+// WithSpacePricing prices bookings from the space's hourly rate. Without it every booking is free.
+func WithSpacePricing(p SpacePricing) Option {
+	return func(s *Service) { s.pricing = p }
+}
+
+func (s *Service) pricingOf(ctx context.Context, spaceID string) (domain.Pricing, error) {
+	if s.pricing == nil {
+		return domain.Pricing{}, nil
+	}
+	return s.pricing.PricingOf(ctx, spaceID)
+}
+
+// Quote prices seats over [start, end) in spaceID without holding anything. The slot must satisfy the
+// space's policy and opening hours, as it would have to when booked; availability is not checked.
+// A non-empty voucherCode is checked for the caller and applied but not redeemed.
+func (s *Service) Quote(ctx context.Context, accessToken, spaceID string, start, end time.Time, seats int, voucherCode string) (domain.Quote, error) {
+	p, err := s.verify(ctx, accessToken)
+	if err != nil {
+		return domain.Quote{}, err
+	}
+	if seats < 1 {
+		seats = 1
+	}
+	policy, err := s.policyOf(ctx, spaceID)
+	if err != nil {
+		return domain.Quote{}, err
+	}
+	if err := s.checkSlot(policy, start, end, seats); err != nil {
+		return domain.Quote{}, err
+	}
+	if err := s.checkOpen(ctx, spaceID, start, end); err != nil {
+		return domain.Quote{}, err
+	}
+	q, err := s.quote(ctx, spaceID, start, end, seats)
+	if err != nil {
+		return domain.Quote{}, err
+	}
+	if voucherCode != "" {
+		if _, err := s.applyVoucher(ctx, voucherCode, spaceID, p.UserID, &q); err != nil {
+			return domain.Quote{}, err
+		}
+	}
+	return q, nil
+}
+
+func (s *Service) quote(ctx context.Context, spaceID string, start, end time.Time, seats int) (domain.Quote, error) {
+	pricing, err := s.pricingOf(ctx, spaceID)
+	if err != nil {
+		return domain.Quote{}, err
+	}
+	return pricing.Quote(spaceID, start, end, seats), nil
+}
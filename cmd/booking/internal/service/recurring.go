@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"templespace/cmd/booking/internal/domain"
+	"templespace/cmd/booking/internal/events"
+)
+
+// maxOccurrences bounds how many bookings one recurring request may create.
+const maxOccurrences = 366
+
+// RecurringRequest describes a recurring booking: the first occurrence plus an RFC 5545 RRULE.
+type RecurringRequest struct {
+	SpaceID   string
+	UserID    string
+	SlotStart time.Time
+	SlotEnd   time.Time
+	RRule     string
+	ExDates   []time.Time
+	// TimeZone is the IANA zone the rule is expanded in, keeping wall-clock times across DST;
+	// empty uses SlotStart's own offset.
+	TimeZone string
+	// AllowPartial books the free occurrences and reports the rest instead of failing the whole series.
+	AllowPartial bool
+	// Seats is held in every occurrence; below 1 means 1.
+	Seats int
+}
+
+// SeriesResult is the outcome of a recurring booking request.
+type SeriesResult struct {
+	SeriesID  string
+	Bookings  []*domain.Booking
+	Conflicts []domain.Interval
+}
+
+// CreateRecurringBooking expands the rule into occurrences sharing one series ID and books them
+// atomically: all or nothing, or with AllowPartial every free occurrence. A fully blocked series
+// returns a *domain.SeriesConflictError.
+func (s *Service) CreateRecurringBooking(ctx context.Context, accessToken string, req RecurringRequest) (*SeriesResult, error) {
+	p, err := s.verify(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	userID, err := bookerFor(p, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	seats := max(req.Seats, 1)
+	policy, err := s.policyOf(ctx, req.SpaceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkSlot(policy, req.SlotStart, req.SlotEnd, seats); err != nil {
+		return nil, err
+	}
+	rule, err := domain.ParseRRule(req.RRule)
+	if err != nil {
+		return nil, domain.Invalid(err)
+	}
+	dtstart := req.SlotStart
+	if req.TimeZone != "" {
+		loc, err := time.LoadLocation(req.TimeZone)
+		if err != nil {
+			return nil, domain.Invalid(fmt.Errorf("invalid timezone %q", req.TimeZone))
+		}
+		dtstart = dtstart.In(loc)
+	}
+	starts, err := rule.Expand(dtstart, req.ExDates, maxOccurrences)
+	if err != nil {
+		return nil, domain.Invalid(err)
+	}
+	if len(starts) == 0 {
+		return nil, domain.Invalid(errors.New("rrule yields no occurrences"))
+	}
+
+	res := &SeriesResult{SeriesID: generateID()}
+	duration := req.SlotEnd.Sub(req.SlotStart)
+	sched, err := s.scheduleOf(ctx, req.SpaceID, starts[0], starts[len(starts)-1].Add(duration))
+	if err != nil {
+		return nil, err
+	}
+	pricing, err := s.pricingOf(ctx, req.SpaceID)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	bs := make([]*domain.Booking, 0, len(starts))
+	for _, st := range starts {
+		// every occurrence must fit the policy too, e.g. the horizon or alignment after a DST shift
+		if err := s.checkSlot(policy, st, st.Add(duration), seats); err != nil {
+			return nil, fmt.Errorf("occurrence %s: %w", st.Format(time.RFC3339), err)
+		}
+		b := &domain.Booking{
+			ID:        generateID(),
+			SpaceID:   req.SpaceID,
+			UserID:    userID,
+			SeriesID:  res.SeriesID,
+			SlotStart: st.UTC(),
+			SlotEnd:   st.Add(duration).UTC(),
+			Seats:     seats,
+			Status:    domain.StatusPending,
+			Version:   1,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		b.Total = pricing.Quote(req.SpaceID, b.SlotStart, b.SlotEnd, seats).Total
+		// no hold expiry: occurrences are paid one by one long after the series is booked
+		applyPolicy(b, policy)
+		// an occurrence on a closed day is reported like a taken slot when partial series are allowed
+		if err := sched.Check(b.SlotStart, b.SlotEnd); err != nil {
+			if !req.AllowPartial {
+				return nil, fmt.Errorf("occurrence %s: %w", st.Format(time.RFC3339), err)
+			}
+			res.Conflicts = append(res.Conflicts, domain.Interval{Start: b.SlotStart, End: b.SlotEnd})
+			continue
+		}
+		b.Track(nil, p.UserID)
+		if err := s.record(b, "booking_created", now, events.Booking(b)); err != nil {
+			return nil, err
+		}
+		bs = append(bs, b)
+	}
+
+	if req.AllowPartial {
+		// one write, so a failed request stores nothing and its retry books the same occurrences
+		conflicts, err := s.repo.CreateAvailable(bs)
+		if err != nil {
+			return nil, err
+		}
+		taken := make(map[int64]bool, len(conflicts))
+		for _, c := range conflicts {
+			taken[c.Start.UnixNano()] = true
+		}
+		for _, b := range bs {
+			if !taken[b.SlotStart.UnixNano()] {
+				res.Bookings = append(res.Bookings, b)
+			}
+		}
+		res.Conflicts = append(res.Conflicts, conflicts...)
+		if len(res.Bookings) == 0 {
+			return nil, &domain.SeriesConflictError{Conflicts: res.Conflicts}
+		}
+	} else {
+		if err := s.repo.CreateAllIfAvailable(bs); err != nil {
+			return nil, err
+		}
+		res.Bookings = bs
+	}
+
+	_ = s.readModel.Invalidate(req.SpaceID)
+	return res, nil
+}
+
+// CancelFollowing cancels the given occurrence and every later occurrence of its series. Paid
+// occurrences are refunded as in CancelBooking; those that can no longer be cancelled (cancelled,
+// expired, completed or no-show) are skipped. For a one-off booking it behaves like CancelBooking.
+func (s *Service) CancelFollowing(ctx context.Context, accessToken, bookingID string, expectedVersion int) ([]*domain.Booking, error) {
+	p, err := s.verify(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	anchor, err := s.repo.GetByID(bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, p, anchor); err != nil {
+		return nil, err
+	}
+	if anchor.SeriesID == "" {
+		b, err := s.cancel(ctx, p, anchor, expectedVersion)
+		if err != nil {
+			return nil, err
+		}
+		return []*domain.Booking{b}, nil
+	}
+	if err := checkVersion(anchor, expectedVersion); err != nil {
+		return nil, err
+	}
+	series, err := s.repo.ListBySeries(anchor.SeriesID)
+	if err != nil {
+		return nil, err
+	}
+	var cancelled []*domain.Booking
+	for _, b := range series {
+		if b.SlotStart.Before(anchor.SlotStart) || !b.CanTransition(domain.EventCancel) {
+			continue
+		}
+		out, err := s.cancel(ctx, p, b, 0)
+		if errors.Is(err, domain.ErrInvalidTransition) {
+			continue
+		}
+		if err != nil {
+			return cancelled, err
+		}
+		cancelled = append(cancelled, out)
+	}
+	return cancelled, nil
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"templespace/cmd/booking/internal/domain"
+)
+
+func TestRecurringPartialSeriesIsNotHeld(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2030, 3, 4, 9, 0, 0, 0, time.UTC)}
+	s := newTestService(WithClock(clock), WithHoldTTL(15*time.Minute))
+	start := clock.Now().Add(48 * time.Hour)
+	taken, err := s.CreateBooking(context.Background(), "bob", "space-1", "", start.AddDate(0, 0, 1), start.AddDate(0, 0, 1).Add(time.Hour), 1, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	series, err := s.CreateRecurringBooking(context.Background(), "alice", RecurringRequest{
+		SpaceID: "space-1", SlotStart: start, SlotEnd: start.Add(time.Hour), RRule: "FREQ=DAILY;COUNT=3", AllowPartial: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(series.Bookings) != 2 || len(series.Conflicts) != 1 || !series.Conflicts[0].Start.Equal(taken.SlotStart) {
+		t.Fatalf("booked %d, conflicts %v; want the occurrence on bob's slot skipped", len(series.Bookings), series.Conflicts)
+	}
+	for _, b := range series.Bookings {
+		if !b.HoldExpiresAt.IsZero() {
+			t.Fatalf("occurrence held until %s", b.HoldExpiresAt)
+		}
+	}
+	clock.Advance(time.Hour)
+	if n, err := s.ExpireHolds(context.Background()); err != nil || n != 1 {
+		t.Fatalf("expired %d holds, %v; want only bob's one-off booking", n, err)
+	}
+	for _, b := range series.Bookings {
+		if got, _ := s.repo.GetByID(b.ID); got.Status != domain.StatusPending {
+			t.Fatalf("occurrence %s after the hold TTL", got.Status)
+		}
+	}
+}
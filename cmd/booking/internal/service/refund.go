@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"templespace/cmd/booking/internal/domain"
+	"templespace/cmd/booking/internal/events"
+)
+
+// errPaidMeanwhile stops the cancellation of an unpaid booking that was paid since it was loaded.
+var errPaidMeanwhile = errors.New("booking was paid meanwhile")
+
+// cancel cancels b on behalf of p and frees its slot, refunding a paid booking through cancelPaid.
+// Whether b is paid is decided on the state the cancellation is written over, so a payment landing
+// between load and write is refunded too; with a pinned expectedVersion that payment is a conflict.
+func (s *Service) cancel(ctx context.Context, p Principal, b *domain.Booking, expectedVersion int) (*domain.Booking, error) {
+	for {
+		if b.Status == domain.StatusPaid {
+			return s.cancelPaid(ctx, p, b, expectedVersion)
+		}
+		out, err := s.updateWithRetry(b, p.UserID, expectedVersion, func(b *domain.Booking) error {
+			if b.Status == domain.StatusPaid {
+				return errPaidMeanwhile
+			}
+			return b.Transition(domain.EventCancel)
+		})
+		if errors.Is(err, errPaidMeanwhile) {
+			if b, err = s.repo.GetByID(b.ID); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		s.afterTransition(out)
+		return out, nil
+	}
+}
+
+// cancelPaid cancels a paid booking and refunds it. The guest gets the share the space's cancellation
+// policy grants for the notice given; a cancellation by the space owner or a booking admin is refunded
+// in full. The refund is made before the booking is cancelled; the gateway refunds an intent once, so
+// retrying after a failed write does not pay twice.
+func (s *Service) cancelPaid(ctx context.Context, p Principal, b *domain.Booking, expectedVersion int) (*domain.Booking, error) {
+	if err := checkVersion(b, expectedVersion); err != nil {
+		return nil, err
+	}
+	percent := 100.0
+	if p.UserID == b.UserID {
+		policy, err := s.policyOf(ctx, b.SpaceID)
+		if err != nil {
+			return nil, err
+		}
+		percent = policy.Cancellation.RefundPercent(b.SlotStart, s.now())
+	}
+	amount := domain.RefundOf(b.Total, percent)
+	refundID, err := s.refund(ctx, b, amount)
+	if err != nil {
+		return nil, err
+	}
+	b, err = s.updateAndRecord(b, p.UserID, expectedVersion, func(b *domain.Booking) error {
+		if err := b.Transition(domain.EventCancel); err != nil {
+			return err
+		}
+		b.Refunded = amount
+		return nil
+	}, func(b *domain.Booking) error {
+		if amount.Amount == 0 {
+			return nil
+		}
+		return s.record(b, "booking_refunded", b.UpdatedAt, events.BookingRefundedV1{
+			BookingID: b.ID,
+			RefundID:  refundID,
+			Percent:   percent,
+			Total:     events.Money(b.Total),
+			Refund:    events.Money(amount),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.afterTransition(b)
+	return b, nil
+}
+
+// refund pays amount back through the gateway when the booking was paid with a provider intent. A
+// booking marked paid offline has no intent and is refunded the same way; the refund ID is then empty.
+func (s *Service) refund(ctx context.Context, b *domain.Booking, amount domain.Money) (string, error) {
+	if amount.Amount <= 0 || s.intents == nil {
+		return "", nil
+	}
+	pi, err := s.intents.LatestIntent(b.ID)
+	if errors.Is(err, domain.ErrNotFound) || (err == nil && pi.Status != domain.PaymentSucceeded) {
+		log.Printf("booking %s was paid offline; refund %s offline", b.ID, amount)
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return s.payment.Refund(ctx, pi, amount)
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"templespace/cmd/booking/internal/domain"
+	"templespace/cmd/booking/internal/events"
+)
+
+// RescheduleBooking moves a live booking to [start, end). The repository checks the new interval
+// (buffers from the space's current policy included) against every other booking of the space and
+// writes the move in one step, so the booking never holds both slots nor neither. The total is
+// re-quoted for the new slot, keeping any voucher; a paid booking may only move to a slot of the
+// same price. expectedVersion works as in ConfirmPayment.
+func (s *Service) RescheduleBooking(ctx context.Context, accessToken, bookingID string, start, end time.Time, expectedVersion int) (*domain.Booking, error) {
+	p, err := s.verify(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.repo.GetByID(bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, p, b); err != nil {
+		return nil, err
+	}
+	policy, err := s.policyOf(ctx, b.SpaceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkSlot(policy, start, end, b.Seats); err != nil {
+		return nil, err
+	}
+	if err := s.checkOpen(ctx, b.SpaceID, start, end); err != nil {
+		return nil, err
+	}
+	q, err := s.quote(ctx, b.SpaceID, start, end, b.Seats)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.reapplyVoucher(b, &q); err != nil {
+		return nil, err
+	}
+	var oldStart, oldEnd time.Time
+	b, err = s.updateAndRecord(b, p.UserID, expectedVersion, func(b *domain.Booking) error {
+		if b.Status == domain.StatusPaid && q.Total != b.Total {
+			return &domain.PolicyError{Reason: fmt.Sprintf("paid booking cannot move to a slot priced %s instead of %s", q.Total, b.Total)}
+		}
+		if err := b.Transition(domain.EventReschedule); err != nil {
+			return err
+		}
+		oldStart, oldEnd = b.SlotStart, b.SlotEnd
+		b.SlotStart, b.SlotEnd = start, end
+		b.Total = q.Total
+		applyPolicy(b, policy)
+		return nil
+	}, func(b *domain.Booking) error {
+		return s.record(b, "booking_rescheduled", b.UpdatedAt, events.BookingRescheduledV1{
+			Booking:      events.Booking(b),
+			OldSlotStart: oldStart.UTC(),
+			OldSlotEnd:   oldEnd.UTC(),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	_ = s.readModel.Invalidate(b.SpaceID)
+	return b, nil
+}
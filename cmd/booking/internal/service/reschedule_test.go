@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"templespace/cmd/booking/internal/domain"
+	"templespace/cmd/booking/internal/events"
+	"templespace/cmd/booking/internal/storage"
+	"templespace/internal/cloudevents"
+)
+
+func TestRescheduleIntoTakenSlot(t *testing.T) {
+	s := newTestService()
+	b := book(t, s)
+	other, err := s.CreateBooking(context.Background(), "bob", "space-1", "", b.SlotEnd, b.SlotEnd.Add(time.Hour), 1, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.RescheduleBooking(context.Background(), "alice", b.ID, other.SlotStart, other.SlotEnd, 0); !errors.Is(err, domain.ErrSlotNotAvailable) {
+		t.Fatalf("moving onto bob's slot: %v", err)
+	}
+	got, err := s.repo.GetByID(b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.SlotStart.Equal(b.SlotStart) || got.Version != b.Version {
+		t.Fatalf("booking at %s version %d after the refused move", got.SlotStart, got.Version)
+	}
+}
+
+func TestRescheduleChecksVersion(t *testing.T) {
+	s := newTestService()
+	b := book(t, s)
+	later := b.SlotStart.Add(3 * time.Hour)
+	moved, err := s.RescheduleBooking(context.Background(), "alice", b.ID, later, later.Add(time.Hour), b.Version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a client still holding the first version must not move the booking back
+	var conflict *domain.ConflictError
+	if _, err := s.RescheduleBooking(context.Background(), "alice", b.ID, b.SlotStart, b.SlotEnd, b.Version); !errors.As(err, &conflict) {
+		t.Fatalf("stale version: %v", err)
+	}
+	got, err := s.repo.GetByID(b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.SlotStart.Equal(later) || got.Version != moved.Version {
+		t.Fatalf("booking at %s version %d, want %s version %d", got.SlotStart, got.Version, later, moved.Version)
+	}
+}
+
+func TestRescheduleRequotesAndRecordsOldSlot(t *testing.T) {
+	s := newTestService(WithSpacePricing(flatRate{}))
+	b := book(t, s)
+	start := b.SlotStart.Add(24 * time.Hour)
+	moved, err := s.RescheduleBooking(context.Background(), "alice", b.ID, start, start.Add(2*time.Hour), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (domain.Money{Amount: 4000, Currency: "EUR"}); moved.Total != want {
+		t.Fatalf("total %s after moving to two hours, want %s", moved.Total, want)
+	}
+	msgs, err := s.repo.(*storage.MemoryRepo).Outbox().Pending(context.Background(), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var data *events.BookingRescheduledV1
+	for _, m := range msgs {
+		if m.Topic != "booking_rescheduled" {
+			continue
+		}
+		e, err := cloudevents.Decode(m.Payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data = &events.BookingRescheduledV1{}
+		if err := json.Unmarshal(e.Data, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if data == nil {
+		t.Fatal("no booking_rescheduled event recorded")
+	}
+	if !data.OldSlotStart.Equal(b.SlotStart) || !data.OldSlotEnd.Equal(b.SlotEnd) {
+		t.Fatalf("old slot %s-%s, want %s-%s", data.OldSlotStart, data.OldSlotEnd, b.SlotStart, b.SlotEnd)
+	}
+	if !data.Booking.SlotStart.Equal(start) || data.Booking.Total.AmountMinor != 4000 {
+		t.Fatalf("event booking %+v, want the moved slot and total", data.Booking)
+	}
+}
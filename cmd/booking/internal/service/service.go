@@ -2,19 +2,52 @@ package service
 
 import (
 	"context"
-	"encoding/json"
+	"crypto/rand"
 	"errors"
+	"fmt"
+	"log"
+	"strings"
 	"time"
 
 	"templespace/cmd/booking/internal/domain"
+	"templespace/cmd/booking/internal/events"
 )
 
+// Principal is the caller identity resolved from an access token.
+type Principal struct {
+	UserID string
+	Scopes []string
+	// ExpiresAt is when the access token stops being valid; zero when the verifier does not say.
+	ExpiresAt time.Time
+}
+
+// HasScope reports whether the principal holds scope, either directly or via a "prefix:*" wildcard.
+func (p Principal) HasScope(scope string) bool {
+	for _, g := range p.Scopes {
+		if g == scope {
+			return true
+		}
+		if strings.HasSuffix(g, ":*") && strings.HasPrefix(scope, strings.TrimSuffix(g, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+// ScopeBookingAdmin lets the holder act on bookings of other users.
+const ScopeBookingAdmin = "booking:*"
+
 type TokenVerifier interface {
-	Verify(ctx context.Context, token string) (userID string, err error)
+	Verify(ctx context.Context, token string) (Principal, error)
 }
 
+// PaymentGateway opens payments at the payment provider, which reports their outcome through a webhook.
 type PaymentGateway interface {
-	Charge(ctx context.Context, bookingID string) error
+	// CreateIntent opens a payment of amount for bookingID.
+	CreateIntent(ctx context.Context, bookingID string, amount domain.Money) (*domain.PaymentIntent, error)
+	// Refund pays amount of the succeeded intent back and returns the provider's refund ID. Repeated
+	// calls for one intent refund only once.
+	Refund(ctx context.Context, intent *domain.PaymentIntent, amount domain.Money) (string, error)
 }
 
 type Service struct {
@@ -23,92 +56,298 @@ type Service struct {
 	events    domain.EventPublisher
 	auth      TokenVerifier
 	payment   PaymentGateway
+	spaces    SpaceOwners
+	policies  SpacePolicies
+	schedules SpaceSchedules
+	pricing   SpacePricing
+	tags      SpaceTags
+	vouchers  domain.VoucherRepository
+	intents   domain.PaymentRepository
+	idem      domain.IdempotencyStore
+	idemTTL   time.Duration
+	outbox    domain.Outbox
+	clock     Clock
+	holdTTL   time.Duration
 }
 
-func New(repo domain.BookingRepository, readModel domain.ReadModel, events domain.EventPublisher, auth TokenVerifier, payment PaymentGateway) *Service {
-	return &Service{repo: repo, readModel: readModel, events: events, auth: auth, payment: payment}
+// Clock abstracts time so tests can advance it.
+type Clock interface {
+	Now() time.Time
 }
 
-func (s *Service) CreateBooking(ctx context.Context, accessToken, spaceID, userID string, start, end time.Time) (*domain.Booking, error) {
-	if _, err := s.auth.Verify(ctx, accessToken); err != nil {
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// Option configures optional Service dependencies.
+type Option func(*Service)
+
+// WithClock replaces the wall clock used for timestamps and hold expiry.
+func WithClock(c Clock) Option {
+	return func(s *Service) { s.clock = c }
+}
+
+// WithHoldTTL makes pending bookings expire ttl after creation unless paid. Zero disables expiry.
+func WithHoldTTL(ttl time.Duration) Option {
+	return func(s *Service) { s.holdTTL = ttl }
+}
+
+// WithSpaceOwners lets space owners manage bookings made for their spaces.
+func WithSpaceOwners(o SpaceOwners) Option {
+	return func(s *Service) { s.spaces = o }
+}
+
+func New(repo domain.BookingRepository, readModel domain.ReadModel, events domain.EventPublisher, auth TokenVerifier, payment PaymentGateway, opts ...Option) *Service {
+	s := &Service{repo: repo, readModel: readModel, events: events, auth: auth, payment: payment, clock: systemClock{}}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// CreateBooking holds seats over [start, end) in spaceID; seats below 1 default to 1. A non-empty
+// voucherCode is redeemed together with the insert of the booking, so a taken slot redeems nothing.
+func (s *Service) CreateBooking(ctx context.Context, accessToken, spaceID, userID string, start, end time.Time, seats int, voucherCode string) (*domain.Booking, error) {
+	p, err := s.verify(ctx, accessToken)
+	if err != nil {
 		return nil, err
 	}
-	available, err := s.repo.IsAvailable(spaceID, start, end)
+	userID, err = bookerFor(p, userID)
 	if err != nil {
 		return nil, err
 	}
-	if !available {
-		return nil, errors.New("slot not available")
+	if seats < 1 {
+		seats = 1
 	}
+	policy, err := s.policyOf(ctx, spaceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkSlot(policy, start, end, seats); err != nil {
+		return nil, err
+	}
+	if err := s.checkOpen(ctx, spaceID, start, end); err != nil {
+		return nil, err
+	}
+	q, err := s.quote(ctx, spaceID, start, end, seats)
+	if err != nil {
+		return nil, err
+	}
+	gross := q.Total
+	var voucher *domain.Voucher
+	if voucherCode != "" {
+		if voucher, err = s.applyVoucher(ctx, voucherCode, spaceID, userID, &q); err != nil {
+			return nil, err
+		}
+	}
+	now := s.now()
 	b := &domain.Booking{
 		ID:        generateID(),
 		SpaceID:   spaceID,
 		UserID:    userID,
 		SlotStart: start,
 		SlotEnd:   end,
+		Seats:     seats,
+		Total:     q.Total,
 		Status:    domain.StatusPending,
 		Version:   1,
-		CreatedAt: time.Now().UTC(),
-		UpdatedAt: time.Now().UTC(),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	applyPolicy(b, policy)
+	if s.holdTTL > 0 {
+		b.HoldExpiresAt = now.Add(s.holdTTL)
+	}
+	if voucher != nil {
+		b.VoucherCode = voucher.Code
+		discount := domain.Money{Amount: gross.Amount - q.Total.Amount, Currency: q.Total.Currency}
+		b.Redemption = &domain.Redemption{Code: voucher.Code, BookingID: b.ID, UserID: userID, Discount: discount, RedeemedAt: now}
+	}
+	b.Track(nil, p.UserID)
+	if err := s.record(b, "booking_created", now, events.Booking(b)); err != nil {
+		return nil, err
 	}
-	if err := s.repo.Create(b); err != nil {
+	// check, insert and redemption happen atomically in the repository so concurrent requests can
+	// neither double-book nor overuse a voucher
+	if err := s.repo.CreateIfAvailable(b); err != nil {
 		return nil, err
 	}
-	_ = s.readModel.CacheAvailability(spaceID, start, end, false)
-	payload, _ := json.Marshal(b)
-	_ = s.events.Publish("booking_created", b.ID, payload)
+	_ = s.readModel.Invalidate(spaceID)
+	// the booking stands without an intent; StartPayment opens one later
+	if _, err := s.startPayment(ctx, b); err != nil {
+		log.Printf("payment intent for booking %s: %v", b.ID, err)
+	}
 	return b, nil
 }
 
-func (s *Service) ConfirmPayment(ctx context.Context, accessToken, bookingID string) (*domain.Booking, error) {
-	if _, err := s.auth.Verify(ctx, accessToken); err != nil {
+// ConfirmPayment marks the booking paid without the payment provider: the guest may confirm a free
+// booking, while a priced one needs the space owner or a booking admin recording an offline payment.
+// Provider payments are confirmed by ApplyPaymentEvent. A non-zero expectedVersion makes the call
+// conditional on the booking's current version (HTTP If-Match).
+func (s *Service) ConfirmPayment(ctx context.Context, accessToken, bookingID string, expectedVersion int) (*domain.Booking, error) {
+	p, err := s.verify(ctx, accessToken)
+	if err != nil {
 		return nil, err
 	}
 	b, err := s.repo.GetByID(bookingID)
 	if err != nil {
 		return nil, err
 	}
-	if b.Status == domain.StatusCancelled {
-		return nil, errors.New("booking cancelled")
+	if b.Total.Amount > 0 {
+		err = s.authorizeManager(ctx, p, b)
+	} else {
+		err = s.authorize(ctx, p, b)
 	}
-	if err := s.payment.Charge(ctx, bookingID); err != nil {
+	if err != nil {
 		return nil, err
 	}
-	b.Status = domain.StatusPaid
-	b.Version++
-	b.UpdatedAt = time.Now().UTC()
-	if err := s.repo.Update(b); err != nil {
+	return s.transition(b, p.UserID, expectedVersion, domain.EventPay)
+}
+
+// CancelBooking cancels the booking and frees its slot; a paid booking is refunded as cancelPaid
+// describes. expectedVersion works as in ConfirmPayment.
+func (s *Service) CancelBooking(ctx context.Context, accessToken, bookingID string, expectedVersion int) (*domain.Booking, error) {
+	p, err := s.verify(ctx, accessToken)
+	if err != nil {
 		return nil, err
 	}
-	payload, _ := json.Marshal(b)
-	_ = s.events.Publish("booking_paid", b.ID, payload)
-	return b, nil
+	b, err := s.repo.GetByID(bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, p, b); err != nil {
+		return nil, err
+	}
+	return s.cancel(ctx, p, b, expectedVersion)
 }
 
-func (s *Service) CancelBooking(ctx context.Context, accessToken, bookingID string) (*domain.Booking, error) {
-	if _, err := s.auth.Verify(ctx, accessToken); err != nil {
+// ConfirmBooking accepts a pending booking on behalf of the space.
+func (s *Service) ConfirmBooking(ctx context.Context, accessToken, bookingID string, expectedVersion int) (*domain.Booking, error) {
+	return s.manage(ctx, accessToken, bookingID, expectedVersion, domain.EventConfirm)
+}
+
+// CompleteBooking records that a paid booking took place.
+func (s *Service) CompleteBooking(ctx context.Context, accessToken, bookingID string, expectedVersion int) (*domain.Booking, error) {
+	return s.manage(ctx, accessToken, bookingID, expectedVersion, domain.EventComplete)
+}
+
+// MarkNoShow records that the guest of a paid booking did not turn up.
+func (s *Service) MarkNoShow(ctx context.Context, accessToken, bookingID string, expectedVersion int) (*domain.Booking, error) {
+	return s.manage(ctx, accessToken, bookingID, expectedVersion, domain.EventNoShow)
+}
+
+// manage applies an event reserved for the space owner or booking admins.
+func (s *Service) manage(ctx context.Context, accessToken, bookingID string, expectedVersion int, ev domain.BookingEvent) (*domain.Booking, error) {
+	p, err := s.verify(ctx, accessToken)
+	if err != nil {
 		return nil, err
 	}
 	b, err := s.repo.GetByID(bookingID)
 	if err != nil {
 		return nil, err
 	}
-	if b.Status == domain.StatusPaid {
-		return nil, errors.New("cannot cancel paid booking")
+	if err := s.authorizeManager(ctx, p, b); err != nil {
+		return nil, err
 	}
-	b.Status = domain.StatusCancelled
-	b.Version++
-	b.UpdatedAt = time.Now().UTC()
-	if err := s.repo.Update(b); err != nil {
+	return s.transition(b, p.UserID, expectedVersion, ev)
+}
+
+// transition applies ev on behalf of actor under optimistic concurrency and publishes one event for
+// the new status.
+func (s *Service) transition(b *domain.Booking, actor string, expectedVersion int, ev domain.BookingEvent) (*domain.Booking, error) {
+	b, err := s.updateWithRetry(b, actor, expectedVersion, func(b *domain.Booking) error {
+		return b.Transition(ev)
+	})
+	if err != nil {
 		return nil, err
 	}
-	payload, _ := json.Marshal(b)
-	_ = s.events.Publish("booking_cancelled", b.ID, payload)
-	_ = s.readModel.CacheAvailability(b.SpaceID, b.SlotStart, b.SlotEnd, true)
+	s.afterTransition(b)
 	return b, nil
 }
 
+// afterTransition runs once a status change is stored: when the booking no longer holds its slot it
+// drops the cached free slots of its space and releases its voucher. The event for the
+// new status was written to the outbox with the change.
+func (s *Service) afterTransition(b *domain.Booking) {
+	if !b.Status.HoldsSlot() {
+		_ = s.readModel.Invalidate(b.SpaceID)
+		s.releaseVoucher(b)
+	}
+}
+
+// maxConflictRetries bounds how often updateWithRetry reloads after losing a concurrent write.
+const maxConflictRetries = 3
+
+// updateWithRetry applies mutate to b and writes it back conditioned on b's version. When another
+// writer got there first it reloads the booking and re-applies mutate, so rules are always checked
+// against the latest state. A caller-pinned expectedVersion is never retried. The change goes into
+// the booking's history under actor, and a status change records the event for the new status, e.g.
+// booking_paid, to be written with the booking.
+func (s *Service) updateWithRetry(b *domain.Booking, actor string, expectedVersion int, mutate func(b *domain.Booking) error) (*domain.Booking, error) {
+	return s.updateAndRecord(b, actor, expectedVersion, mutate, nil)
+}
+
+// updateAndRecord is updateWithRetry that also lets record add events to each attempt. record sees
+// the booking as it is about to be stored, new version included.
+func (s *Service) updateAndRecord(b *domain.Booking, actor string, expectedVersion int, mutate, record func(b *domain.Booking) error) (*domain.Booking, error) {
+	for attempt := 0; ; attempt++ {
+		if err := checkVersion(b, expectedVersion); err != nil {
+			return nil, err
+		}
+		before := *b
+		if err := mutate(b); err != nil {
+			return nil, err
+		}
+		current := b.Version
+		b.Version++
+		b.UpdatedAt = s.now()
+		b.Track(&before, actor)
+		if b.Status != before.Status {
+			if err := s.record(b, b.Status.Topic(), b.UpdatedAt, events.Booking(b)); err != nil {
+				return nil, err
+			}
+		}
+		if record != nil {
+			if err := record(b); err != nil {
+				return nil, err
+			}
+		}
+		err := s.repo.Update(b, current)
+		if err == nil {
+			return b, nil
+		}
+		if !errors.Is(err, domain.ErrConflict) || expectedVersion != 0 || attempt >= maxConflictRetries {
+			return nil, err
+		}
+		if b, err = s.repo.GetByID(b.ID); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func checkVersion(b *domain.Booking, expectedVersion int) error {
+	if expectedVersion != 0 && b.Version != expectedVersion {
+		return &domain.ConflictError{ID: b.ID, Expected: expectedVersion, Actual: b.Version}
+	}
+	return nil
+}
+
+// verify resolves the caller behind accessToken, reporting any verifier failure as domain.ErrUnauthorized.
+func (s *Service) verify(ctx context.Context, accessToken string) (Principal, error) {
+	p, err := s.auth.Verify(ctx, accessToken)
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: %v", domain.ErrUnauthorized, err)
+	}
+	return p, nil
+}
+
+func (s *Service) now() time.Time { return s.clock.Now().UTC() }
+
+// generateID returns a random RFC 4122 version 4 UUID.
 func generateID() string {
-	// simple placeholder; replace with proper UUID
-	return time.Now().UTC().Format("20060102150405.000000000")
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
 }
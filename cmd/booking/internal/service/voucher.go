@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"templespace/cmd/booking/internal/domain"
+)
+
+// ScopeVoucherAdmin lets the holder create vouchers and report their redemptions.
+const ScopeVoucherAdmin = "voucher:*"
+
+// SpaceTags resolves the tags of a space for vouchers restricted to tags.
+type SpaceTags interface {
+	TagsOf(ctx context.Context, spaceID string) ([]string, error)
+}
+
+// WithVouchers enables discount codes on quotes and bookings. Without it every code is rejected. v must
+// be the voucher store of the booking repository, which redeems codes with the bookings it creates.
+func WithVouchers(v domain.VoucherRepository) Option {
+	return func(s *Service) { s.vouchers = v }
+}
+
+// WithSpaceTags lets vouchers be restricted to spaces by tag. Without it spaces have no tags.
+func WithSpaceTags(t SpaceTags) Option {
+	return func(s *Service) { s.tags = t }
+}
+
+// VoucherUsage is a voucher with its number of active redemptions.
+type VoucherUsage struct {
+	*domain.Voucher
+	Redeemed int
+}
+
+// CreateVoucher stores a new discount code; it requires ScopeVoucherAdmin.
+func (s *Service) CreateVoucher(ctx context.Context, accessToken string, v *domain.Voucher) (*domain.Voucher, error) {
+	if err := s.voucherAdmin(ctx, accessToken); err != nil {
+		return nil, err
+	}
+	v.Code = domain.NormalizeVoucherCode(v.Code)
+	v.Amount.Currency = strings.ToUpper(v.Amount.Currency)
+	if err := v.Validate(); err != nil {
+		return nil, domain.Invalid(err)
+	}
+	v.CreatedAt = s.now()
+	if err := s.vouchers.CreateVoucher(v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// ListVouchers returns every voucher with its active redemptions, oldest first; it requires ScopeVoucherAdmin.
+func (s *Service) ListVouchers(ctx context.Context, accessToken string) ([]VoucherUsage, error) {
+	if err := s.voucherAdmin(ctx, accessToken); err != nil {
+		return nil, err
+	}
+	vs, err := s.vouchers.ListVouchers()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(vs, func(i, j int) bool { return vs[i].CreatedAt.Before(vs[j].CreatedAt) })
+	out := make([]VoucherUsage, 0, len(vs))
+	for _, v := range vs {
+		total, _, err := s.vouchers.CountRedemptions(v.Code, "")
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, VoucherUsage{Voucher: v, Redeemed: total})
+	}
+	return out, nil
+}
+
+// ListRedemptions reports every use of code, released ones included; it requires ScopeVoucherAdmin.
+func (s *Service) ListRedemptions(ctx context.Context, accessToken, code string) ([]*domain.Redemption, error) {
+	if err := s.voucherAdmin(ctx, accessToken); err != nil {
+		return nil, err
+	}
+	code = domain.NormalizeVoucherCode(code)
+	if _, err := s.vouchers.GetVoucher(code); err != nil {
+		return nil, err
+	}
+	return s.vouchers.ListRedemptions(code)
+}
+
+func (s *Service) voucherAdmin(ctx context.Context, accessToken string) error {
+	p, err := s.verify(ctx, accessToken)
+	if err != nil {
+		return err
+	}
+	if s.vouchers == nil {
+		return fmt.Errorf("vouchers are %w", domain.ErrNotEnabled)
+	}
+	if !p.HasScope(ScopeVoucherAdmin) {
+		return fmt.Errorf("%w: managing vouchers requires %s", domain.ErrForbidden, ScopeVoucherAdmin)
+	}
+	return nil
+}
+
+// applyVoucher checks that userID may use code for spaceID now and takes its discount off q. The usage
+// limits checked here are advisory; the repository enforces them atomically when it creates the booking.
+func (s *Service) applyVoucher(ctx context.Context, code, spaceID, userID string, q *domain.Quote) (*domain.Voucher, error) {
+	code = domain.NormalizeVoucherCode(code)
+	if s.vouchers == nil {
+		return nil, &domain.VoucherError{Code: code, Reason: "vouchers are not enabled"}
+	}
+	v, err := s.vouchers.GetVoucher(code)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, &domain.VoucherError{Code: code, Reason: "unknown code"}
+	}
+	if err != nil {
+		return nil, err
+	}
+	var tags []string
+	if len(v.Tags) > 0 && s.tags != nil {
+		if tags, err = s.tags.TagsOf(ctx, spaceID); err != nil {
+			return nil, err
+		}
+	}
+	if err := v.Check(spaceID, tags, s.now()); err != nil {
+		return nil, err
+	}
+	total, byUser, err := s.vouchers.CountRedemptions(v.Code, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := v.CheckLimits(total, byUser); err != nil {
+		return nil, err
+	}
+	if err := v.Apply(q); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// reapplyVoucher takes the discount of the voucher already redeemed for b off a fresh quote; validity
+// and limits were settled at redemption.
+func (s *Service) reapplyVoucher(b *domain.Booking, q *domain.Quote) error {
+	if b.VoucherCode == "" || s.vouchers == nil {
+		return nil
+	}
+	v, err := s.vouchers.GetVoucher(b.VoucherCode)
+	if err != nil {
+		return err
+	}
+	return v.Apply(q)
+}
+
+// releaseVoucher frees the redemption of a booking that no longer holds its slot.
+func (s *Service) releaseVoucher(b *domain.Booking) {
+	if b.VoucherCode != "" && s.vouchers != nil {
+		_ = s.vouchers.Release(b.ID, s.now())
+	}
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"templespace/cmd/booking/internal/domain"
+	"templespace/cmd/booking/internal/readmodel"
+	"templespace/cmd/booking/internal/storage"
+)
+
+func TestVoucherRedeemedWithBooking(t *testing.T) {
+	repo := storage.NewMemoryRepo()
+	vouchers := repo.Vouchers()
+	if err := vouchers.CreateVoucher(&domain.Voucher{Code: "SPRING", Kind: domain.VoucherPercent, Percent: 10, MaxRedemptions: 2}); err != nil {
+		t.Fatal(err)
+	}
+	s := New(repo, readmodel.NewMemoryReadModel(), discard{}, tokens{}, nil, WithVouchers(vouchers))
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+	book := func(user string, hour int) error {
+		from := start.Add(time.Duration(hour) * time.Hour)
+		_, err := s.CreateBooking(context.Background(), user, "space-1", "", from, from.Add(time.Hour), 1, "spring")
+		return err
+	}
+	redeemed := func() int {
+		total, _, err := vouchers.CountRedemptions("SPRING", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return total
+	}
+
+	if err := book("alice", 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := book("bob", 0); !errors.Is(err, domain.ErrSlotNotAvailable) {
+		t.Fatalf("booking a taken slot: %v", err)
+	}
+	if n := redeemed(); n != 1 {
+		t.Fatalf("%d redemptions after a taken slot, want 1", n)
+	}
+	if err := book("bob", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := book("carol", 2); !errors.Is(err, domain.ErrVoucherRejected) {
+		t.Fatalf("booking with a used up voucher: %v", err)
+	}
+	if n := redeemed(); n != 2 {
+		t.Fatalf("%d redemptions, want 2", n)
+	}
+}
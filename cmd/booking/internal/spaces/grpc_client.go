@@ -0,0 +1,227 @@
+//go:build grpc
+
+package spaces
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	gogrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"templespace/cmd/booking/internal/domain"
+)
+
+const (
+	getSpaceMethod    = "/space.SpaceService/GetSpace"
+	getScheduleMethod = "/space.SpaceService/GetSchedule"
+	getPricingMethod  = "/space.SpaceService/GetPricing"
+)
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// GRPCClient looks up spaces through space.SpaceService over the structpb contract.
+type GRPCClient struct {
+	conn    *gogrpc.ClientConn
+	timeout time.Duration
+	// currency prices spaces whose attributes name none
+	currency string
+}
+
+func NewGRPCClient(addr string, timeout time.Duration, currency string) (*GRPCClient, error) {
+	conn, err := gogrpc.NewClient(addr, gogrpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+	return &GRPCClient{conn: conn, timeout: timeout, currency: currency}, nil
+}
+
+// OwnerOf returns the user ID that created the space.
+func (c *GRPCClient) OwnerOf(ctx context.Context, spaceID string) (string, error) {
+	out, err := c.getSpace(ctx, spaceID)
+	if err != nil {
+		return "", err
+	}
+	return out.GetFields()["owner_id"].GetStringValue(), nil
+}
+
+// PolicyOf reads the booking policy from the space's attributes.booking_policy, aligned in the
+// space's time zone.
+func (c *GRPCClient) PolicyOf(ctx context.Context, spaceID string) (domain.BookingPolicy, error) {
+	out, err := c.getSpace(ctx, spaceID)
+	if err != nil {
+		return domain.BookingPolicy{}, err
+	}
+	p, err := domain.PolicyFromAttributes(out.GetFields()["attributes"].GetStructValue().AsMap())
+	if err != nil {
+		return p, err
+	}
+	p.Location, err = time.LoadLocation(out.GetFields()["time_zone"].GetStringValue())
+	return p, err
+}
+
+// TagsOf returns the tags of a space.
+func (c *GRPCClient) TagsOf(ctx context.Context, spaceID string) ([]string, error) {
+	out, err := c.getSpace(ctx, spaceID)
+	if err != nil {
+		return nil, err
+	}
+	var tags []string
+	for _, v := range out.GetFields()["tags"].GetListValue().GetValues() {
+		tags = append(tags, v.GetStringValue())
+	}
+	return tags, nil
+}
+
+// PricingOf returns the space's price_per_hour with its pricing rules. Prices are in the space's
+// currency, or in the client's default currency when the space names none.
+func (c *GRPCClient) PricingOf(ctx context.Context, spaceID string) (domain.Pricing, error) {
+	out, err := c.invoke(ctx, getPricingMethod, map[string]interface{}{"space_id": spaceID})
+	if err != nil {
+		return domain.Pricing{}, err
+	}
+	f := out.GetFields()
+	currency := c.currency
+	if cur := f["currency"].GetStringValue(); cur != "" {
+		currency = cur
+	}
+	loc, err := time.LoadLocation(f["time_zone"].GetStringValue())
+	if err != nil {
+		return domain.Pricing{}, err
+	}
+	p := domain.Pricing{HourlyRate: domain.MoneyFromMajor(f["price_per_hour"].GetNumberValue(), currency), Location: loc}
+	for _, v := range f["rules"].GetListValue().GetValues() {
+		rf := v.GetStructValue().GetFields()
+		name := rf["name"].GetStringValue()
+		switch kind := rf["kind"].GetStringValue(); kind {
+		case "weekly":
+			r := domain.RateRule{Name: name, Multiplier: rf["multiplier"].GetNumberValue()}
+			for _, d := range rf["weekdays"].GetListValue().GetValues() {
+				wd, ok := weekdayNames[d.GetStringValue()]
+				if !ok {
+					return domain.Pricing{}, fmt.Errorf("space %s: invalid weekday %q", spaceID, d.GetStringValue())
+				}
+				r.Weekdays = append(r.Weekdays, wd)
+			}
+			if from := rf["from"].GetStringValue(); from != "" {
+				open, err1 := parseClock(from)
+				closeAt, err2 := parseClock(rf["to"].GetStringValue())
+				if err := errors.Join(err1, err2); err != nil {
+					return domain.Pricing{}, fmt.Errorf("space %s: %w", spaceID, err)
+				}
+				r.Hours = domain.ClockRange{Open: open, Close: closeAt}
+			}
+			p.Rules = append(p.Rules, r)
+		case "date_range":
+			first, err1 := time.ParseInLocation("2006-01-02", rf["start_date"].GetStringValue(), loc)
+			last, err2 := time.ParseInLocation("2006-01-02", rf["end_date"].GetStringValue(), loc)
+			if err := errors.Join(err1, err2); err != nil {
+				return domain.Pricing{}, fmt.Errorf("space %s: %w", spaceID, err)
+			}
+			p.Rules = append(p.Rules, domain.RateRule{
+				Name:       name,
+				Multiplier: rf["multiplier"].GetNumberValue(),
+				Period:     domain.Interval{Start: first, End: last.AddDate(0, 0, 1)},
+			})
+		case "duration_discount":
+			p.Discounts = append(p.Discounts, domain.DurationDiscount{
+				Name:        name,
+				MinDuration: time.Duration(rf["min_hours"].GetNumberValue() * float64(time.Hour)),
+				Percent:     rf["discount_percent"].GetNumberValue(),
+			})
+		default:
+			return domain.Pricing{}, fmt.Errorf("space %s: unknown pricing rule kind %q", spaceID, kind)
+		}
+	}
+	return p, nil
+}
+
+// ScheduleOf returns the opening hours of a space and its blackouts overlapping [from, to).
+func (c *GRPCClient) ScheduleOf(ctx context.Context, spaceID string, from, to time.Time) (domain.Schedule, error) {
+	out, err := c.invoke(ctx, getScheduleMethod, map[string]interface{}{
+		"space_id": spaceID,
+		"from":     from.UTC().Format(time.RFC3339),
+		"to":       to.UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return domain.Schedule{}, err
+	}
+	var sched domain.Schedule
+	if hours := out.GetFields()["opening_hours"].GetStructValue(); hours != nil {
+		loc, err := time.LoadLocation(hours.GetFields()["time_zone"].GetStringValue())
+		if err != nil {
+			return domain.Schedule{}, err
+		}
+		sched.Location = loc
+		sched.Weekly = map[time.Weekday][]domain.ClockRange{}
+		for _, v := range hours.GetFields()["weekly"].GetListValue().GetValues() {
+			f := v.GetStructValue().GetFields()
+			wd, ok := weekdayNames[f["weekday"].GetStringValue()]
+			if !ok {
+				return domain.Schedule{}, fmt.Errorf("space %s: invalid weekday %q", spaceID, f["weekday"].GetStringValue())
+			}
+			open, err1 := parseClock(f["open"].GetStringValue())
+			closeAt, err2 := parseClock(f["close"].GetStringValue())
+			if err := errors.Join(err1, err2); err != nil {
+				return domain.Schedule{}, fmt.Errorf("space %s: %w", spaceID, err)
+			}
+			sched.Weekly[wd] = append(sched.Weekly[wd], domain.ClockRange{Open: open, Close: closeAt})
+		}
+	}
+	for _, v := range out.GetFields()["blackouts"].GetListValue().GetValues() {
+		f := v.GetStructValue().GetFields()
+		start, err1 := time.Parse(time.RFC3339, f["start"].GetStringValue())
+		end, err2 := time.Parse(time.RFC3339, f["end"].GetStringValue())
+		if err := errors.Join(err1, err2); err != nil {
+			return domain.Schedule{}, fmt.Errorf("space %s: invalid blackout: %w", spaceID, err)
+		}
+		sched.Blackouts = append(sched.Blackouts, domain.Blackout{
+			Interval: domain.Interval{Start: start, End: end},
+			Reason:   f["reason"].GetStringValue(),
+		})
+	}
+	return sched, nil
+}
+
+// parseClock parses "HH:MM" into the offset from midnight.
+func parseClock(s string) (time.Duration, error) {
+	var h, m int
+	if _, err := fmt.Sscanf(s, "%d:%d", &h, &m); err != nil {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
+}
+
+func (c *GRPCClient) getSpace(ctx context.Context, spaceID string) (*structpb.Struct, error) {
+	return c.invoke(ctx, getSpaceMethod, map[string]interface{}{"id": spaceID})
+}
+
+func (c *GRPCClient) invoke(ctx context.Context, method string, req map[string]interface{}) (*structpb.Struct, error) {
+	in, err := structpb.NewStruct(req)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	out := &structpb.Struct{}
+	if err := c.conn.Invoke(ctx, method, in, out); err != nil {
+		return nil, err
+	}
+	if e := out.GetFields()["error"].GetStringValue(); e != "" {
+		// the space service reports domain errors in-band; an unknown space is the caller's mistake
+		if strings.HasSuffix(e, "not found") {
+			return nil, fmt.Errorf("space %w", domain.ErrNotFound)
+		}
+		return nil, errors.New(e)
+	}
+	return out, nil
+}
+
+func (c *GRPCClient) Close() error { return c.conn.Close() }
@@ -0,0 +1,40 @@
+//go:build !grpc
+
+package spaces
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"templespace/cmd/booking/internal/domain"
+)
+
+// GRPCClient is unavailable without the grpc build tag; NewGRPCClient always fails.
+type GRPCClient struct{}
+
+func NewGRPCClient(addr string, timeout time.Duration, currency string) (*GRPCClient, error) {
+	return nil, errors.New("space grpc client requires the grpc build tag")
+}
+
+func (c *GRPCClient) OwnerOf(ctx context.Context, spaceID string) (string, error) {
+	return "", errors.New("space grpc client requires the grpc build tag")
+}
+
+func (c *GRPCClient) PolicyOf(ctx context.Context, spaceID string) (domain.BookingPolicy, error) {
+	return domain.BookingPolicy{}, errors.New("space grpc client requires the grpc build tag")
+}
+
+func (c *GRPCClient) TagsOf(ctx context.Context, spaceID string) ([]string, error) {
+	return nil, errors.New("space grpc client requires the grpc build tag")
+}
+
+func (c *GRPCClient) PricingOf(ctx context.Context, spaceID string) (domain.Pricing, error) {
+	return domain.Pricing{}, errors.New("space grpc client requires the grpc build tag")
+}
+
+func (c *GRPCClient) ScheduleOf(ctx context.Context, spaceID string, from, to time.Time) (domain.Schedule, error) {
+	return domain.Schedule{}, errors.New("space grpc client requires the grpc build tag")
+}
+
+func (c *GRPCClient) Close() error { return nil }
@@ -0,0 +1,158 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"templespace/cmd/booking/internal/domain"
+)
+
+// appendHistory adds the tracked history of b to its event log through db, normally the transaction
+// writing b, and replaces the booking's snapshot when the stream grew past a multiple of
+// domain.SnapshotEvery.
+func appendHistory(ctx context.Context, db dbtx, b *domain.Booking) error {
+	if err := b.CheckHistory(); err != nil {
+		return err
+	}
+	for _, e := range b.History {
+		change, err := json.Marshal(e.Change)
+		if err != nil {
+			return err
+		}
+		if _, err := db.ExecContext(ctx, `
+			INSERT INTO booking_events (booking_id, version, type, actor, at, change) VALUES ($1, $2, $3, $4, $5, $6)`,
+			e.BookingID, e.Version, e.Type, e.Actor, e.At, change); err != nil {
+			return err
+		}
+	}
+	if !domain.SnapshotDue(b.History) {
+		return nil
+	}
+	snap := *b
+	snap.Outbox, snap.History = nil, nil
+	state, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	_, err = db.ExecContext(ctx, `
+		INSERT INTO booking_snapshots (booking_id, version, state, taken_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (booking_id) DO UPDATE SET version = EXCLUDED.version, state = EXCLUDED.state, taken_at = EXCLUDED.taken_at`,
+		b.ID, b.Version, state, b.UpdatedAt)
+	return err
+}
+
+// loadBooking folds the events of booking id that follow its snapshot. Events are never changed once
+// written, so reading the snapshot and the events outside one transaction is consistent.
+func loadBooking(ctx context.Context, db dbtx, id string) (*domain.Booking, error) {
+	var snap *domain.Booking
+	var state []byte
+	err := db.QueryRowContext(ctx, `SELECT state FROM booking_snapshots WHERE booking_id = $1`, id).Scan(&state)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		return nil, err
+	default:
+		snap = &domain.Booking{}
+		if err := json.Unmarshal(state, snap); err != nil {
+			return nil, err
+		}
+	}
+	after := 0
+	if snap != nil {
+		after = snap.Version
+	}
+	events, err := queryHistory(ctx, db, id, after)
+	if err != nil {
+		return nil, err
+	}
+	return domain.Replay(snap, events)
+}
+
+func queryHistory(ctx context.Context, db dbtx, id string, afterVersion int) ([]domain.HistoryEvent, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT booking_id, version, type, actor, at, change FROM booking_events
+		WHERE booking_id = $1 AND version > $2
+		ORDER BY version`, id, afterVersion)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []domain.HistoryEvent
+	for rows.Next() {
+		var e domain.HistoryEvent
+		var change []byte
+		if err := rows.Scan(&e.BookingID, &e.Version, &e.Type, &e.Actor, &e.At, &change); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(change, &e.Change); err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+func (r *PostgresRepo) History(id string) ([]domain.HistoryEvent, error) {
+	events, err := queryHistory(context.Background(), r.db, id, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		return nil, domain.ErrNotFound
+	}
+	return events, nil
+}
+
+// Reproject upserts the row of b unless the stored row is at a later version.
+func (r *PostgresRepo) Reproject(b *domain.Booking) error {
+	blk := b.Blocked()
+	_, err := r.db.ExecContext(context.Background(), `
+		INSERT INTO bookings (id, space_id, user_id, series_id, slot_start, slot_end, block_start, block_end, seats, capacity,
+		                      total_amount, currency, voucher_code, refunded_amount, status, version, hold_expires_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
+		ON CONFLICT (id) DO UPDATE
+		SET space_id = EXCLUDED.space_id, user_id = EXCLUDED.user_id, series_id = EXCLUDED.series_id,
+		    slot_start = EXCLUDED.slot_start, slot_end = EXCLUDED.slot_end, block_start = EXCLUDED.block_start,
+		    block_end = EXCLUDED.block_end, seats = EXCLUDED.seats, capacity = EXCLUDED.capacity,
+		    total_amount = EXCLUDED.total_amount, currency = EXCLUDED.currency, voucher_code = EXCLUDED.voucher_code,
+		    refunded_amount = EXCLUDED.refunded_amount, status = EXCLUDED.status, version = EXCLUDED.version,
+		    hold_expires_at = EXCLUDED.hold_expires_at, created_at = EXCLUDED.created_at, updated_at = EXCLUDED.updated_at
+		WHERE bookings.version <= EXCLUDED.version`,
+		b.ID, b.SpaceID, b.UserID, nullString(b.SeriesID), b.SlotStart, b.SlotEnd, blk.Start, blk.End, b.Seats, b.Capacity,
+		b.Total.Amount, b.Total.Currency, nullString(b.VoucherCode), b.Refunded.Amount, string(b.Status), b.Version,
+		nullTime(b.HoldExpiresAt), b.CreatedAt, b.UpdatedAt)
+	return mapPgError(err)
+}
+
+func (r *PostgresRepo) ReplayAll(fn func(b *domain.Booking) error) error {
+	ctx := context.Background()
+	rows, err := r.db.QueryContext(ctx, `SELECT DISTINCT booking_id FROM booking_events ORDER BY booking_id`)
+	if err != nil {
+		return err
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		b, err := loadBooking(ctx, r.db, id)
+		if err != nil {
+			return err
+		}
+		if err := fn(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
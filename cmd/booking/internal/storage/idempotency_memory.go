@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"sync"
+	"time"
+
+	"templespace/cmd/booking/internal/domain"
+)
+
+type MemoryIdempotency struct {
+	mu    sync.Mutex
+	byKey map[string]*domain.IdempotencyRecord
+}
+
+func NewMemoryIdempotency() *MemoryIdempotency {
+	return &MemoryIdempotency{byKey: map[string]*domain.IdempotencyRecord{}}
+}
+
+func (m *MemoryIdempotency) Claim(rec *domain.IdempotencyRecord) (*domain.IdempotencyRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if cur, ok := m.byKey[rec.Key]; ok && rec.CreatedAt.Before(cur.ExpiresAt) {
+		cp := *cur
+		return &cp, nil
+	}
+	cp := *rec
+	m.byKey[rec.Key] = &cp
+	return nil, nil
+}
+
+func (m *MemoryIdempotency) Complete(key, token string, resp *domain.IdempotentResponse, expiresAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	rec, ok := m.byKey[key]
+	if !ok || rec.Token != token {
+		return domain.ErrIdempotencyClaimLost
+	}
+	rec.Response, rec.ExpiresAt = resp, expiresAt
+	return nil
+}
+
+func (m *MemoryIdempotency) Release(key, token string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if rec, ok := m.byKey[key]; ok && rec.Token == token && rec.Response == nil {
+		delete(m.byKey, key)
+	}
+	return nil
+}
+
+func (m *MemoryIdempotency) Purge(now time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := 0
+	for k, rec := range m.byKey {
+		if !now.Before(rec.ExpiresAt) {
+			delete(m.byKey, k)
+			n++
+		}
+	}
+	return n, nil
+}
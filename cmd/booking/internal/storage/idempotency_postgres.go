@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"templespace/cmd/booking/internal/domain"
+)
+
+// PostgresIdempotency keeps idempotency keys in Postgres so every instance sees the same claims.
+type PostgresIdempotency struct {
+	db *sql.DB
+}
+
+func NewPostgresIdempotency(db *sql.DB) *PostgresIdempotency {
+	return &PostgresIdempotency{db: db}
+}
+
+func (r *PostgresIdempotency) Claim(rec *domain.IdempotencyRecord) (*domain.IdempotencyRecord, error) {
+	ctx := context.Background()
+	for {
+		// an expired record is taken over in the same statement that would insert a new one
+		res, err := r.db.ExecContext(ctx, `
+			INSERT INTO idempotency_keys (key, token, fingerprint, created_at, expires_at) VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (key) DO UPDATE
+			SET token = EXCLUDED.token, fingerprint = EXCLUDED.fingerprint, status = NULL, header = NULL, body = NULL,
+			    created_at = EXCLUDED.created_at, expires_at = EXCLUDED.expires_at
+			WHERE idempotency_keys.expires_at <= EXCLUDED.created_at`,
+			rec.Key, rec.Token, rec.Fingerprint, rec.CreatedAt, rec.ExpiresAt)
+		if err != nil {
+			return nil, err
+		}
+		if n, err := res.RowsAffected(); err != nil || n == 1 {
+			return nil, err
+		}
+		cur, err := r.get(ctx, rec.Key)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue // released between the insert and the read
+		}
+		return cur, err
+	}
+}
+
+func (r *PostgresIdempotency) get(ctx context.Context, key string) (*domain.IdempotencyRecord, error) {
+	var rec domain.IdempotencyRecord
+	var status sql.NullInt64
+	var header []byte
+	var body []byte
+	err := r.db.QueryRowContext(ctx, `
+		SELECT key, token, fingerprint, status, header, body, created_at, expires_at FROM idempotency_keys WHERE key = $1`, key).
+		Scan(&rec.Key, &rec.Token, &rec.Fingerprint, &status, &header, &body, &rec.CreatedAt, &rec.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if status.Valid {
+		rec.Response = &domain.IdempotentResponse{Status: int(status.Int64), Body: body}
+		if err := json.Unmarshal(header, &rec.Response.Header); err != nil {
+			return nil, err
+		}
+	}
+	return &rec, nil
+}
+
+func (r *PostgresIdempotency) Complete(key, token string, resp *domain.IdempotentResponse, expiresAt time.Time) error {
+	header, _ := json.Marshal(resp.Header)
+	res, err := r.db.ExecContext(context.Background(),
+		`UPDATE idempotency_keys SET status = $3, header = $4, body = $5, expires_at = $6 WHERE key = $1 AND token = $2`,
+		key, token, resp.Status, header, resp.Body, expiresAt)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return domain.ErrIdempotencyClaimLost
+	}
+	return nil
+}
+
+func (r *PostgresIdempotency) Release(key, token string) error {
+	_, err := r.db.ExecContext(context.Background(),
+		`DELETE FROM idempotency_keys WHERE key = $1 AND token = $2 AND status IS NULL`, key, token)
+	return err
+}
+
+func (r *PostgresIdempotency) Purge(now time.Time) (int, error) {
+	res, err := r.db.ExecContext(context.Background(), `DELETE FROM idempotency_keys WHERE expires_at <= $1`, now)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
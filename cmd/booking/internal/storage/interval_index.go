@@ -0,0 +1,152 @@
+package storage
+
+import (
+	"hash/maphash"
+	"time"
+)
+
+// slotEntry is the blocked interval of one slot-holding booking in a spaceIndex.
+type slotEntry struct {
+	start time.Time
+	end   time.Time
+	id    string
+}
+
+func (e slotEntry) less(o slotEntry) bool {
+	if !e.start.Equal(o.start) {
+		return e.start.Before(o.start)
+	}
+	return e.id < o.id
+}
+
+// spaceIndex is an interval tree over the slot-holding bookings of one space: a treap ordered by
+// start whose nodes also carry the latest end in their subtree. Inserts and removals take expected
+// logarithmic time, and an overlap query skips every subtree that ends before the query starts or
+// starts after it ends, so it costs a logarithm plus the number of overlapping bookings.
+type spaceIndex struct {
+	root *intervalNode
+}
+
+type intervalNode struct {
+	entry slotEntry
+	// priority orders the treap as a heap; a hash of the booking ID, so it is random but stable.
+	priority    uint64
+	maxEnd      time.Time
+	left, right *intervalNode
+}
+
+func (n *intervalNode) update() {
+	n.maxEnd = n.entry.end
+	for _, c := range [2]*intervalNode{n.left, n.right} {
+		if c != nil && c.maxEnd.After(n.maxEnd) {
+			n.maxEnd = c.maxEnd
+		}
+	}
+}
+
+var prioritySeed = maphash.MakeSeed()
+
+func priority(id string) uint64 { return maphash.String(prioritySeed, id) }
+
+func (ix *spaceIndex) insert(e slotEntry) {
+	ix.root = insertNode(ix.root, &intervalNode{entry: e, priority: priority(e.id), maxEnd: e.end})
+}
+
+func insertNode(n, x *intervalNode) *intervalNode {
+	if n == nil {
+		return x
+	}
+	if x.entry.less(n.entry) {
+		n.left = insertNode(n.left, x)
+		if n.left.priority > n.priority {
+			n = rotateRight(n)
+		}
+	} else {
+		n.right = insertNode(n.right, x)
+		if n.right.priority > n.priority {
+			n = rotateLeft(n)
+		}
+	}
+	n.update()
+	return n
+}
+
+func (ix *spaceIndex) remove(e slotEntry) {
+	ix.root = removeNode(ix.root, e)
+}
+
+func removeNode(n *intervalNode, e slotEntry) *intervalNode {
+	if n == nil {
+		return nil
+	}
+	switch {
+	case e.less(n.entry):
+		n.left = removeNode(n.left, e)
+	case n.entry.less(e):
+		n.right = removeNode(n.right, e)
+	default:
+		return merge(n.left, n.right)
+	}
+	n.update()
+	return n
+}
+
+// merge joins two treaps whose keys are all ordered left before right.
+func merge(l, r *intervalNode) *intervalNode {
+	switch {
+	case l == nil:
+		return r
+	case r == nil:
+		return l
+	case l.priority > r.priority:
+		l.right = merge(l.right, r)
+		l.update()
+		return l
+	default:
+		r.left = merge(l, r.left)
+		r.update()
+		return r
+	}
+}
+
+func rotateRight(n *intervalNode) *intervalNode {
+	l := n.left
+	n.left, l.right = l.right, n
+	n.update()
+	l.update()
+	return l
+}
+
+func rotateLeft(n *intervalNode) *intervalNode {
+	r := n.right
+	n.right, r.left = r.left, n
+	n.update()
+	r.update()
+	return r
+}
+
+// overlapping calls fn for each entry overlapping [start, end), in start order, until fn returns
+// false.
+func (ix *spaceIndex) overlapping(start, end time.Time, fn func(slotEntry) bool) {
+	if ix == nil || !start.Before(end) {
+		return
+	}
+	visit(ix.root, start, end, fn)
+}
+
+func visit(n *intervalNode, start, end time.Time, fn func(slotEntry) bool) bool {
+	if n == nil || !n.maxEnd.After(start) {
+		return true
+	}
+	if !visit(n.left, start, end, fn) {
+		return false
+	}
+	if !n.entry.start.Before(end) {
+		// this node and its right subtree start at or after end
+		return true
+	}
+	if overlaps(n.entry.start, n.entry.end, start, end) && !fn(n.entry) {
+		return false
+	}
+	return visit(n.right, start, end, fn)
+}
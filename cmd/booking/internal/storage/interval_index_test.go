@@ -0,0 +1,142 @@
+package storage
+
+import (
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"templespace/cmd/booking/internal/domain"
+)
+
+// TestSpaceIndexMatchesScan checks overlap queries against a linear scan while bookings of very
+// different lengths come and go.
+func TestSpaceIndexMatchesScan(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	var ix spaceIndex
+	live := map[string]slotEntry{}
+	random := func(maxHours int) (time.Time, time.Time) {
+		start := base.Add(time.Duration(rnd.Intn(24*60)) * time.Hour)
+		return start, start.Add(time.Duration(1+rnd.Intn(maxHours*60)) * time.Minute)
+	}
+	for i := 0; i < 5000; i++ {
+		switch op := rnd.Intn(10); {
+		case op < 5 || len(live) == 0:
+			maxHours := 4
+			if rnd.Intn(50) == 0 {
+				maxHours = 24 * 30 // the odd month-long booking
+			}
+			start, end := random(maxHours)
+			e := slotEntry{start: start, end: end, id: strconv.Itoa(i)}
+			ix.insert(e)
+			live[e.id] = e
+		case op < 8:
+			for id, e := range live {
+				ix.remove(e)
+				delete(live, id)
+				break
+			}
+		default:
+			start, end := random(8)
+			var got, want []string
+			ix.overlapping(start, end, func(e slotEntry) bool {
+				got = append(got, e.id)
+				return true
+			})
+			for _, e := range live {
+				if overlaps(e.start, e.end, start, end) {
+					want = append(want, e.id)
+				}
+			}
+			sort.Strings(got)
+			sort.Strings(want)
+			if strings.Join(got, ",") != strings.Join(want, ",") {
+				t.Fatalf("step %d: overlapping [%s, %s) = %v, want %v", i, start, end, got, want)
+			}
+		}
+	}
+}
+
+// TestSpaceIndexForgetsRemovedSpans checks that a removed long booking no longer widens queries.
+func TestSpaceIndexForgetsRemovedSpans(t *testing.T) {
+	var ix spaceIndex
+	long := slotEntry{start: base, end: base.Add(365 * 24 * time.Hour), id: "long"}
+	ix.insert(long)
+	for i := 0; i < 100; i++ {
+		start := base.Add(time.Duration(i) * time.Hour)
+		ix.insert(slotEntry{start: start, end: start.Add(time.Hour), id: strconv.Itoa(i)})
+	}
+	ix.remove(long)
+	if got := ix.root.maxEnd; !got.Equal(base.Add(100 * time.Hour)) {
+		t.Fatalf("maxEnd %s after removing the long booking", got)
+	}
+	visited := 0
+	ix.overlapping(base.Add(200*time.Hour), base.Add(201*time.Hour), func(slotEntry) bool {
+		visited++
+		return true
+	})
+	if visited != 0 {
+		t.Fatalf("%d bookings overlap a free hour", visited)
+	}
+}
+
+const benchBookings = 100_000
+
+// benchIndex indexes benchBookings one-hour bookings back to back, plus a year-long one that is
+// removed again and must no longer widen queries.
+func benchIndex() *spaceIndex {
+	ix := &spaceIndex{}
+	long := slotEntry{start: base, end: base.Add(365 * 24 * time.Hour), id: "long"}
+	ix.insert(long)
+	for i := 0; i < benchBookings; i++ {
+		start := base.Add(time.Duration(i) * time.Hour)
+		ix.insert(slotEntry{start: start, end: start.Add(time.Hour), id: strconv.Itoa(i)})
+	}
+	ix.remove(long)
+	return ix
+}
+
+// BenchmarkSpaceIndexInsertRemove adds a booking to a full index and takes it out again.
+func BenchmarkSpaceIndexInsertRemove(b *testing.B) {
+	ix := benchIndex()
+	rnd := rand.New(rand.NewSource(1))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		start := base.Add(time.Duration(rnd.Intn(benchBookings)) * time.Hour)
+		e := slotEntry{start: start, end: start.Add(time.Hour), id: "new"}
+		ix.insert(e)
+		ix.remove(e)
+	}
+}
+
+func BenchmarkSpaceIndexOverlapping(b *testing.B) {
+	ix := benchIndex()
+	rnd := rand.New(rand.NewSource(1))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		start := base.Add(time.Duration(rnd.Intn(benchBookings)) * time.Hour)
+		ix.overlapping(start, start.Add(90*time.Minute), func(slotEntry) bool { return true })
+	}
+}
+
+// BenchmarkMemoryRepoCreate books one more slot in a space holding benchBookings bookings.
+func BenchmarkMemoryRepoCreate(b *testing.B) {
+	repo := NewMemoryRepo()
+	for i := 0; i < benchBookings; i++ {
+		if err := repo.CreateIfAvailable(newBooking("s1", i, i+1)); err != nil {
+			b.Fatal(err)
+		}
+	}
+	bookings := make([]*domain.Booking, b.N)
+	for i := range bookings {
+		bookings[i] = newBooking("s1", benchBookings+i, benchBookings+i+1)
+	}
+	b.ResetTimer()
+	for _, bk := range bookings {
+		if err := repo.CreateIfAvailable(bk); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+CREATE TABLE IF NOT EXISTS bookings (
+    id TEXT PRIMARY KEY,
+    space_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    slot_start TIMESTAMPTZ NOT NULL,
+    slot_end TIMESTAMPTZ NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('pending','confirmed','paid','cancelled')),
+    version INT NOT NULL DEFAULT 1,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (slot_start < slot_end)
+);
+
+-- Two live bookings of one space can never overlap, whatever the number of concurrent writers.
+ALTER TABLE bookings ADD CONSTRAINT bookings_no_overlap
+    EXCLUDE USING gist (space_id WITH =, tstzrange(slot_start, slot_end, '[)') WITH &&)
+    WHERE (status <> 'cancelled');
+
+CREATE INDEX IF NOT EXISTS bookings_user_id_idx ON bookings (user_id);
@@ -0,0 +1,9 @@
+-- Statuses added by the booking state machine; expired holds release their slot like cancellations.
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_status_check;
+ALTER TABLE bookings ADD CONSTRAINT bookings_status_check
+    CHECK (status IN ('pending','confirmed','paid','cancelled','expired','completed','no_show'));
+
+ALTER TABLE bookings DROP CONSTRAINT bookings_no_overlap;
+ALTER TABLE bookings ADD CONSTRAINT bookings_no_overlap
+    EXCLUDE USING gist (space_id WITH =, tstzrange(slot_start, slot_end, '[)') WITH &&)
+    WHERE (status NOT IN ('cancelled', 'expired'));
@@ -0,0 +1,4 @@
+-- Unpaid pending bookings release their slot once the hold expires.
+ALTER TABLE bookings ADD COLUMN hold_expires_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS bookings_pending_holds_idx ON bookings (hold_expires_at) WHERE status = 'pending';
@@ -0,0 +1,4 @@
+-- Occurrences of a recurring booking share a series id.
+ALTER TABLE bookings ADD COLUMN series_id TEXT;
+
+CREATE INDEX IF NOT EXISTS bookings_series_id_idx ON bookings (series_id, slot_start) WHERE series_id IS NOT NULL;
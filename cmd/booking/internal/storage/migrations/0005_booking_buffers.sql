@@ -0,0 +1,12 @@
+-- Setup and teardown buffers widen the interval a booking blocks; overlap is checked on that interval.
+ALTER TABLE bookings ADD COLUMN block_start TIMESTAMPTZ;
+ALTER TABLE bookings ADD COLUMN block_end TIMESTAMPTZ;
+UPDATE bookings SET block_start = slot_start, block_end = slot_end;
+ALTER TABLE bookings ALTER COLUMN block_start SET NOT NULL;
+ALTER TABLE bookings ALTER COLUMN block_end SET NOT NULL;
+ALTER TABLE bookings ADD CONSTRAINT bookings_block_check CHECK (block_start <= slot_start AND block_end >= slot_end);
+
+ALTER TABLE bookings DROP CONSTRAINT bookings_no_overlap;
+ALTER TABLE bookings ADD CONSTRAINT bookings_no_overlap
+    EXCLUDE USING gist (space_id WITH =, tstzrange(block_start, block_end, '[)') WITH &&)
+    WHERE (status NOT IN ('cancelled', 'expired'));
@@ -0,0 +1,9 @@
+-- Shared spaces take overlapping bookings up to their capacity. Seat sums are checked by the
+-- repository under a per-space advisory lock; the exclusion constraint still guards exclusive bookings.
+ALTER TABLE bookings ADD COLUMN seats INT NOT NULL DEFAULT 1 CHECK (seats >= 1);
+ALTER TABLE bookings ADD COLUMN capacity INT NOT NULL DEFAULT 0 CHECK (capacity >= 0);
+
+ALTER TABLE bookings DROP CONSTRAINT bookings_no_overlap;
+ALTER TABLE bookings ADD CONSTRAINT bookings_no_overlap
+    EXCLUDE USING gist (space_id WITH =, tstzrange(block_start, block_end, '[)') WITH &&)
+    WHERE (capacity = 0 AND status NOT IN ('cancelled', 'expired'));
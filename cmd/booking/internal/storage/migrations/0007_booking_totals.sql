@@ -0,0 +1,3 @@
+-- Quoted price stored with each booking, in the minor unit of its currency.
+ALTER TABLE bookings ADD COLUMN total_amount BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE bookings ADD COLUMN currency TEXT NOT NULL DEFAULT '';
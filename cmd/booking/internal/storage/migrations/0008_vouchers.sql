@@ -0,0 +1,29 @@
+-- Discount codes and their use by bookings. A released redemption (booking cancelled or expired)
+-- no longer counts towards the limits of its voucher.
+CREATE TABLE vouchers (
+    code TEXT PRIMARY KEY,
+    kind TEXT NOT NULL CHECK (kind IN ('percent', 'fixed')),
+    percent DOUBLE PRECISION NOT NULL DEFAULT 0,
+    amount BIGINT NOT NULL DEFAULT 0,
+    currency TEXT NOT NULL DEFAULT '',
+    valid_from TIMESTAMPTZ,
+    valid_until TIMESTAMPTZ,
+    max_redemptions INT NOT NULL DEFAULT 0,
+    max_per_user INT NOT NULL DEFAULT 0,
+    space_ids JSONB NOT NULL DEFAULT '[]',
+    tags JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE voucher_redemptions (
+    booking_id TEXT PRIMARY KEY,
+    code TEXT NOT NULL REFERENCES vouchers (code),
+    user_id TEXT NOT NULL,
+    discount BIGINT NOT NULL,
+    currency TEXT NOT NULL,
+    redeemed_at TIMESTAMPTZ NOT NULL,
+    released_at TIMESTAMPTZ
+);
+CREATE INDEX voucher_redemptions_active ON voucher_redemptions (code, user_id) WHERE released_at IS NULL;
+
+ALTER TABLE bookings ADD COLUMN voucher_code TEXT;
@@ -0,0 +1,18 @@
+-- Payment intents opened at the provider and the provider events already applied, so webhook
+-- redeliveries are acknowledged without effect.
+CREATE TABLE payment_intents (
+    id TEXT PRIMARY KEY,
+    booking_id TEXT NOT NULL REFERENCES bookings (id),
+    amount BIGINT NOT NULL,
+    currency TEXT NOT NULL,
+    client_secret TEXT NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('requires_payment', 'succeeded', 'failed')),
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX payment_intents_booking ON payment_intents (booking_id, created_at);
+
+CREATE TABLE payment_events (
+    id TEXT PRIMARY KEY,
+    processed_at TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,2 @@
+-- Amount paid back when a paid booking is cancelled, in the minor unit of the booking's currency.
+ALTER TABLE bookings ADD COLUMN refunded_amount BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,12 @@
+-- Responses of booking mutations sent with an Idempotency-Key, replayed to retries until they expire.
+-- status is NULL while the first request is in flight.
+CREATE TABLE idempotency_keys (
+    key TEXT PRIMARY KEY,
+    fingerprint TEXT NOT NULL,
+    status INT,
+    header JSONB,
+    body BYTEA,
+    created_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX idempotency_keys_expires ON idempotency_keys (expires_at);
@@ -0,0 +1,14 @@
+-- Events written in the same transaction as the booking change they report; the relay publishes
+-- them in seq order and deletes them.
+CREATE TABLE outbox (
+    seq BIGSERIAL PRIMARY KEY,
+    topic TEXT NOT NULL,
+    key TEXT NOT NULL,
+    payload BYTEA NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ,
+    last_error TEXT
+);
+
+CREATE INDEX outbox_deferred_idx ON outbox (key) WHERE next_attempt_at IS NOT NULL;
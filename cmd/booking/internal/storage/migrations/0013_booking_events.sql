@@ -0,0 +1,40 @@
+-- Append-only history of every booking; folding a booking's events gives its current state, which
+-- the bookings table keeps as a projection for the overlap queries. change holds the JSON of
+-- domain.BookingChange.
+CREATE TABLE booking_events (
+    booking_id TEXT NOT NULL,
+    version INT NOT NULL,
+    type TEXT NOT NULL,
+    actor TEXT NOT NULL DEFAULT '',
+    at TIMESTAMPTZ NOT NULL,
+    change JSONB NOT NULL,
+    PRIMARY KEY (booking_id, version)
+);
+
+-- Latest snapshot per booking, taken every domain.SnapshotEvery events; state is a domain.Booking.
+CREATE TABLE booking_snapshots (
+    booking_id TEXT PRIMARY KEY,
+    version INT NOT NULL,
+    state JSONB NOT NULL,
+    taken_at TIMESTAMPTZ NOT NULL
+);
+
+-- Bookings stored before the log start their stream with an imported event holding them as they are.
+INSERT INTO booking_events (booking_id, version, type, actor, at, change)
+SELECT id, version, 'imported', '', updated_at, jsonb_strip_nulls(jsonb_build_object(
+    'space_id', space_id,
+    'user_id', user_id,
+    'series_id', series_id,
+    'slot_start', slot_start,
+    'slot_end', slot_end,
+    'buffer_before', (EXTRACT(EPOCH FROM slot_start - block_start) * 1000000000)::BIGINT,
+    'buffer_after', (EXTRACT(EPOCH FROM block_end - slot_end) * 1000000000)::BIGINT,
+    'seats', seats,
+    'capacity', capacity,
+    'total', jsonb_build_object('Amount', total_amount, 'Currency', currency),
+    'voucher_code', voucher_code,
+    'refunded', CASE WHEN refunded_amount <> 0 THEN jsonb_build_object('Amount', refunded_amount, 'Currency', currency) END,
+    'status', status,
+    'hold_expires_at', hold_expires_at,
+    'created_at', created_at))
+FROM bookings;
@@ -0,0 +1,3 @@
+-- Each claim of a key gets a token, so a request that lost its claim to a retry cannot complete or
+-- release the retry's claim.
+ALTER TABLE idempotency_keys ADD COLUMN token TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"templespace/cmd/booking/internal/domain"
+)
+
+// MemoryOutbox keeps unpublished events in memory. MemoryRepo appends to it under its own lock, so a
+// booking write and its events become visible together.
+type MemoryOutbox struct {
+	mu      sync.Mutex
+	nextSeq int64
+	pending []domain.OutboxMessage
+}
+
+func NewMemoryOutbox() *MemoryOutbox {
+	return &MemoryOutbox{}
+}
+
+func (o *MemoryOutbox) Append(ctx context.Context, msgs ...domain.OutboxMessage) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for _, m := range msgs {
+		o.nextSeq++
+		m.Seq = o.nextSeq
+		o.pending = append(o.pending, m)
+	}
+	return nil
+}
+
+func (o *MemoryOutbox) Pending(ctx context.Context, limit int) ([]domain.OutboxMessage, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	now := time.Now()
+	waiting := map[string]bool{}
+	for _, m := range o.pending {
+		if m.NextAttemptAt.After(now) {
+			waiting[m.Key] = true
+		}
+	}
+	var out []domain.OutboxMessage
+	for _, m := range o.pending {
+		if len(out) == limit {
+			break
+		}
+		if !waiting[m.Key] {
+			out = append(out, m)
+		}
+	}
+	return out, nil
+}
+
+func (o *MemoryOutbox) MarkPublished(ctx context.Context, seqs ...int64) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	done := make(map[int64]bool, len(seqs))
+	for _, s := range seqs {
+		done[s] = true
+	}
+	kept := o.pending[:0]
+	for _, m := range o.pending {
+		if !done[m.Seq] {
+			kept = append(kept, m)
+		}
+	}
+	o.pending = kept
+	return nil
+}
+
+func (o *MemoryOutbox) MarkFailed(ctx context.Context, seq int64, reason string, retryAt time.Time) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for i := range o.pending {
+		if o.pending[i].Seq == seq {
+			o.pending[i].Attempts++
+			o.pending[i].LastError = reason
+			o.pending[i].NextAttemptAt = retryAt
+		}
+	}
+	return nil
+}
+
+func (o *MemoryOutbox) Stats(ctx context.Context) (domain.OutboxStats, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	st := domain.OutboxStats{Pending: len(o.pending)}
+	if len(o.pending) > 0 {
+		st.Oldest = o.pending[0].CreatedAt
+	}
+	return st, nil
+}
@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"templespace/cmd/booking/internal/domain"
+)
+
+// outboxLockID is the session advisory lock held by the instance that relays the outbox, so events
+// of one booking are never published by two relays out of order.
+const outboxLockID = 7261003
+
+// PostgresOutbox reads the outbox table that PostgresRepo writes in the same transaction as bookings.
+// Only the instance holding outboxLockID sees pending messages.
+type PostgresOutbox struct {
+	db *sql.DB
+
+	mu   sync.Mutex
+	conn *sql.Conn // holds outboxLockID while this instance relays
+}
+
+func NewPostgresOutbox(db *sql.DB) *PostgresOutbox {
+	return &PostgresOutbox{db: db}
+}
+
+// insertOutbox stores the recorded events of b through db, normally the transaction writing b.
+func insertOutbox(ctx context.Context, db dbtx, b *domain.Booking) error {
+	for _, m := range b.Outbox {
+		if _, err := db.ExecContext(ctx, `INSERT INTO outbox (topic, key, payload, created_at) VALUES ($1, $2, $3, $4)`,
+			m.Topic, m.Key, m.Payload, m.CreatedAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *PostgresOutbox) Append(ctx context.Context, msgs ...domain.OutboxMessage) error {
+	return insertOutbox(ctx, o.db, &domain.Booking{Outbox: msgs})
+}
+
+// leader reports whether this instance holds the relay lock, taking it when it is free. The lock
+// lives as long as the dedicated connection, so another instance takes over if this one dies.
+func (o *PostgresOutbox) leader(ctx context.Context) (bool, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.conn != nil {
+		if o.conn.PingContext(ctx) == nil {
+			return true, nil
+		}
+		_ = o.conn.Close()
+		o.conn = nil
+	}
+	conn, err := o.db.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+	var ok bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, outboxLockID).Scan(&ok); err != nil || !ok {
+		_ = conn.Close()
+		return false, err
+	}
+	o.conn = conn
+	return true, nil
+}
+
+func (o *PostgresOutbox) Pending(ctx context.Context, limit int) ([]domain.OutboxMessage, error) {
+	if ok, err := o.leader(ctx); !ok {
+		return nil, err
+	}
+	rows, err := o.db.QueryContext(ctx, `
+		SELECT seq, topic, key, payload, created_at, attempts, next_attempt_at, last_error FROM outbox
+		WHERE key NOT IN (SELECT key FROM outbox WHERE next_attempt_at > now())
+		ORDER BY seq LIMIT $1`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []domain.OutboxMessage
+	for rows.Next() {
+		var m domain.OutboxMessage
+		var next sql.NullTime
+		var lastErr sql.NullString
+		if err := rows.Scan(&m.Seq, &m.Topic, &m.Key, &m.Payload, &m.CreatedAt, &m.Attempts, &next, &lastErr); err != nil {
+			return nil, err
+		}
+		m.NextAttemptAt = next.Time
+		m.LastError = lastErr.String
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
+
+func (o *PostgresOutbox) MarkPublished(ctx context.Context, seqs ...int64) error {
+	if len(seqs) == 0 {
+		return nil
+	}
+	_, err := o.db.ExecContext(ctx, `DELETE FROM outbox WHERE seq = ANY($1)`, seqs)
+	return err
+}
+
+func (o *PostgresOutbox) MarkFailed(ctx context.Context, seq int64, reason string, retryAt time.Time) error {
+	_, err := o.db.ExecContext(ctx, `
+		UPDATE outbox SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3 WHERE seq = $1`,
+		seq, reason, retryAt)
+	return err
+}
+
+func (o *PostgresOutbox) Stats(ctx context.Context) (domain.OutboxStats, error) {
+	var st domain.OutboxStats
+	var oldest sql.NullTime
+	err := o.db.QueryRowContext(ctx, `SELECT count(*), min(created_at) FROM outbox`).Scan(&st.Pending, &oldest)
+	st.Oldest = oldest.Time
+	return st, err
+}
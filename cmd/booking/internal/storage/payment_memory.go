@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"sync"
+	"time"
+
+	"templespace/cmd/booking/internal/domain"
+)
+
+type MemoryPayments struct {
+	mu      sync.RWMutex
+	intents map[string]*domain.PaymentIntent
+	// latest maps a booking to its most recent intent
+	latest map[string]string
+	events map[string]time.Time
+}
+
+func NewMemoryPayments() *MemoryPayments {
+	return &MemoryPayments{
+		intents: map[string]*domain.PaymentIntent{},
+		latest:  map[string]string{},
+		events:  map[string]time.Time{},
+	}
+}
+
+func (m *MemoryPayments) SaveIntent(pi *domain.PaymentIntent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cp := *pi
+	if _, ok := m.intents[pi.ID]; !ok {
+		m.latest[pi.BookingID] = pi.ID
+	}
+	m.intents[pi.ID] = &cp
+	return nil
+}
+
+func (m *MemoryPayments) GetIntent(id string) (*domain.PaymentIntent, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	pi, ok := m.intents[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	cp := *pi
+	return &cp, nil
+}
+
+func (m *MemoryPayments) LatestIntent(bookingID string) (*domain.PaymentIntent, error) {
+	m.mu.RLock()
+	id, ok := m.latest[bookingID]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return m.GetIntent(id)
+}
+
+func (m *MemoryPayments) RecordEvent(id string, at time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.events[id]; ok {
+		return false, nil
+	}
+	m.events[id] = at
+	return true, nil
+}
+
+func (m *MemoryPayments) ForgetEvent(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.events, id)
+	return nil
+}
@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"templespace/cmd/booking/internal/domain"
+)
+
+// PostgresPayments stores payment intents and the ids of handled provider events.
+type PostgresPayments struct {
+	db *sql.DB
+}
+
+func NewPostgresPayments(db *sql.DB) *PostgresPayments {
+	return &PostgresPayments{db: db}
+}
+
+const intentColumns = `id, booking_id, amount, currency, client_secret, status, created_at, updated_at`
+
+func (r *PostgresPayments) SaveIntent(pi *domain.PaymentIntent) error {
+	_, err := r.db.ExecContext(context.Background(), `
+		INSERT INTO payment_intents (`+intentColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (id) DO UPDATE SET status = EXCLUDED.status, updated_at = EXCLUDED.updated_at`,
+		pi.ID, pi.BookingID, pi.Amount.Amount, pi.Amount.Currency, pi.ClientSecret, string(pi.Status), pi.CreatedAt, pi.UpdatedAt)
+	return err
+}
+
+func (r *PostgresPayments) GetIntent(id string) (*domain.PaymentIntent, error) {
+	return r.getIntent(`SELECT `+intentColumns+` FROM payment_intents WHERE id = $1`, id)
+}
+
+func (r *PostgresPayments) LatestIntent(bookingID string) (*domain.PaymentIntent, error) {
+	return r.getIntent(`SELECT `+intentColumns+` FROM payment_intents WHERE booking_id = $1
+		ORDER BY created_at DESC, id DESC LIMIT 1`, bookingID)
+}
+
+func (r *PostgresPayments) getIntent(query, arg string) (*domain.PaymentIntent, error) {
+	var pi domain.PaymentIntent
+	var status string
+	err := r.db.QueryRowContext(context.Background(), query, arg).Scan(&pi.ID, &pi.BookingID, &pi.Amount.Amount,
+		&pi.Amount.Currency, &pi.ClientSecret, &status, &pi.CreatedAt, &pi.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	pi.Status = domain.PaymentStatus(status)
+	return &pi, nil
+}
+
+func (r *PostgresPayments) RecordEvent(id string, at time.Time) (bool, error) {
+	res, err := r.db.ExecContext(context.Background(),
+		`INSERT INTO payment_events (id, processed_at) VALUES ($1, $2) ON CONFLICT (id) DO NOTHING`, id, at)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+func (r *PostgresPayments) ForgetEvent(id string) error {
+	_, err := r.db.ExecContext(context.Background(), `DELETE FROM payment_events WHERE id = $1`, id)
+	return err
+}
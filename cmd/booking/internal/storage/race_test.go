@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"templespace/cmd/booking/internal/domain"
+)
+
+// TestConcurrentCreatesOneWins races overlapping bookings of one slot; exactly one may be stored.
+func TestConcurrentCreatesOneWins(t *testing.T) {
+	const racers = 50
+	forEachBackend(t, func(t *testing.T, repo domain.BookingRepository) {
+		for round := 0; round < 5; round++ {
+			from := round * 10
+			var (
+				wg             sync.WaitGroup
+				start          = make(chan struct{})
+				mu             sync.Mutex
+				won, lost      int
+				unexpectedErrs []error
+			)
+			for i := 0; i < racers; i++ {
+				// every booking overlaps all others, each in a slightly different way
+				b := newBooking("s1", from+i%3, from+3+i%2)
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					<-start
+					err := repo.CreateIfAvailable(b)
+					mu.Lock()
+					defer mu.Unlock()
+					switch {
+					case err == nil:
+						won++
+					case errors.Is(err, domain.ErrSlotNotAvailable):
+						lost++
+					default:
+						unexpectedErrs = append(unexpectedErrs, err)
+					}
+				}()
+			}
+			close(start)
+			wg.Wait()
+			if len(unexpectedErrs) > 0 {
+				t.Fatalf("round %d: unexpected errors %v", round, unexpectedErrs)
+			}
+			if won != 1 || lost != racers-1 {
+				t.Fatalf("round %d: %d won, %d lost, want 1 and %d", round, won, lost, racers-1)
+			}
+			live, err := repo.ListOverlapping("s1", base.Add(time.Duration(from)*time.Hour), base.Add(time.Duration(from+5)*time.Hour))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(live) != 1 {
+				t.Fatalf("round %d: %d bookings stored", round, len(live))
+			}
+		}
+	})
+}
+
+// TestConcurrentSharedSeats races single-seat bookings of a shared space; exactly capacity fit.
+func TestConcurrentSharedSeats(t *testing.T) {
+	const racers, capacity = 40, 7
+	forEachBackend(t, func(t *testing.T, repo domain.BookingRepository) {
+		var (
+			wg    sync.WaitGroup
+			start = make(chan struct{})
+			mu    sync.Mutex
+			won   int
+		)
+		for i := 0; i < racers; i++ {
+			b := shared(newBooking("s1", i%2, 2+i%2), 1, capacity)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-start
+				err := repo.CreateIfAvailable(b)
+				if err != nil && !errors.Is(err, domain.ErrSlotNotAvailable) {
+					t.Error(err)
+				}
+				if err == nil {
+					mu.Lock()
+					won++
+					mu.Unlock()
+				}
+			}()
+		}
+		close(start)
+		wg.Wait()
+		if won != capacity {
+			t.Fatalf("%d bookings of one seat stored, want %d", won, capacity)
+		}
+	})
+}
@@ -0,0 +1,520 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"templespace/cmd/booking/internal/domain"
+)
+
+// postgresEnv names the database the suites also run against, e.g.
+// postgres://localhost:5432/templespace_test?sslmode=disable. Its booking tables are emptied by
+// every test, so never point it at a database you care about.
+const postgresEnv = "BOOKING_TEST_POSTGRES_URL"
+
+var (
+	pgOnce sync.Once
+	pgDB   *sql.DB
+	pgErr  error
+)
+
+// backends returns a constructor per repository under test; Postgres is left out unless
+// postgresEnv is set. Each call of a constructor starts from an empty store.
+func backends(t testing.TB) map[string]func(t testing.TB) domain.BookingRepository {
+	out := map[string]func(t testing.TB) domain.BookingRepository{
+		"memory": func(testing.TB) domain.BookingRepository { return NewMemoryRepo() },
+	}
+	url := os.Getenv(postgresEnv)
+	if url == "" {
+		t.Logf("%s not set: postgres repository not tested", postgresEnv)
+		return out
+	}
+	out["postgres"] = func(t testing.TB) domain.BookingRepository {
+		pgOnce.Do(func() { pgDB, pgErr = OpenPostgres(context.Background(), url) })
+		if pgErr != nil {
+			t.Fatal(pgErr)
+		}
+		if _, err := pgDB.Exec(`TRUNCATE bookings, booking_events, booking_snapshots, outbox`); err != nil {
+			t.Fatal(err)
+		}
+		return NewPostgresRepo(pgDB)
+	}
+	return out
+}
+
+// forEachBackend runs test as a subtest against every backend.
+func forEachBackend(t *testing.T, test func(t *testing.T, repo domain.BookingRepository)) {
+	for name, newRepo := range backends(t) {
+		t.Run(name, func(t *testing.T) { test(t, newRepo(t)) })
+	}
+}
+
+var (
+	base   = time.Date(2030, 3, 4, 9, 0, 0, 0, time.UTC)
+	nextID atomic.Int64
+)
+
+// newBooking returns a pending exclusive booking of one seat over [base+from, base+to) hours,
+// with the created event tracked.
+func newBooking(spaceID string, from, to int) *domain.Booking {
+	b := &domain.Booking{
+		ID:        "b-" + strconv.FormatInt(nextID.Add(1), 10),
+		SpaceID:   spaceID,
+		UserID:    "alice",
+		SlotStart: base.Add(time.Duration(from) * time.Hour),
+		SlotEnd:   base.Add(time.Duration(to) * time.Hour),
+		Seats:     1,
+		Total:     domain.Money{Amount: 1500, Currency: "EUR"},
+		Status:    domain.StatusPending,
+		Version:   1,
+		CreatedAt: base,
+		UpdatedAt: base,
+	}
+	b.Track(nil, "alice")
+	return b
+}
+
+// shared makes b a booking of seats in a space of capacity.
+func shared(b *domain.Booking, seats, capacity int) *domain.Booking {
+	b.Seats, b.Capacity = seats, capacity
+	b.History = nil
+	b.Track(nil, "alice")
+	return b
+}
+
+// update applies mutate to b as the service does and writes it conditioned on b's version.
+func update(repo domain.BookingRepository, b *domain.Booking, mutate func(b *domain.Booking)) error {
+	before := *b
+	mutate(b)
+	b.Version++
+	b.UpdatedAt = b.UpdatedAt.Add(time.Minute)
+	b.Track(&before, "alice")
+	return repo.Update(b, before.Version)
+}
+
+func cancel(b *domain.Booking) { b.Status = domain.StatusCancelled }
+
+func sameBooking(t *testing.T, got, want *domain.Booking) {
+	t.Helper()
+	if got.ID != want.ID || got.SpaceID != want.SpaceID || got.UserID != want.UserID || got.SeriesID != want.SeriesID ||
+		!got.SlotStart.Equal(want.SlotStart) || !got.SlotEnd.Equal(want.SlotEnd) ||
+		got.BufferBefore != want.BufferBefore || got.BufferAfter != want.BufferAfter ||
+		got.Seats != want.Seats || got.Capacity != want.Capacity || got.Total != want.Total || got.Refunded != want.Refunded ||
+		got.VoucherCode != want.VoucherCode || got.Status != want.Status || got.Version != want.Version ||
+		!got.HoldExpiresAt.Equal(want.HoldExpiresAt) || !got.CreatedAt.Equal(want.CreatedAt) || !got.UpdatedAt.Equal(want.UpdatedAt) {
+		t.Fatalf("got  %+v\nwant %+v", got, want)
+	}
+}
+
+func TestRepoCreateAndGet(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repo domain.BookingRepository) {
+		b := newBooking("s1", 0, 2)
+		b.SeriesID, b.VoucherCode = "series-1", "SPRING"
+		b.BufferBefore, b.BufferAfter = 15*time.Minute, 30*time.Minute
+		b.HoldExpiresAt = base.Add(15 * time.Minute)
+		b.History = nil
+		b.Track(nil, "alice")
+		want := *b
+		if err := repo.CreateIfAvailable(b); err != nil {
+			t.Fatal(err)
+		}
+		if len(b.History) != 0 || len(b.Outbox) != 0 {
+			t.Errorf("history and outbox not cleared after the write")
+		}
+		got, err := repo.GetByID(b.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sameBooking(t, got, &want)
+		if _, err := repo.GetByID("missing"); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("GetByID(missing) = %v, want ErrNotFound", err)
+		}
+	})
+}
+
+func TestRepoRejectsWriteWithoutHistory(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repo domain.BookingRepository) {
+		b := newBooking("s1", 0, 1)
+		b.History = nil
+		if err := repo.CreateIfAvailable(b); err == nil {
+			t.Fatal("write without history succeeded")
+		}
+		if _, err := repo.GetByID(b.ID); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("booking stored after a rejected write: %v", err)
+		}
+	})
+}
+
+func TestRepoCreateIfAvailable(t *testing.T) {
+	tests := []struct {
+		name   string
+		first  *domain.Booking
+		second *domain.Booking
+		fits   bool
+	}{
+		{"overlap", newBooking("s1", 0, 2), newBooking("s1", 1, 3), false},
+		{"contained", newBooking("s1", 0, 4), newBooking("s1", 1, 2), false},
+		{"adjacent", newBooking("s1", 0, 2), newBooking("s1", 2, 4), true},
+		{"other space", newBooking("s1", 0, 2), newBooking("s2", 0, 2), true},
+		{"shared within capacity", shared(newBooking("s1", 0, 2), 2, 4), shared(newBooking("s1", 1, 3), 2, 4), true},
+		{"shared over capacity", shared(newBooking("s1", 0, 2), 3, 4), shared(newBooking("s1", 1, 3), 2, 4), false},
+		{"exclusive next to shared", shared(newBooking("s1", 0, 2), 1, 4), newBooking("s1", 1, 3), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forEachBackend(t, func(t *testing.T, repo domain.BookingRepository) {
+				first, second := *tt.first, *tt.second
+				if err := repo.CreateIfAvailable(&first); err != nil {
+					t.Fatal(err)
+				}
+				err := repo.CreateIfAvailable(&second)
+				if tt.fits && err != nil {
+					t.Fatalf("second booking: %v", err)
+				}
+				if !tt.fits && !errors.Is(err, domain.ErrSlotNotAvailable) {
+					t.Fatalf("second booking: got %v, want ErrSlotNotAvailable", err)
+				}
+			})
+		})
+	}
+}
+
+func TestRepoBuffersBlock(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repo domain.BookingRepository) {
+		first := newBooking("s1", 0, 2)
+		first.BufferAfter = 30 * time.Minute
+		first.History = nil
+		first.Track(nil, "alice")
+		if err := repo.CreateIfAvailable(first); err != nil {
+			t.Fatal(err)
+		}
+		// starts when the slot ends but inside the teardown buffer
+		if err := repo.CreateIfAvailable(newBooking("s1", 2, 3)); !errors.Is(err, domain.ErrSlotNotAvailable) {
+			t.Fatalf("booking inside a buffer: %v", err)
+		}
+		if err := repo.CreateIfAvailable(newBooking("s1", 3, 4)); err != nil {
+			t.Fatalf("booking after the buffer: %v", err)
+		}
+	})
+}
+
+func TestRepoCancelledFreesSlot(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repo domain.BookingRepository) {
+		first := newBooking("s1", 0, 2)
+		if err := repo.CreateIfAvailable(first); err != nil {
+			t.Fatal(err)
+		}
+		if err := update(repo, first, cancel); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.CreateIfAvailable(newBooking("s1", 0, 2)); err != nil {
+			t.Fatalf("slot of a cancelled booking: %v", err)
+		}
+		// the cancelled booking may not come back while its slot is taken
+		if err := update(repo, first, func(b *domain.Booking) { b.Status = domain.StatusPending }); !errors.Is(err, domain.ErrSlotNotAvailable) {
+			t.Fatalf("reviving a booking over a taken slot: %v", err)
+		}
+	})
+}
+
+func TestRepoCreateAll(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repo domain.BookingRepository) {
+		if err := repo.CreateIfAvailable(newBooking("s1", 4, 5)); err != nil {
+			t.Fatal(err)
+		}
+		series := []*domain.Booking{newBooking("s1", 0, 1), newBooking("s1", 4, 5), newBooking("s1", 8, 9)}
+		err := repo.CreateAllIfAvailable(series)
+		var conflict *domain.SeriesConflictError
+		if !errors.As(err, &conflict) || len(conflict.Conflicts) != 1 || !conflict.Conflicts[0].Start.Equal(series[1].SlotStart) {
+			t.Fatalf("got %v, want one conflict at %s", err, series[1].SlotStart)
+		}
+		if _, err := repo.GetByID(series[0].ID); !errors.Is(err, domain.ErrNotFound) {
+			t.Fatalf("occurrence stored from a rejected series: %v", err)
+		}
+		// occurrences overlapping each other conflict too
+		series = []*domain.Booking{newBooking("s1", 10, 12), newBooking("s1", 11, 13)}
+		if err := repo.CreateAllIfAvailable(series); !errors.Is(err, domain.ErrSlotNotAvailable) {
+			t.Fatalf("overlapping occurrences: %v", err)
+		}
+		series = []*domain.Booking{newBooking("s1", 0, 1), newBooking("s1", 8, 9)}
+		for _, b := range series {
+			b.SeriesID = "series-1"
+			b.History = nil
+			b.Track(nil, "alice")
+		}
+		if err := repo.CreateAllIfAvailable(series); err != nil {
+			t.Fatal(err)
+		}
+		got, err := repo.ListBySeries("series-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 2 || got[0].ID != series[0].ID || got[1].ID != series[1].ID {
+			t.Fatalf("ListBySeries = %v", got)
+		}
+	})
+}
+
+func TestRepoCreateAvailable(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repo domain.BookingRepository) {
+		if err := repo.CreateIfAvailable(newBooking("s1", 4, 5)); err != nil {
+			t.Fatal(err)
+		}
+		// the second occurrence is taken, the fourth overlaps the third
+		series := []*domain.Booking{newBooking("s1", 0, 1), newBooking("s1", 4, 5), newBooking("s1", 8, 10), newBooking("s1", 9, 11)}
+		conflicts, err := repo.CreateAvailable(series)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(conflicts) != 2 || !conflicts[0].Start.Equal(series[1].SlotStart) || !conflicts[1].Start.Equal(series[3].SlotStart) {
+			t.Fatalf("conflicts %v, want the second and fourth occurrence", conflicts)
+		}
+		for i, b := range series {
+			_, err := repo.GetByID(b.ID)
+			if stored := err == nil; stored != (i%2 == 0) {
+				t.Errorf("occurrence %d stored %v, %v", i, stored, err)
+			}
+		}
+	})
+}
+
+func TestRepoUpdate(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repo domain.BookingRepository) {
+		b := newBooking("s1", 0, 2)
+		if err := repo.CreateIfAvailable(b); err != nil {
+			t.Fatal(err)
+		}
+		stale := *b
+		if err := update(repo, b, func(b *domain.Booking) { b.Status = domain.StatusPaid }); err != nil {
+			t.Fatal(err)
+		}
+		err := update(repo, &stale, cancel)
+		var conflict *domain.ConflictError
+		if !errors.As(err, &conflict) || conflict.Expected != 1 || conflict.Actual != 2 {
+			t.Fatalf("stale update: %v", err)
+		}
+		got, err := repo.GetByID(b.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sameBooking(t, got, b)
+
+		missing := newBooking("s1", 5, 6)
+		if err := update(repo, missing, cancel); !errors.Is(err, domain.ErrNotFound) {
+			t.Fatalf("update of a missing booking: %v", err)
+		}
+	})
+}
+
+func TestRepoMoveChecksOthersOnly(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repo domain.BookingRepository) {
+		b := newBooking("s1", 0, 2)
+		other := newBooking("s1", 4, 6)
+		for _, x := range []*domain.Booking{b, other} {
+			if err := repo.CreateIfAvailable(x); err != nil {
+				t.Fatal(err)
+			}
+		}
+		// overlapping its own old slot is fine
+		if err := update(repo, b, func(b *domain.Booking) { b.SlotStart, b.SlotEnd = base.Add(time.Hour), base.Add(3*time.Hour) }); err != nil {
+			t.Fatal(err)
+		}
+		if err := update(repo, b, func(b *domain.Booking) { b.SlotStart, b.SlotEnd = base.Add(5*time.Hour), base.Add(7*time.Hour) }); !errors.Is(err, domain.ErrSlotNotAvailable) {
+			t.Fatalf("move onto another booking: %v", err)
+		}
+	})
+}
+
+func TestRepoListing(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repo domain.BookingRepository) {
+		late, early, cancelled := newBooking("s1", 6, 7), newBooking("s1", 0, 1), newBooking("s1", 3, 4)
+		early.HoldExpiresAt = base.Add(-time.Minute)
+		early.History = nil
+		early.Track(nil, "alice")
+		for _, b := range []*domain.Booking{late, early, cancelled, newBooking("s2", 0, 1)} {
+			if err := repo.CreateIfAvailable(b); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := update(repo, cancelled, cancel); err != nil {
+			t.Fatal(err)
+		}
+		got, err := repo.ListOverlapping("s1", base, base.Add(24*time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 2 || got[0].ID != early.ID || got[1].ID != late.ID {
+			t.Fatalf("ListOverlapping = %v, want %s then %s", got, early.ID, late.ID)
+		}
+		expired, err := repo.ListExpiredHolds(base, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(expired) != 1 || expired[0].ID != early.ID {
+			t.Fatalf("ListExpiredHolds = %v, want %s", expired, early.ID)
+		}
+	})
+}
+
+func TestRepoHistory(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repo domain.BookingRepository) {
+		b := newBooking("s1", 0, 2)
+		if err := repo.CreateIfAvailable(b); err != nil {
+			t.Fatal(err)
+		}
+		// enough changes for two snapshots
+		for i := 0; i < 2*domain.SnapshotEvery+3; i++ {
+			shift := time.Duration(i%2) * time.Hour
+			if err := update(repo, b, func(b *domain.Booking) { b.SlotStart, b.SlotEnd = base.Add(shift), base.Add(2*time.Hour+shift) }); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := update(repo, b, cancel); err != nil {
+			t.Fatal(err)
+		}
+		events, err := repo.History(b.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(events) != b.Version {
+			t.Fatalf("%d events for version %d", len(events), b.Version)
+		}
+		if first, last := events[0], events[len(events)-1]; first.Type != domain.HistoryCreated || last.Type != "cancelled" || last.Actor != "alice" {
+			t.Fatalf("first %+v, last %+v", first, last)
+		}
+		got, err := repo.GetByID(b.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sameBooking(t, got, b)
+		folded, err := domain.Replay(nil, events)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sameBooking(t, folded, b)
+
+		var replayed []string
+		if err := repo.ReplayAll(func(x *domain.Booking) error {
+			replayed = append(replayed, x.ID)
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if len(replayed) != 1 || replayed[0] != b.ID {
+			t.Fatalf("ReplayAll visited %v", replayed)
+		}
+		if _, err := repo.History("missing"); !errors.Is(err, domain.ErrNotFound) {
+			t.Fatalf("History(missing) = %v", err)
+		}
+	})
+}
+
+func TestRepoReprojectKeepsNewerRows(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repo domain.BookingRepository) {
+		b := newBooking("s1", 0, 2)
+		if err := repo.CreateIfAvailable(b); err != nil {
+			t.Fatal(err)
+		}
+		stale, err := repo.GetByID(b.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := update(repo, b, cancel); err != nil {
+			t.Fatal(err)
+		}
+		// a rebuild that folded the booking before the cancellation landed
+		if err := repo.Reproject(stale); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := repo.ListOverlapping("s1", base, base.Add(2*time.Hour)); len(got) != 0 {
+			t.Fatalf("stale reprojection revived %v", got)
+		}
+	})
+}
+
+func TestMemoryRepoLoadsFromSnapshot(t *testing.T) {
+	repo := NewMemoryRepo()
+	b := newBooking("s1", 0, 2)
+	if err := repo.CreateIfAvailable(b); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < domain.SnapshotEvery+2; i++ {
+		if err := update(repo, b, func(b *domain.Booking) { b.Seats = i + 1 }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	snap := repo.snapshots[b.ID]
+	if snap == nil || snap.Version != domain.SnapshotEvery {
+		t.Fatalf("snapshot %+v, want one at version %d", snap, domain.SnapshotEvery)
+	}
+	// events the snapshot covers are no longer folded
+	repo.streams[b.ID][1].Version = -1
+	got, err := repo.GetByID(b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sameBooking(t, got, b)
+	if got.Seats != domain.SnapshotEvery+2 {
+		t.Fatalf("seats %d after the snapshot's events", got.Seats)
+	}
+}
+
+func TestMemoryRepoReprojectRepairsRows(t *testing.T) {
+	repo := NewMemoryRepo()
+	b := newBooking("s1", 0, 2)
+	if err := repo.CreateIfAvailable(b); err != nil {
+		t.Fatal(err)
+	}
+	// the row drifted from the log: it claims the booking was cancelled
+	row := repo.byID[b.ID]
+	repo.unindexLocked(row)
+	row.Status = domain.StatusCancelled
+	if err := repo.CreateIfAvailable(newBooking("s1", 1, 2)); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.ReplayAll(repo.Reproject); err != nil {
+		t.Fatal(err)
+	}
+	got, err := repo.ListOverlapping("s1", base, base.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].ID != b.ID || got[0].Status != domain.StatusPending {
+		t.Fatalf("ListOverlapping = %v, want %s pending again", got, b.ID)
+	}
+}
+
+func TestMemoryRepoRedeemsWithInsert(t *testing.T) {
+	repo := NewMemoryRepo()
+	if err := repo.Vouchers().CreateVoucher(&domain.Voucher{Code: "ONCE", Kind: domain.VoucherPercent, Percent: 10, MaxRedemptions: 1}); err != nil {
+		t.Fatal(err)
+	}
+	withCode := func(b *domain.Booking) *domain.Booking {
+		b.Redemption = &domain.Redemption{Code: "ONCE", BookingID: b.ID, UserID: b.UserID, RedeemedAt: base}
+		return b
+	}
+	first := withCode(newBooking("s1", 0, 1))
+	if err := repo.CreateIfAvailable(first); err != nil {
+		t.Fatal(err)
+	}
+	if first.Redemption != nil {
+		t.Error("redemption not cleared after the write")
+	}
+	second := withCode(newBooking("s1", 2, 3))
+	if err := repo.CreateIfAvailable(second); !errors.Is(err, domain.ErrVoucherRejected) {
+		t.Fatalf("second redemption: %v", err)
+	}
+	if _, err := repo.GetByID(second.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("booking stored without its redemption: %v", err)
+	}
+	if err := repo.CreateIfAvailable(newBooking("s1", 2, 3)); err != nil {
+		t.Fatalf("slot of the rejected booking: %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"sync"
+	"time"
+
+	"templespace/cmd/booking/internal/domain"
+)
+
+// MemoryVouchers stores vouchers and redemptions; codes are redeemed by the MemoryRepo owning it.
+type MemoryVouchers struct {
+	mu          sync.RWMutex
+	byCode      map[string]*domain.Voucher
+	redemptions []*domain.Redemption
+}
+
+func NewMemoryVouchers() *MemoryVouchers {
+	return &MemoryVouchers{byCode: map[string]*domain.Voucher{}}
+}
+
+func (m *MemoryVouchers) CreateVoucher(v *domain.Voucher) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.byCode[v.Code]; ok {
+		return domain.ErrVoucherExists
+	}
+	cp := *v
+	m.byCode[v.Code] = &cp
+	return nil
+}
+
+func (m *MemoryVouchers) GetVoucher(code string) (*domain.Voucher, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	v, ok := m.byCode[code]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	cp := *v
+	return &cp, nil
+}
+
+func (m *MemoryVouchers) ListVouchers() ([]*domain.Voucher, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]*domain.Voucher, 0, len(m.byCode))
+	for _, v := range m.byCode {
+		cp := *v
+		out = append(out, &cp)
+	}
+	return out, nil
+}
+
+// redeem records r unless its voucher already has MaxRedemptions active redemptions, or MaxPerUser
+// for r.UserID, in which case it returns a *domain.VoucherError. Check and insert are atomic.
+func (m *MemoryVouchers) redeem(r *domain.Redemption) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	v, ok := m.byCode[r.Code]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	if err := v.CheckLimits(m.countLocked(r.Code, r.UserID)); err != nil {
+		return err
+	}
+	cp := *r
+	m.redemptions = append(m.redemptions, &cp)
+	return nil
+}
+
+func (m *MemoryVouchers) Release(bookingID string, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, r := range m.redemptions {
+		if r.BookingID == bookingID && r.Active() {
+			r.ReleasedAt = at
+		}
+	}
+	return nil
+}
+
+func (m *MemoryVouchers) CountRedemptions(code, userID string) (int, int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	total, byUser := m.countLocked(code, userID)
+	return total, byUser, nil
+}
+
+func (m *MemoryVouchers) countLocked(code, userID string) (total, byUser int) {
+	for _, r := range m.redemptions {
+		if r.Code != code || !r.Active() {
+			continue
+		}
+		total++
+		if r.UserID == userID {
+			byUser++
+		}
+	}
+	return total, byUser
+}
+
+func (m *MemoryVouchers) ListRedemptions(code string) ([]*domain.Redemption, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]*domain.Redemption, 0)
+	for _, r := range m.redemptions {
+		if r.Code == code {
+			cp := *r
+			out = append(out, &cp)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,159 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"templespace/cmd/booking/internal/domain"
+)
+
+// PostgresVouchers stores vouchers and redemptions; codes are redeemed by PostgresRepo in the
+// transaction inserting the booking.
+type PostgresVouchers struct {
+	db *sql.DB
+}
+
+func NewPostgresVouchers(db *sql.DB) *PostgresVouchers {
+	return &PostgresVouchers{db: db}
+}
+
+const voucherColumns = `code, kind, percent, amount, currency, valid_from, valid_until, max_redemptions, max_per_user,
+	space_ids, tags, created_at`
+
+func (r *PostgresVouchers) CreateVoucher(v *domain.Voucher) error {
+	spaceIDs, _ := json.Marshal(nonNil(v.SpaceIDs))
+	tags, _ := json.Marshal(nonNil(v.Tags))
+	_, err := r.db.ExecContext(context.Background(), `
+		INSERT INTO vouchers (`+voucherColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
+		v.Code, string(v.Kind), v.Percent, v.Amount.Amount, v.Amount.Currency, nullTime(v.ValidFrom), nullTime(v.ValidUntil),
+		v.MaxRedemptions, v.MaxPerUser, spaceIDs, tags, v.CreatedAt)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		return domain.ErrVoucherExists
+	}
+	return err
+}
+
+func (r *PostgresVouchers) GetVoucher(code string) (*domain.Voucher, error) {
+	return getVoucher(context.Background(), r.db, code, "")
+}
+
+// getVoucher loads code, appending suffix (such as FOR UPDATE) to the query.
+func getVoucher(ctx context.Context, db dbtx, code, suffix string) (*domain.Voucher, error) {
+	row := db.QueryRowContext(ctx, `SELECT `+voucherColumns+` FROM vouchers WHERE code = $1 `+suffix, code)
+	v, err := scanVoucher(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	return v, err
+}
+
+func (r *PostgresVouchers) ListVouchers() ([]*domain.Voucher, error) {
+	rows, err := r.db.QueryContext(context.Background(), `SELECT `+voucherColumns+` FROM vouchers ORDER BY created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*domain.Voucher
+	for rows.Next() {
+		v, err := scanVoucher(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, rows.Err()
+}
+
+// redeem records red in tx unless its voucher already has MaxRedemptions active redemptions, or
+// MaxPerUser for red.UserID, in which case it returns a *domain.VoucherError. The voucher row stays
+// locked until tx ends, so limits hold across concurrent redemptions.
+func redeem(ctx context.Context, tx *sql.Tx, red *domain.Redemption) error {
+	v, err := getVoucher(ctx, tx, red.Code, "FOR UPDATE")
+	if err != nil {
+		return err
+	}
+	total, byUser, err := countRedemptions(ctx, tx, red.Code, red.UserID)
+	if err != nil {
+		return err
+	}
+	if err := v.CheckLimits(total, byUser); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO voucher_redemptions (booking_id, code, user_id, discount, currency, redeemed_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		red.BookingID, red.Code, red.UserID, red.Discount.Amount, red.Discount.Currency, red.RedeemedAt); err != nil {
+		return mapPgError(err)
+	}
+	return nil
+}
+
+func (r *PostgresVouchers) Release(bookingID string, at time.Time) error {
+	_, err := r.db.ExecContext(context.Background(), `
+		UPDATE voucher_redemptions SET released_at = $2 WHERE booking_id = $1 AND released_at IS NULL`, bookingID, at)
+	return err
+}
+
+func (r *PostgresVouchers) CountRedemptions(code, userID string) (int, int, error) {
+	return countRedemptions(context.Background(), r.db, code, userID)
+}
+
+func countRedemptions(ctx context.Context, db dbtx, code, userID string) (total, byUser int, err error) {
+	err = db.QueryRowContext(ctx, `
+		SELECT count(*), count(*) FILTER (WHERE user_id = $2)
+		FROM voucher_redemptions WHERE code = $1 AND released_at IS NULL`, code, userID).Scan(&total, &byUser)
+	return total, byUser, err
+}
+
+func (r *PostgresVouchers) ListRedemptions(code string) ([]*domain.Redemption, error) {
+	rows, err := r.db.QueryContext(context.Background(), `
+		SELECT code, booking_id, user_id, discount, currency, redeemed_at, released_at
+		FROM voucher_redemptions WHERE code = $1 ORDER BY redeemed_at`, code)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []*domain.Redemption{}
+	for rows.Next() {
+		var red domain.Redemption
+		var released sql.NullTime
+		if err := rows.Scan(&red.Code, &red.BookingID, &red.UserID, &red.Discount.Amount, &red.Discount.Currency,
+			&red.RedeemedAt, &released); err != nil {
+			return nil, err
+		}
+		red.ReleasedAt = released.Time
+		out = append(out, &red)
+	}
+	return out, rows.Err()
+}
+
+func scanVoucher(row interface{ Scan(dest ...any) error }) (*domain.Voucher, error) {
+	var v domain.Voucher
+	var kind string
+	var from, until sql.NullTime
+	var spaceIDs, tags []byte
+	if err := row.Scan(&v.Code, &kind, &v.Percent, &v.Amount.Amount, &v.Amount.Currency, &from, &until,
+		&v.MaxRedemptions, &v.MaxPerUser, &spaceIDs, &tags, &v.CreatedAt); err != nil {
+		return nil, err
+	}
+	v.Kind = domain.VoucherKind(kind)
+	v.ValidFrom, v.ValidUntil = from.Time, until.Time
+	if err := errors.Join(json.Unmarshal(spaceIDs, &v.SpaceIDs), json.Unmarshal(tags, &v.Tags)); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
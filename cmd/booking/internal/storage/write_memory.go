@@ -1,7 +1,9 @@
 package storage
 
 import (
+	"context"
 	"errors"
+	"sort"
 	"sync"
 	"time"
 
@@ -9,59 +11,304 @@ import (
 )
 
 type MemoryRepo struct {
-	mu      sync.RWMutex
-	byID    map[string]*domain.Booking
-	bySpace map[string][]*domain.Booking
+	mu   sync.RWMutex
+	byID map[string]*domain.Booking
+	// bySpace indexes only slot-holding bookings; it is kept in step with byID on every write
+	bySpace map[string]*spaceIndex
+	outbox  *MemoryOutbox
+	// vouchers receives the redemptions of created bookings under the repository's lock
+	vouchers *MemoryVouchers
+	// streams is the event log GetByID folds, starting at the latest snapshot
+	streams   map[string][]domain.HistoryEvent
+	snapshots map[string]*domain.Booking
 }
 
 func NewMemoryRepo() *MemoryRepo {
-	return &MemoryRepo{byID: map[string]*domain.Booking{}, bySpace: map[string][]*domain.Booking{}}
+	return &MemoryRepo{
+		byID:      map[string]*domain.Booking{},
+		bySpace:   map[string]*spaceIndex{},
+		outbox:    NewMemoryOutbox(),
+		vouchers:  NewMemoryVouchers(),
+		streams:   map[string][]domain.HistoryEvent{},
+		snapshots: map[string]*domain.Booking{},
+	}
+}
+
+// Outbox returns the outbox the repository stores the events of written bookings in.
+func (m *MemoryRepo) Outbox() *MemoryOutbox { return m.outbox }
+
+// Vouchers returns the voucher store the repository redeems the codes of created bookings in.
+func (m *MemoryRepo) Vouchers() *MemoryVouchers { return m.vouchers }
+
+func (m *MemoryRepo) CreateIfAvailable(b *domain.Booking) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.fitsLocked(b) {
+		return domain.ErrSlotNotAvailable
+	}
+	if err := m.insertLocked(b); err != nil {
+		return err
+	}
+	if b.Redemption != nil {
+		if err := m.vouchers.redeem(b.Redemption); err != nil {
+			m.unindexLocked(b)
+			delete(m.byID, b.ID)
+			return err
+		}
+	}
+	m.flushLocked(b)
+	return nil
+}
+
+func (m *MemoryRepo) CreateAllIfAvailable(bs []*domain.Booking) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, b := range bs {
+		if _, ok := m.byID[b.ID]; ok {
+			return errors.New("duplicate id")
+		}
+		if err := b.CheckHistory(); err != nil {
+			return err
+		}
+	}
+	// insert one by one so later occurrences are checked against earlier ones, then undo on conflict
+	var conflicts []domain.Interval
+	var inserted []*domain.Booking
+	for _, b := range bs {
+		if !m.fitsLocked(b) {
+			conflicts = append(conflicts, domain.Interval{Start: b.SlotStart, End: b.SlotEnd})
+			continue
+		}
+		_ = m.insertLocked(b)
+		inserted = append(inserted, b)
+	}
+	if len(conflicts) > 0 {
+		for _, b := range inserted {
+			m.unindexLocked(b)
+			delete(m.byID, b.ID)
+		}
+		return &domain.SeriesConflictError{Conflicts: conflicts}
+	}
+	m.flushLocked(bs...)
+	return nil
 }
 
-func (m *MemoryRepo) Create(b *domain.Booking) error {
+func (m *MemoryRepo) CreateAvailable(bs []*domain.Booking) ([]domain.Interval, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	for _, b := range bs {
+		if _, ok := m.byID[b.ID]; ok {
+			return nil, errors.New("duplicate id")
+		}
+		if err := b.CheckHistory(); err != nil {
+			return nil, err
+		}
+	}
+	var conflicts []domain.Interval
+	var inserted []*domain.Booking
+	for _, b := range bs {
+		if !m.fitsLocked(b) {
+			conflicts = append(conflicts, domain.Interval{Start: b.SlotStart, End: b.SlotEnd})
+			continue
+		}
+		_ = m.insertLocked(b)
+		inserted = append(inserted, b)
+	}
+	m.flushLocked(inserted...)
+	return conflicts, nil
+}
+
+func (m *MemoryRepo) insertLocked(b *domain.Booking) error {
 	if _, ok := m.byID[b.ID]; ok {
 		return errors.New("duplicate id")
 	}
+	if err := b.CheckHistory(); err != nil {
+		return err
+	}
 	cp := *b
+	cp.Outbox, cp.History, cp.Redemption = nil, nil, nil
 	m.byID[b.ID] = &cp
-	m.bySpace[b.SpaceID] = append(m.bySpace[b.SpaceID], &cp)
+	m.indexLocked(&cp)
 	return nil
 }
 
-func (m *MemoryRepo) Update(b *domain.Booking) error {
+// flushLocked moves the recorded events of written bookings to the outbox and their history to the
+// event log, snapshotting streams that grew past a multiple of domain.SnapshotEvery.
+func (m *MemoryRepo) flushLocked(bs ...*domain.Booking) {
+	for _, b := range bs {
+		_ = m.outbox.Append(context.Background(), b.Outbox...)
+		m.streams[b.ID] = append(m.streams[b.ID], b.History...)
+		if domain.SnapshotDue(b.History) {
+			snap := *m.byID[b.ID]
+			m.snapshots[b.ID] = &snap
+		}
+		b.Outbox, b.History, b.Redemption = nil, nil, nil
+	}
+}
+
+func (m *MemoryRepo) Update(b *domain.Booking, expectedVersion int) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	if _, ok := m.byID[b.ID]; !ok {
-		return errors.New("not found")
+	cur, ok := m.byID[b.ID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	if cur.Version != expectedVersion {
+		return &domain.ConflictError{ID: b.ID, Expected: expectedVersion, Actual: cur.Version}
+	}
+	if err := b.CheckHistory(); err != nil {
+		return err
+	}
+	m.unindexLocked(cur)
+	if b.Status.HoldsSlot() && !m.fitsLocked(b) {
+		m.indexLocked(cur)
+		return domain.ErrSlotNotAvailable
 	}
 	cp := *b
+	cp.Outbox, cp.History, cp.Redemption = nil, nil, nil
 	m.byID[b.ID] = &cp
-	// naive: not updating bySpace slice entries for brevity
+	m.indexLocked(&cp)
+	m.flushLocked(b)
 	return nil
 }
 
+func (m *MemoryRepo) indexLocked(b *domain.Booking) {
+	if !b.Status.HoldsSlot() {
+		return
+	}
+	ix := m.bySpace[b.SpaceID]
+	if ix == nil {
+		ix = &spaceIndex{}
+		m.bySpace[b.SpaceID] = ix
+	}
+	blk := b.Blocked()
+	ix.insert(slotEntry{start: blk.Start, end: blk.End, id: b.ID})
+}
+
+func (m *MemoryRepo) unindexLocked(b *domain.Booking) {
+	if ix := m.bySpace[b.SpaceID]; ix != nil {
+		blk := b.Blocked()
+		ix.remove(slotEntry{start: blk.Start, end: blk.End, id: b.ID})
+	}
+}
+
+// GetByID folds the booking's events since its latest snapshot.
 func (m *MemoryRepo) GetByID(id string) (*domain.Booking, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
-	b := m.byID[id]
-	if b == nil {
-		return nil, errors.New("not found")
+	return m.replayLocked(id)
+}
+
+func (m *MemoryRepo) replayLocked(id string) (*domain.Booking, error) {
+	stream := m.streams[id]
+	snap := m.snapshots[id]
+	if snap != nil {
+		i := sort.Search(len(stream), func(i int) bool { return stream[i].Version > snap.Version })
+		stream = stream[i:]
+	}
+	return domain.Replay(snap, stream)
+}
+
+func (m *MemoryRepo) History(id string) ([]domain.HistoryEvent, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	stream := m.streams[id]
+	if len(stream) == 0 {
+		return nil, domain.ErrNotFound
+	}
+	return append([]domain.HistoryEvent(nil), stream...), nil
+}
+
+func (m *MemoryRepo) ReplayAll(fn func(b *domain.Booking) error) error {
+	m.mu.RLock()
+	ids := make([]string, 0, len(m.streams))
+	for id := range m.streams {
+		ids = append(ids, id)
+	}
+	m.mu.RUnlock()
+	sort.Strings(ids)
+	for _, id := range ids {
+		b, err := m.GetByID(id)
+		if err != nil {
+			return err
+		}
+		if err := fn(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *MemoryRepo) Reproject(b *domain.Booking) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if cur, ok := m.byID[b.ID]; ok {
+		if cur.Version > b.Version {
+			return nil
+		}
+		m.unindexLocked(cur)
 	}
 	cp := *b
-	return &cp, nil
+	cp.Outbox, cp.History, cp.Redemption = nil, nil, nil
+	m.byID[b.ID] = &cp
+	m.indexLocked(&cp)
+	return nil
+}
+
+func (m *MemoryRepo) ListOverlapping(spaceID string, start, end time.Time) ([]*domain.Booking, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var out []*domain.Booking
+	m.bySpace[spaceID].overlapping(start, end, func(e slotEntry) bool {
+		cp := *m.byID[e.id]
+		out = append(out, &cp)
+		return true
+	})
+	sort.Slice(out, func(i, j int) bool { return out[i].SlotStart.Before(out[j].SlotStart) })
+	return out, nil
 }
 
-func (m *MemoryRepo) IsAvailable(spaceID string, start, end time.Time) (bool, error) {
+func (m *MemoryRepo) ListBySeries(seriesID string) ([]*domain.Booking, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
-	for _, b := range m.bySpace[spaceID] {
-		if overlaps(b.SlotStart, b.SlotEnd, start, end) && b.Status != domain.StatusCancelled {
-			return false, nil
+	var out []*domain.Booking
+	for _, b := range m.byID {
+		if seriesID != "" && b.SeriesID == seriesID {
+			cp := *b
+			out = append(out, &cp)
 		}
 	}
-	return true, nil
+	sort.Slice(out, func(i, j int) bool { return out[i].SlotStart.Before(out[j].SlotStart) })
+	return out, nil
+}
+
+func (m *MemoryRepo) ListExpiredHolds(now time.Time, limit int) ([]*domain.Booking, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var out []*domain.Booking
+	for _, b := range m.byID {
+		if len(out) >= limit {
+			break
+		}
+		if b.Status == domain.StatusPending && !b.HoldExpiresAt.IsZero() && !b.HoldExpiresAt.After(now) {
+			cp := *b
+			out = append(out, &cp)
+		}
+	}
+	return out, nil
+}
+
+// fitsLocked checks b against the other live bookings overlapping its blocked interval.
+func (m *MemoryRepo) fitsLocked(b *domain.Booking) bool {
+	blk := b.Blocked()
+	var others []*domain.Booking
+	m.bySpace[b.SpaceID].overlapping(blk.Start, blk.End, func(e slotEntry) bool {
+		if e.id != b.ID {
+			others = append(others, m.byID[e.id])
+		}
+		return true
+	})
+	return b.Fits(others)
 }
 
 func overlaps(aStart, aEnd, bStart, bEnd time.Time) bool {
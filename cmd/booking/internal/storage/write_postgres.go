@@ -0,0 +1,374 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	_ "github.com/jackc/pgx/v5/stdlib" // registers the "pgx" database/sql driver
+
+	"templespace/cmd/booking/internal/domain"
+)
+
+// Postgres SQLSTATE codes mapped onto domain errors.
+const (
+	pgUniqueViolation    = "23505"
+	pgExclusionViolation = "23P01"
+)
+
+// PostgresRepo is the write model on Postgres. Slot-holding writes check Booking.Fits under a
+// per-space advisory lock, so the check holds across concurrent writers and instances; the
+// bookings_no_overlap exclusion constraint additionally guards exclusive bookings.
+type PostgresRepo struct {
+	db *sql.DB
+}
+
+func NewPostgresRepo(db *sql.DB) *PostgresRepo {
+	return &PostgresRepo{db: db}
+}
+
+// OpenPostgres connects to url and applies pending migrations.
+func OpenPostgres(ctx context.Context, url string) (*sql.DB, error) {
+	db, err := sql.Open("pgx", url)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	if err := Migrate(ctx, db); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// dbtx is satisfied by both *sql.DB and *sql.Tx.
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// spaceLockClass namespaces the per-space advisory locks (two-key form, apart from migrationLockID).
+const spaceLockClass = 7261002
+
+// commitWithOutbox stores the recorded events and the history of the written bookings in tx and
+// commits it, then clears what the bookings carried for the write.
+func commitWithOutbox(ctx context.Context, tx *sql.Tx, bs ...*domain.Booking) error {
+	for _, b := range bs {
+		if err := insertOutbox(ctx, tx, b); err != nil {
+			return err
+		}
+		if err := appendHistory(ctx, tx, b); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	for _, b := range bs {
+		b.Outbox, b.History, b.Redemption = nil, nil, nil
+	}
+	return nil
+}
+
+func insertBooking(ctx context.Context, db dbtx, b *domain.Booking) error {
+	blk := b.Blocked()
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO bookings (id, space_id, user_id, series_id, slot_start, slot_end, block_start, block_end, seats, capacity,
+		                      total_amount, currency, voucher_code, refunded_amount, status, version, hold_expires_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)`,
+		b.ID, b.SpaceID, b.UserID, nullString(b.SeriesID), b.SlotStart, b.SlotEnd, blk.Start, blk.End, b.Seats, b.Capacity,
+		b.Total.Amount, b.Total.Currency, nullString(b.VoucherCode), b.Refunded.Amount, string(b.Status), b.Version,
+		nullTime(b.HoldExpiresAt), b.CreatedAt, b.UpdatedAt)
+	return mapPgError(err)
+}
+
+// lockSpaces serializes slot-holding writes per space until the transaction ends, so the seat check
+// and the write that follows it cannot interleave with another writer. Spaces are locked in order
+// to avoid deadlocks between batches.
+func lockSpaces(ctx context.Context, tx *sql.Tx, spaceIDs ...string) error {
+	ids := append([]string(nil), spaceIDs...)
+	sort.Strings(ids)
+	for i, id := range ids {
+		if i > 0 && id == ids[i-1] {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1, hashtext($2))`, spaceLockClass, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fits checks b against the other live bookings of its space; the space must be locked.
+func fits(ctx context.Context, tx *sql.Tx, b *domain.Booking) (bool, error) {
+	blk := b.Blocked()
+	others, err := queryBookings(ctx, tx, `
+		SELECT `+bookingColumns+` FROM bookings
+		WHERE space_id = $1 AND id <> $2 AND status NOT IN ('cancelled', 'expired')
+		  AND tstzrange(block_start, block_end, '[)') && tstzrange($3, $4, '[)')`, b.SpaceID, b.ID, blk.Start, blk.End)
+	if err != nil {
+		return false, err
+	}
+	return b.Fits(others), nil
+}
+
+// CreateIfAvailable checks and inserts b in one transaction under the space lock.
+func (r *PostgresRepo) CreateIfAvailable(b *domain.Booking) error {
+	ctx := context.Background()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := lockSpaces(ctx, tx, b.SpaceID); err != nil {
+		return err
+	}
+	ok, err := fits(ctx, tx, b)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return domain.ErrSlotNotAvailable
+	}
+	if err := insertBooking(ctx, tx, b); err != nil {
+		return err
+	}
+	if b.Redemption != nil {
+		if err := redeem(ctx, tx, b.Redemption); err != nil {
+			return err
+		}
+	}
+	return commitWithOutbox(ctx, tx, b)
+}
+
+// CreateAllIfAvailable inserts the bookings in one transaction. Every occurrence is checked against
+// the stored bookings and the occurrences inserted before it, so all conflicts are reported before
+// the whole batch is rolled back.
+func (r *PostgresRepo) CreateAllIfAvailable(bs []*domain.Booking) error {
+	ctx := context.Background()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	spaceIDs := make([]string, 0, len(bs))
+	for _, b := range bs {
+		spaceIDs = append(spaceIDs, b.SpaceID)
+	}
+	if err := lockSpaces(ctx, tx, spaceIDs...); err != nil {
+		return err
+	}
+	var conflicts []domain.Interval
+	for _, b := range bs {
+		ok, err := fits(ctx, tx, b)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			conflicts = append(conflicts, domain.Interval{Start: b.SlotStart, End: b.SlotEnd})
+			continue
+		}
+		if err := insertBooking(ctx, tx, b); err != nil {
+			return err
+		}
+	}
+	if len(conflicts) > 0 {
+		return &domain.SeriesConflictError{Conflicts: conflicts}
+	}
+	return commitWithOutbox(ctx, tx, bs...)
+}
+
+// CreateAvailable inserts the bookings that fit in one transaction, checking each against the stored
+// bookings and the occurrences inserted before it, and skips the others.
+func (r *PostgresRepo) CreateAvailable(bs []*domain.Booking) ([]domain.Interval, error) {
+	ctx := context.Background()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	spaceIDs := make([]string, 0, len(bs))
+	for _, b := range bs {
+		spaceIDs = append(spaceIDs, b.SpaceID)
+	}
+	if err := lockSpaces(ctx, tx, spaceIDs...); err != nil {
+		return nil, err
+	}
+	var conflicts []domain.Interval
+	var inserted []*domain.Booking
+	for _, b := range bs {
+		ok, err := fits(ctx, tx, b)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			conflicts = append(conflicts, domain.Interval{Start: b.SlotStart, End: b.SlotEnd})
+			continue
+		}
+		if err := insertBooking(ctx, tx, b); err != nil {
+			return nil, err
+		}
+		inserted = append(inserted, b)
+	}
+	if err := commitWithOutbox(ctx, tx, inserted...); err != nil {
+		return nil, err
+	}
+	return conflicts, nil
+}
+
+func (r *PostgresRepo) Update(b *domain.Booking, expectedVersion int) error {
+	ctx := context.Background()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if b.Status.HoldsSlot() {
+		if err := lockSpaces(ctx, tx, b.SpaceID); err != nil {
+			return err
+		}
+		ok, err := fits(ctx, tx, b)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return domain.ErrSlotNotAvailable
+		}
+	}
+	blk := b.Blocked()
+	res, err := tx.ExecContext(ctx, `
+		UPDATE bookings
+		SET space_id = $2, user_id = $3, series_id = $4, slot_start = $5, slot_end = $6, block_start = $7, block_end = $8,
+		    seats = $9, capacity = $10, total_amount = $11, currency = $12, voucher_code = $13, refunded_amount = $14,
+		    status = $15, version = $16, hold_expires_at = $17, updated_at = $18
+		WHERE id = $1 AND version = $19`,
+		b.ID, b.SpaceID, b.UserID, nullString(b.SeriesID), b.SlotStart, b.SlotEnd, blk.Start, blk.End, b.Seats, b.Capacity,
+		b.Total.Amount, b.Total.Currency, nullString(b.VoucherCode), b.Refunded.Amount, string(b.Status), b.Version,
+		nullTime(b.HoldExpiresAt), b.UpdatedAt, expectedVersion)
+	if err != nil {
+		return mapPgError(err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return commitWithOutbox(ctx, tx, b)
+	}
+	// nothing matched: tell a missing booking apart from a stale version
+	var actual int
+	err = tx.QueryRowContext(ctx, `SELECT version FROM bookings WHERE id = $1`, b.ID).Scan(&actual)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return &domain.ConflictError{ID: b.ID, Expected: expectedVersion, Actual: actual}
+}
+
+// bookingColumns is the SELECT list understood by scanBooking.
+const bookingColumns = `id, space_id, user_id, series_id, slot_start, slot_end, block_start, block_end, seats, capacity,
+	total_amount, currency, voucher_code, refunded_amount, status, version, hold_expires_at, created_at, updated_at`
+
+// GetByID folds the booking's events since its latest snapshot; the bookings row is not read.
+func (r *PostgresRepo) GetByID(id string) (*domain.Booking, error) {
+	return loadBooking(context.Background(), r.db, id)
+}
+
+func (r *PostgresRepo) ListOverlapping(spaceID string, start, end time.Time) ([]*domain.Booking, error) {
+	if !start.Before(end) {
+		return nil, nil
+	}
+	return r.query(`
+		SELECT `+bookingColumns+` FROM bookings
+		WHERE space_id = $1 AND status NOT IN ('cancelled', 'expired')
+		  AND tstzrange(block_start, block_end, '[)') && tstzrange($2, $3, '[)')
+		ORDER BY slot_start`, spaceID, start, end)
+}
+
+func (r *PostgresRepo) ListBySeries(seriesID string) ([]*domain.Booking, error) {
+	return r.query(`SELECT `+bookingColumns+` FROM bookings WHERE series_id = $1 ORDER BY slot_start`, seriesID)
+}
+
+func (r *PostgresRepo) ListExpiredHolds(now time.Time, limit int) ([]*domain.Booking, error) {
+	return r.query(`
+		SELECT `+bookingColumns+` FROM bookings
+		WHERE status = 'pending' AND hold_expires_at <= $1
+		ORDER BY hold_expires_at
+		LIMIT $2`, now, limit)
+}
+
+func (r *PostgresRepo) query(q string, args ...any) ([]*domain.Booking, error) {
+	return queryBookings(context.Background(), r.db, q, args...)
+}
+
+func queryBookings(ctx context.Context, db dbtx, q string, args ...any) ([]*domain.Booking, error) {
+	rows, err := db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*domain.Booking
+	for rows.Next() {
+		b, err := scanBooking(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, b)
+	}
+	return out, rows.Err()
+}
+
+func scanBooking(row interface{ Scan(dest ...any) error }) (*domain.Booking, error) {
+	var b domain.Booking
+	var status string
+	var series, voucher sql.NullString
+	var hold sql.NullTime
+	var blockStart, blockEnd time.Time
+	if err := row.Scan(&b.ID, &b.SpaceID, &b.UserID, &series, &b.SlotStart, &b.SlotEnd, &blockStart, &blockEnd, &b.Seats, &b.Capacity,
+		&b.Total.Amount, &b.Total.Currency, &voucher, &b.Refunded.Amount, &status, &b.Version, &hold, &b.CreatedAt, &b.UpdatedAt); err != nil {
+		return nil, err
+	}
+	b.BufferBefore = b.SlotStart.Sub(blockStart)
+	b.BufferAfter = blockEnd.Sub(b.SlotEnd)
+	b.Status = domain.BookingStatus(status)
+	b.SeriesID = series.String
+	b.VoucherCode = voucher.String
+	if b.Refunded.Amount != 0 {
+		b.Refunded.Currency = b.Total.Currency
+	}
+	if hold.Valid {
+		b.HoldExpiresAt = hold.Time
+	}
+	return &b, nil
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
+func mapPgError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+	switch pgErr.Code {
+	case pgExclusionViolation:
+		return domain.ErrSlotNotAvailable
+	case pgUniqueViolation:
+		return errors.New("duplicate id")
+	}
+	return err
+}
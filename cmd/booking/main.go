@@ -1,20 +1,183 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
+	"templespace/cmd/booking/internal/auth"
 	"templespace/cmd/booking/internal/config"
+	"templespace/cmd/booking/internal/domain"
+	"templespace/cmd/booking/internal/payment"
+	"templespace/cmd/booking/internal/queue"
+	"templespace/cmd/booking/internal/readmodel"
 	httpserver "templespace/cmd/booking/internal/server"
+	"templespace/cmd/booking/internal/service"
+	"templespace/cmd/booking/internal/spaces"
+	"templespace/cmd/booking/internal/storage"
+	"templespace/internal/outbox"
 )
 
 func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 	cfg := config.FromEnv()
 
+	// In-memory dependencies by default; storage, auth and payment adapters are swappable behind service interfaces
+	repos, err := newRepositories(cfg)
+	if err != nil {
+		log.Println("storage error:", err)
+		os.Exit(1)
+	}
+	rm := readmodel.NewMemoryReadModel()
+	events, closeEvents, err := newPublisher(cfg)
+	if err != nil {
+		log.Println("event publisher error:", err)
+		os.Exit(1)
+	}
+	verifier, err := newTokenVerifier(cfg)
+	if err != nil {
+		log.Println("auth verifier error:", err)
+		os.Exit(1)
+	}
+	var gateway service.PaymentGateway = payment.NewStubGateway()
+	if cfg.PaymentProviderURL != "" {
+		if cfg.PaymentWebhookSecret == "" {
+			log.Println("config error: PAYMENT_WEBHOOK_SECRET is required with PAYMENT_PROVIDER_URL")
+			os.Exit(1)
+		}
+		gateway = payment.NewProviderClient(cfg.PaymentProviderURL, cfg.PaymentAPIKey, cfg.AuthTimeout)
+	}
+	opts := []service.Option{
+		service.WithHoldTTL(cfg.HoldTTL),
+		service.WithVouchers(repos.vouchers),
+		service.WithPaymentIntents(repos.payments),
+		service.WithIdempotency(repos.idempotency, cfg.IdempotencyTTL),
+		service.WithOutbox(repos.outbox),
+	}
+	if cfg.SpaceGRPCAddr != "" {
+		spaceClient, err := spaces.NewGRPCClient(cfg.SpaceGRPCAddr, cfg.AuthTimeout, cfg.Currency)
+		if err != nil {
+			log.Println("space client error:", err)
+			os.Exit(1)
+		}
+		opts = append(opts,
+			service.WithSpaceOwners(spaceClient),
+			service.WithSpacePolicies(spaceClient),
+			service.WithSpaceSchedules(spaceClient),
+			service.WithSpacePricing(spaceClient),
+			service.WithSpaceTags(spaceClient))
+	}
+	svc := service.New(repos.bookings, rm, events, verifier, gateway, opts...)
+	// also purges idempotency keys, so it runs even when holds never expire
+	go service.NewExpirer(svc, cfg.ExpiryInterval).Run(ctx)
+	// events are stored with the booking writes and published from the outbox by the relay
+	relayCtx, stopRelay := context.WithCancel(context.Background())
+	relayDone := make(chan struct{})
+	go func() {
+		defer close(relayDone)
+		outbox.NewRelay(repos.outbox, events, cfg.OutboxInterval).Run(relayCtx)
+	}()
+
 	// Start HTTP server (gRPC server can be added similarly via build tags like in Auth)
-	srv := httpserver.NewHTTPServer(cfg)
-	if err := srv.Listen(cfg.HTTPAddr); err != nil {
-		log.Println("http server error:", err)
+	srv := httpserver.NewHTTPServer(cfg, svc)
+	go func() {
+		if err := srv.Listen(cfg.HTTPAddr); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("http server error: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("http server shutdown error: %v", err)
+	}
+	// the relay makes a last pass over what the finished requests stored, then the producer flushes
+	stopRelay()
+	<-relayDone
+	if err := closeEvents(); err != nil {
+		log.Printf("event publisher close error: %v", err)
 		os.Exit(1)
 	}
+	log.Println("booking service gracefully stopped")
+}
+
+// newPublisher returns the event publisher cfg.Events selects and a func flushing it on shutdown.
+func newPublisher(cfg *config.Config) (domain.EventPublisher, func() error, error) {
+	if cfg.Events != "kafka" {
+		return queue.NewMemoryPublisher(), func() error { return nil }, nil
+	}
+	w, err := queue.NewKafkaWriter(queue.KafkaConfig{
+		Brokers:      strings.Split(cfg.KafkaBrokers, ","),
+		Acks:         cfg.KafkaAcks,
+		BatchSize:    cfg.KafkaBatchSize,
+		Linger:       cfg.KafkaLinger,
+		Compression:  cfg.KafkaCompression,
+		WriteTimeout: 10 * time.Second,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	p := queue.NewKafkaPublisher(w, cfg.KafkaTopic, 30*time.Second)
+	return p, p.Close, nil
+}
+
+func newTokenVerifier(cfg *config.Config) (service.TokenVerifier, error) {
+	switch cfg.AuthMode {
+	case "grpc":
+		v, err := auth.NewGRPCVerifier(cfg.AuthGRPCAddr, cfg.AuthTimeout, cfg.AuthRetries)
+		if err != nil {
+			return nil, err
+		}
+		return auth.NewCachingVerifier(v, cfg.AuthCacheTTL), nil
+	case "jwks":
+		return auth.NewJWKSVerifier(cfg.AuthJWKSURL, cfg.JWTIssuer, cfg.JWTAudience, 10*time.Minute, cfg.AuthTimeout), nil
+	case "stub":
+		log.Printf("AUTH_MODE=stub: accepting any token, for development only")
+		return auth.NewStubVerifier(), nil
+	default:
+		return nil, fmt.Errorf("unknown AUTH_MODE %q", cfg.AuthMode)
+	}
+}
+
+// repositories are the write-side stores, all kept in the backend cfg.Storage selects.
+type repositories struct {
+	bookings    domain.BookingRepository
+	vouchers    domain.VoucherRepository
+	payments    domain.PaymentRepository
+	idempotency domain.IdempotencyStore
+	outbox      domain.Outbox
+}
+
+func newRepositories(cfg *config.Config) (*repositories, error) {
+	if cfg.Storage != "postgres" {
+		bookings := storage.NewMemoryRepo()
+		return &repositories{
+			bookings:    bookings,
+			vouchers:    bookings.Vouchers(),
+			payments:    storage.NewMemoryPayments(),
+			idempotency: storage.NewMemoryIdempotency(),
+			outbox:      bookings.Outbox(),
+		}, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	db, err := storage.OpenPostgres(ctx, cfg.PostgresURL)
+	if err != nil {
+		return nil, err
+	}
+	return &repositories{
+		bookings:    storage.NewPostgresRepo(db),
+		vouchers:    storage.NewPostgresVouchers(db),
+		payments:    storage.NewPostgresPayments(db),
+		idempotency: storage.NewPostgresIdempotency(db),
+		outbox:      storage.NewPostgresOutbox(db),
+	}, nil
 }
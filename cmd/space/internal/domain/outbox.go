@@ -0,0 +1,14 @@
+package domain
+
+import "templespace/internal/outbox"
+
+// OutboxMessage is an event stored with the change it reports and published afterwards by the
+// relay, so a broker outage cannot lose it.
+type OutboxMessage = outbox.Message
+
+// OutboxStats describes the unpublished backlog; Oldest is zero when it is empty.
+type OutboxStats = outbox.Stats
+
+// Outbox stores the events of space writes, which the repositories append under the same lock as the
+// write they report.
+type Outbox = outbox.Store
@@ -0,0 +1,172 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Pricing rule kinds.
+const (
+	RuleWeekly           = "weekly"
+	RuleDateRange        = "date_range"
+	RuleDurationDiscount = "duration_discount"
+)
+
+// PricingRule adjusts the hourly price of a space. A weekly rule multiplies the rate on Weekdays (every
+// day when empty) from From to To ("HH:MM", the whole day when both are empty). A date_range rule
+// multiplies it from StartDate through EndDate ("YYYY-MM-DD") and overrides weekly rules on those days.
+// A duration_discount rule takes DiscountPercent off bookings lasting at least MinHours; only the
+// largest applicable discount is given. Times and dates are read in the time zone of the space's
+// opening hours, or UTC when it has none.
+type PricingRule struct {
+	ID              string    `json:"id"`
+	SpaceID         string    `json:"space_id"`
+	Name            string    `json:"name"`
+	Kind            string    `json:"kind"`
+	Weekdays        []string  `json:"weekdays,omitempty"`
+	From            string    `json:"from,omitempty"`
+	To              string    `json:"to,omitempty"`
+	StartDate       string    `json:"start_date,omitempty"`
+	EndDate         string    `json:"end_date,omitempty"`
+	Multiplier      float64   `json:"multiplier,omitempty"`
+	MinHours        float64   `json:"min_hours,omitempty"`
+	DiscountPercent float64   `json:"discount_percent,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+const dateLayout = "2006-01-02"
+
+// Validate checks that the rule is well formed and sets only the fields of its kind.
+func (r *PricingRule) Validate() error {
+	if r.Name == "" {
+		return errors.New("name required")
+	}
+	weekly := len(r.Weekdays) > 0 || r.From != "" || r.To != ""
+	dated := r.StartDate != "" || r.EndDate != ""
+	discount := r.MinHours != 0 || r.DiscountPercent != 0
+	switch r.Kind {
+	case RuleWeekly:
+		if dated || discount {
+			return errors.New("weekly rule takes only weekdays, from, to and multiplier")
+		}
+		seen := map[string]bool{}
+		for _, d := range r.Weekdays {
+			if !weekdays[d] || seen[d] {
+				return fmt.Errorf("invalid or repeated weekday %q", d)
+			}
+			seen[d] = true
+		}
+		if (r.From == "") != (r.To == "") {
+			return errors.New("from and to must be set together")
+		}
+		from, to, err := r.ClockRange()
+		if err != nil {
+			return err
+		}
+		if from >= to {
+			return fmt.Errorf("from %s must be before to %s", r.From, r.To)
+		}
+	case RuleDateRange:
+		if weekly || discount {
+			return errors.New("date_range rule takes only start_date, end_date and multiplier")
+		}
+		start, err1 := time.Parse(dateLayout, r.StartDate)
+		end, err2 := time.Parse(dateLayout, r.EndDate)
+		if err := errors.Join(err1, err2); err != nil {
+			return fmt.Errorf("dates must be YYYY-MM-DD: %w", err)
+		}
+		if end.Before(start) {
+			return errors.New("end_date must not be before start_date")
+		}
+	case RuleDurationDiscount:
+		if weekly || dated || r.Multiplier != 0 {
+			return errors.New("duration_discount rule takes only min_hours and discount_percent")
+		}
+		if r.MinHours <= 0 {
+			return errors.New("min_hours must be positive")
+		}
+		if r.DiscountPercent <= 0 || r.DiscountPercent >= 100 {
+			return errors.New("discount_percent must be between 0 and 100")
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid kind %q", r.Kind)
+	}
+	if r.Multiplier <= 0 {
+		return errors.New("multiplier must be positive")
+	}
+	return nil
+}
+
+// ClockRange returns the daily range of a weekly rule as offsets from midnight; empty means all day.
+func (r *PricingRule) ClockRange() (from, to time.Duration, err error) {
+	if r.From == "" && r.To == "" {
+		return 0, 24 * time.Hour, nil
+	}
+	if from, err = ParseClock(r.From); err != nil {
+		return 0, 0, err
+	}
+	if to, err = ParseClock(r.To); err != nil {
+		return 0, 0, err
+	}
+	return from, to, nil
+}
+
+// CheckRuleConflicts reports, wrapping ErrConflict, a valid rule that contradicts one of others, the
+// rules already set on its space: weekly rules sharing a weekday and time, overlapping date ranges, or
+// discounts with the same threshold or a longer threshold that does not give a larger discount.
+func CheckRuleConflicts(r *PricingRule, others []*PricingRule) error {
+	for _, o := range others {
+		if o.ID == r.ID || o.Kind != r.Kind {
+			continue
+		}
+		switch r.Kind {
+		case RuleWeekly:
+			rFrom, rTo, _ := r.ClockRange()
+			oFrom, oTo, _ := o.ClockRange()
+			if shareWeekday(r.Weekdays, o.Weekdays) && rFrom < oTo && oFrom < rTo {
+				return fmt.Errorf("%w: overlaps weekly rule %q", ErrConflict, o.Name)
+			}
+		case RuleDateRange:
+			if r.StartDate <= o.EndDate && o.StartDate <= r.EndDate {
+				return fmt.Errorf("%w: overlaps date_range rule %q", ErrConflict, o.Name)
+			}
+		case RuleDurationDiscount:
+			if r.MinHours == o.MinHours {
+				return fmt.Errorf("%w: discount rule %q already starts at %g hours", ErrConflict, o.Name, o.MinHours)
+			}
+			if (r.MinHours > o.MinHours) != (r.DiscountPercent > o.DiscountPercent) {
+				return fmt.Errorf("%w: longer bookings must get a larger discount than with rule %q", ErrConflict, o.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// shareWeekday reports whether two weekday sets intersect; an empty set means every day.
+func shareWeekday(a, b []string) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return true
+	}
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type PricingRuleRepository interface {
+	// SaveRule inserts or replaces r, atomically checking it against the other rules of its space
+	// with CheckRuleConflicts.
+	SaveRule(ctx context.Context, r *PricingRule, events ...OutboxMessage) error
+	GetRule(ctx context.Context, spaceID, id string) (*PricingRule, error)
+	DeleteRule(ctx context.Context, spaceID, id string, events ...OutboxMessage) error
+	// ListRules returns the rules of spaceID in creation order.
+	ListRules(ctx context.Context, spaceID string) ([]*PricingRule, error)
+}
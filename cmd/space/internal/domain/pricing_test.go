@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPricingRuleValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		rule PricingRule
+		ok   bool
+	}{
+		{"weekly", PricingRule{Name: "Evening", Kind: RuleWeekly, Weekdays: []string{"mon", "fri"}, From: "18:00", To: "24:00", Multiplier: 1.5}, true},
+		{"weekly all day", PricingRule{Name: "Weekend", Kind: RuleWeekly, Weekdays: []string{"sat", "sun"}, Multiplier: 2}, true},
+		{"date range of one day", PricingRule{Name: "Holiday", Kind: RuleDateRange, StartDate: "2030-12-25", EndDate: "2030-12-25", Multiplier: 2}, true},
+		{"discount", PricingRule{Name: "Long", Kind: RuleDurationDiscount, MinHours: 4, DiscountPercent: 10}, true},
+		{"no name", PricingRule{Kind: RuleWeekly, Multiplier: 1.5}, false},
+		{"unknown kind", PricingRule{Name: "x", Kind: "monthly", Multiplier: 1.5}, false},
+		{"unknown weekday", PricingRule{Name: "x", Kind: RuleWeekly, Weekdays: []string{"monday"}, Multiplier: 1.5}, false},
+		{"repeated weekday", PricingRule{Name: "x", Kind: RuleWeekly, Weekdays: []string{"mon", "mon"}, Multiplier: 1.5}, false},
+		{"from without to", PricingRule{Name: "x", Kind: RuleWeekly, From: "18:00", Multiplier: 1.5}, false},
+		{"from after to", PricingRule{Name: "x", Kind: RuleWeekly, From: "20:00", To: "18:00", Multiplier: 1.5}, false},
+		{"empty hours", PricingRule{Name: "x", Kind: RuleWeekly, From: "18:00", To: "18:00", Multiplier: 1.5}, false},
+		{"malformed time", PricingRule{Name: "x", Kind: RuleWeekly, From: "6pm", To: "24:00", Multiplier: 1.5}, false},
+		{"weekly with dates", PricingRule{Name: "x", Kind: RuleWeekly, StartDate: "2030-12-25", Multiplier: 1.5}, false},
+		{"zero multiplier", PricingRule{Name: "x", Kind: RuleWeekly}, false},
+		{"negative multiplier", PricingRule{Name: "x", Kind: RuleDateRange, StartDate: "2030-12-25", EndDate: "2030-12-26", Multiplier: -1}, false},
+		{"malformed date", PricingRule{Name: "x", Kind: RuleDateRange, StartDate: "25.12.2030", EndDate: "2030-12-26", Multiplier: 2}, false},
+		{"dates reversed", PricingRule{Name: "x", Kind: RuleDateRange, StartDate: "2030-12-26", EndDate: "2030-12-25", Multiplier: 2}, false},
+		{"date range with weekdays", PricingRule{Name: "x", Kind: RuleDateRange, Weekdays: []string{"mon"}, StartDate: "2030-12-25", EndDate: "2030-12-26", Multiplier: 2}, false},
+		{"discount with multiplier", PricingRule{Name: "x", Kind: RuleDurationDiscount, MinHours: 4, DiscountPercent: 10, Multiplier: 1.5}, false},
+		{"discount without threshold", PricingRule{Name: "x", Kind: RuleDurationDiscount, DiscountPercent: 10}, false},
+		{"full discount", PricingRule{Name: "x", Kind: RuleDurationDiscount, MinHours: 4, DiscountPercent: 100}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.rule.Validate(); (err == nil) != tt.ok {
+				t.Fatalf("Validate() = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestCheckRuleConflicts(t *testing.T) {
+	others := []*PricingRule{
+		{ID: "evening", Name: "Evening", Kind: RuleWeekly, Weekdays: []string{"mon", "tue"}, From: "18:00", To: "22:00", Multiplier: 1.5},
+		{ID: "holidays", Name: "Holidays", Kind: RuleDateRange, StartDate: "2030-12-24", EndDate: "2030-12-26", Multiplier: 2},
+		{ID: "long", Name: "Long", Kind: RuleDurationDiscount, MinHours: 4, DiscountPercent: 10},
+	}
+	tests := []struct {
+		name     string
+		rule     PricingRule
+		conflict bool
+	}{
+		{"overlapping hours", PricingRule{Kind: RuleWeekly, Weekdays: []string{"tue"}, From: "21:00", To: "23:00"}, true},
+		{"every day overlaps", PricingRule{Kind: RuleWeekly, From: "19:00", To: "20:00"}, true},
+		{"all day overlaps", PricingRule{Kind: RuleWeekly, Weekdays: []string{"mon"}}, true},
+		{"adjacent hours", PricingRule{Kind: RuleWeekly, Weekdays: []string{"mon"}, From: "22:00", To: "24:00"}, false},
+		{"hours ending where it starts", PricingRule{Kind: RuleWeekly, Weekdays: []string{"mon"}, From: "08:00", To: "18:00"}, false},
+		{"other weekdays", PricingRule{Kind: RuleWeekly, Weekdays: []string{"wed"}, From: "18:00", To: "22:00"}, false},
+		{"the rule itself", PricingRule{ID: "evening", Kind: RuleWeekly, Weekdays: []string{"mon"}, From: "17:00", To: "23:00"}, false},
+		{"overlapping dates", PricingRule{Kind: RuleDateRange, StartDate: "2030-12-26", EndDate: "2030-12-31"}, true},
+		{"enclosing dates", PricingRule{Kind: RuleDateRange, StartDate: "2030-12-01", EndDate: "2030-12-31"}, true},
+		{"adjacent dates", PricingRule{Kind: RuleDateRange, StartDate: "2030-12-27", EndDate: "2030-12-31"}, false},
+		{"dates before", PricingRule{Kind: RuleDateRange, StartDate: "2030-12-20", EndDate: "2030-12-23"}, false},
+		{"same threshold", PricingRule{Kind: RuleDurationDiscount, MinHours: 4, DiscountPercent: 20}, true},
+		{"longer, smaller discount", PricingRule{Kind: RuleDurationDiscount, MinHours: 8, DiscountPercent: 5}, true},
+		{"shorter, larger discount", PricingRule{Kind: RuleDurationDiscount, MinHours: 2, DiscountPercent: 15}, true},
+		{"longer, larger discount", PricingRule{Kind: RuleDurationDiscount, MinHours: 8, DiscountPercent: 20}, false},
+		{"shorter, smaller discount", PricingRule{Kind: RuleDurationDiscount, MinHours: 2, DiscountPercent: 5}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.rule.Name = tt.name
+			err := CheckRuleConflicts(&tt.rule, others)
+			if got := errors.Is(err, ErrConflict); got != tt.conflict || err != nil && !got {
+				t.Fatalf("CheckRuleConflicts() = %v, want conflict %v", err, tt.conflict)
+			}
+		})
+	}
+}
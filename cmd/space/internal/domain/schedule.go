@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// OpeningHours is the weekly schedule of a space in its own time zone. Days without ranges are closed.
+type OpeningHours struct {
+	TimeZone string     `json:"time_zone"`
+	Weekly   []DayHours `json:"weekly"`
+}
+
+// DayHours opens a space on Weekday ("mon" … "sun") from Open to Close, both "HH:MM"; Close may be "24:00".
+type DayHours struct {
+	Weekday string `json:"weekday"`
+	Open    string `json:"open"`
+	Close   string `json:"close"`
+}
+
+var weekdays = map[string]bool{"mon": true, "tue": true, "wed": true, "thu": true, "fri": true, "sat": true, "sun": true}
+
+// Validate checks the zone, weekday names and that every range is well formed.
+func (h *OpeningHours) Validate() error {
+	if _, err := time.LoadLocation(h.TimeZone); err != nil || h.TimeZone == "" {
+		return fmt.Errorf("invalid time_zone %q", h.TimeZone)
+	}
+	for _, d := range h.Weekly {
+		if !weekdays[d.Weekday] {
+			return fmt.Errorf("invalid weekday %q", d.Weekday)
+		}
+		open, err := ParseClock(d.Open)
+		if err != nil {
+			return err
+		}
+		closeAt, err := ParseClock(d.Close)
+		if err != nil {
+			return err
+		}
+		if open >= closeAt {
+			return fmt.Errorf("%s: open %s must be before close %s", d.Weekday, d.Open, d.Close)
+		}
+	}
+	return nil
+}
+
+// ParseClock parses "HH:MM" (00:00 to 24:00) into the offset from midnight.
+func ParseClock(s string) (time.Duration, error) {
+	var h, m int
+	if n, err := fmt.Sscanf(s, "%d:%d", &h, &m); err != nil || n != 2 || len(s) != 5 {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	if h < 0 || m < 0 || m > 59 || h > 24 || (h == 24 && m != 0) {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
+}
+
+// Blackout closes a space for a one-off period such as a holiday or maintenance.
+type Blackout struct {
+	ID        string    `json:"id"`
+	SpaceID   string    `json:"space_id"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type BlackoutRepository interface {
+	AddBlackout(ctx context.Context, b *Blackout, events ...OutboxMessage) error
+	DeleteBlackout(ctx context.Context, spaceID, id string, events ...OutboxMessage) error
+	// ListBlackouts returns the blackouts of spaceID overlapping [from, to), ordered by start;
+	// zero bounds are open.
+	ListBlackouts(ctx context.Context, spaceID string, from, to time.Time) ([]*Blackout, error)
+}
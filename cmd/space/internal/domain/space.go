@@ -2,19 +2,30 @@ package domain
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
+var (
+	ErrNotFound  = errors.New("not found")
+	ErrForbidden = errors.New("forbidden")
+	// ErrConflict reports a change that contradicts existing state, such as overlapping pricing rules.
+	ErrConflict = errors.New("conflict")
+)
+
 type Space struct {
 	ID           string         `json:"id"`
+	OwnerID      string         `json:"owner_id"`
 	Name         string         `json:"name"`
 	Location     string         `json:"location"`
 	Tags         []string       `json:"tags"`
 	Attributes   map[string]any `json:"attributes"`
 	PricePerHour float64        `json:"price_per_hour"`
-	CreatedAt    time.Time      `json:"created_at"`
-	UpdatedAt    time.Time      `json:"updated_at"`
-	Version      int64          `json:"version"`
+	// OpeningHours limits when the space can be booked; nil means always open.
+	OpeningHours *OpeningHours `json:"opening_hours,omitempty"`
+	CreatedAt    time.Time     `json:"created_at"`
+	UpdatedAt    time.Time     `json:"updated_at"`
+	Version      int64         `json:"version"`
 }
 
 type SpacePhoto struct {
@@ -24,9 +35,11 @@ type SpacePhoto struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// SpaceRepository, BlackoutRepository and PricingRuleRepository store the events passed to a write
+// in the outbox together with the change they report: both are kept or neither is.
 type SpaceRepository interface {
-	Create(ctx context.Context, s *Space) error
-	Update(ctx context.Context, s *Space) error
+	Create(ctx context.Context, s *Space, events ...OutboxMessage) error
+	Update(ctx context.Context, s *Space, events ...OutboxMessage) error
 	GetByID(ctx context.Context, id string) (*Space, error)
 	List(ctx context.Context) ([]*Space, error)
 }
@@ -0,0 +1,125 @@
+package events
+
+import (
+	"time"
+
+	"templespace/cmd/space/internal/domain"
+)
+
+// The payloads below are the published contract. They are copied from domain types, never shared
+// with them, so a domain change cannot alter an event by accident; changing a payload means a new
+// version with its own schema.
+
+// SpaceV1 is the payload of space_created and space_updated.
+type SpaceV1 struct {
+	ID           string          `json:"id"`
+	OwnerID      string          `json:"owner_id"`
+	Name         string          `json:"name"`
+	Location     string          `json:"location"`
+	Tags         []string        `json:"tags"`
+	Attributes   map[string]any  `json:"attributes"`
+	PricePerHour float64         `json:"price_per_hour"`
+	OpeningHours *OpeningHoursV1 `json:"opening_hours,omitempty"`
+	Version      int64           `json:"version"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+}
+
+type OpeningHoursV1 struct {
+	TimeZone string       `json:"time_zone"`
+	Weekly   []DayHoursV1 `json:"weekly"`
+}
+
+type DayHoursV1 struct {
+	Weekday string `json:"weekday"`
+	Open    string `json:"open"`
+	Close   string `json:"close"`
+}
+
+type BlackoutV1 struct {
+	ID        string    `json:"id"`
+	SpaceID   string    `json:"space_id"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PricingRuleV1 carries the fields of its kind; the others are omitted.
+type PricingRuleV1 struct {
+	ID              string    `json:"id"`
+	SpaceID         string    `json:"space_id"`
+	Name            string    `json:"name"`
+	Kind            string    `json:"kind"`
+	Weekdays        []string  `json:"weekdays,omitempty"`
+	From            string    `json:"from,omitempty"`
+	To              string    `json:"to,omitempty"`
+	StartDate       string    `json:"start_date,omitempty"`
+	EndDate         string    `json:"end_date,omitempty"`
+	Multiplier      float64   `json:"multiplier,omitempty"`
+	MinHours        float64   `json:"min_hours,omitempty"`
+	DiscountPercent float64   `json:"discount_percent,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// RemovedV1 is the payload of space_blackout_removed and space_pricing_rule_removed.
+type RemovedV1 struct {
+	ID      string `json:"id"`
+	SpaceID string `json:"space_id"`
+}
+
+func Space(sp *domain.Space) SpaceV1 {
+	out := SpaceV1{
+		ID:           sp.ID,
+		OwnerID:      sp.OwnerID,
+		Name:         sp.Name,
+		Location:     sp.Location,
+		Tags:         append([]string{}, sp.Tags...),
+		Attributes:   sp.Attributes,
+		PricePerHour: sp.PricePerHour,
+		Version:      sp.Version,
+		CreatedAt:    sp.CreatedAt.UTC(),
+		UpdatedAt:    sp.UpdatedAt.UTC(),
+	}
+	if out.Attributes == nil {
+		out.Attributes = map[string]any{}
+	}
+	if h := sp.OpeningHours; h != nil {
+		out.OpeningHours = &OpeningHoursV1{TimeZone: h.TimeZone, Weekly: []DayHoursV1{}}
+		for _, d := range h.Weekly {
+			out.OpeningHours.Weekly = append(out.OpeningHours.Weekly, DayHoursV1(d))
+		}
+	}
+	return out
+}
+
+func Blackout(b *domain.Blackout) BlackoutV1 {
+	return BlackoutV1{
+		ID:        b.ID,
+		SpaceID:   b.SpaceID,
+		Start:     b.Start.UTC(),
+		End:       b.End.UTC(),
+		Reason:    b.Reason,
+		CreatedAt: b.CreatedAt.UTC(),
+	}
+}
+
+func PricingRule(r *domain.PricingRule) PricingRuleV1 {
+	return PricingRuleV1{
+		ID:              r.ID,
+		SpaceID:         r.SpaceID,
+		Name:            r.Name,
+		Kind:            r.Kind,
+		Weekdays:        r.Weekdays,
+		From:            r.From,
+		To:              r.To,
+		StartDate:       r.StartDate,
+		EndDate:         r.EndDate,
+		Multiplier:      r.Multiplier,
+		MinHours:        r.MinHours,
+		DiscountPercent: r.DiscountPercent,
+		CreatedAt:       r.CreatedAt.UTC(),
+		UpdatedAt:       r.UpdatedAt.UTC(),
+	}
+}
@@ -0,0 +1,59 @@
+// Package events defines what the space service publishes: cloudevents envelopes around the
+// versioned payloads of this package, with a JSON Schema registered for each event type.
+package events
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+	"time"
+
+	"templespace/internal/cloudevents"
+)
+
+const (
+	// Source identifies the space service as the producer.
+	Source      = "templespace/space"
+	ContentType = cloudevents.ContentType
+	SchemaPath  = cloudevents.SchemaPath
+)
+
+//go:embed schemas/*.json
+var schemaFiles embed.FS
+
+// registry maps every event type to the schema file of its current payload version. A breaking
+// payload change adds a new file, e.g. space.v2.json, and points the types at it.
+var registry = cloudevents.NewRegistry(Source, mustSub(), map[string]string{
+	"space_created":              "space.v1.json",
+	"space_updated":              "space.v1.json",
+	"space_blackout_added":       "blackout.v1.json",
+	"space_blackout_removed":     "removed.v1.json",
+	"space_pricing_rule_added":   "pricing_rule.v1.json",
+	"space_pricing_rule_updated": "pricing_rule.v1.json",
+	"space_pricing_rule_removed": "removed.v1.json",
+})
+
+// Encode wraps data, one of the payload types of this package, in an envelope of typ about subject,
+// e.g. a space ID. Payloads are not validated here; the package tests check each of them against
+// its schema.
+func Encode(typ, subject string, at time.Time, data any) ([]byte, error) {
+	return registry.Encode(typ, subject, at, data)
+}
+
+// Types returns the registered event types in name order.
+func Types() []string { return registry.Types() }
+
+// Validate checks the data of an event of type typ against its registered schema.
+func Validate(typ string, data []byte) error { return registry.Validate(typ, data) }
+
+// SchemaHandler serves GET SchemaPath, mapping event types to their dataschema, and the schema
+// files below it.
+func SchemaHandler() http.Handler { return registry.SchemaHandler() }
+
+func mustSub() fs.FS {
+	sub, err := fs.Sub(schemaFiles, "schemas")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
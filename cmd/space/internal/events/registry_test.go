@@ -0,0 +1,72 @@
+package events
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"templespace/cmd/space/internal/domain"
+	"templespace/internal/cloudevents"
+)
+
+// samples holds a payload for every registered event type, built through the same functions the
+// service uses, so a payload drifting from its schema fails here instead of in production.
+func samples() map[string]any {
+	at := time.Date(2030, 3, 4, 9, 0, 0, 0, time.UTC)
+	sp := &domain.Space{
+		ID:           "space-1",
+		OwnerID:      "olivia",
+		Name:         "Loft",
+		Location:     "Berlin",
+		Tags:         []string{"quiet"},
+		Attributes:   map[string]any{"capacity": 8, "time_zone": "Europe/Berlin"},
+		PricePerHour: 25,
+		OpeningHours: &domain.OpeningHours{
+			TimeZone: "Europe/Berlin",
+			Weekly:   []domain.DayHours{{Weekday: "mon", Open: "09:00", Close: "18:00"}},
+		},
+		CreatedAt: at,
+		UpdatedAt: at,
+		Version:   2,
+	}
+	blackout := &domain.Blackout{ID: "bo-1", SpaceID: sp.ID, Start: at, End: at.Add(24 * time.Hour), Reason: "maintenance", CreatedAt: at}
+	rule := &domain.PricingRule{
+		ID: "pr-1", SpaceID: sp.ID, Name: "Weekend", Kind: "weekly", Weekdays: []string{"sat", "sun"},
+		From: "10:00", To: "18:00", Multiplier: 1.5, CreatedAt: at, UpdatedAt: at,
+	}
+	removed := RemovedV1{ID: "x-1", SpaceID: sp.ID}
+	return map[string]any{
+		"space_created":              Space(sp),
+		"space_updated":              Space(sp),
+		"space_blackout_added":       Blackout(blackout),
+		"space_blackout_removed":     removed,
+		"space_pricing_rule_added":   PricingRule(rule),
+		"space_pricing_rule_updated": PricingRule(rule),
+		"space_pricing_rule_removed": removed,
+	}
+}
+
+func TestPayloadsMatchSchemas(t *testing.T) {
+	payloads := samples()
+	for _, typ := range Types() {
+		data, ok := payloads[typ]
+		if !ok {
+			t.Errorf("%s: no sample payload", typ)
+			continue
+		}
+		raw, err := Encode(typ, "space-1", time.Now(), data)
+		if err != nil {
+			t.Fatalf("%s: %v", typ, err)
+		}
+		env, err := cloudevents.Decode(raw)
+		if err != nil {
+			t.Fatalf("%s: %v", typ, err)
+		}
+		if env.Type != typ || env.Source != Source || !strings.HasPrefix(env.DataSchema, SchemaPath) {
+			t.Errorf("%s: envelope %+v", typ, env)
+		}
+		if err := Validate(typ, env.Data); err != nil {
+			t.Error(err)
+		}
+	}
+}
@@ -97,7 +97,7 @@ func (h *Handlers) handleUpdateSpace(w http.ResponseWriter, r *http.Request) {
 	sp := &domain.Space{ID: id, Name: req.Name, Location: req.Location, Tags: req.Tags, Attributes: req.Attributes, PricePerHour: req.PricePerHour, UpdatedAt: time.Now().UTC()}
 	out, err := h.svc.UpdateSpace(r.Context(), access, sp)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, err)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -0,0 +1,68 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"templespace/cmd/space/internal/domain"
+)
+
+// handlePricingRules serves GET and POST /spaces/{id}/pricing-rules.
+func (h *Handlers) handlePricingRules(w http.ResponseWriter, r *http.Request, spaceID string) {
+	switch r.Method {
+	case http.MethodGet:
+		list, err := h.svc.ListPricingRules(r.Context(), spaceID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, list)
+	case http.MethodPost:
+		var rule domain.PricingRule
+		if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		out, err := h.svc.AddPricingRule(r.Context(), r.Header.Get("Authorization"), spaceID, &rule)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, out)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// handlePricingRule serves GET, PUT and DELETE /spaces/{id}/pricing-rules/{ruleID}.
+func (h *Handlers) handlePricingRule(w http.ResponseWriter, r *http.Request, spaceID, ruleID string) {
+	switch r.Method {
+	case http.MethodGet:
+		rule, err := h.svc.GetPricingRule(r.Context(), spaceID, ruleID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, rule)
+	case http.MethodPut:
+		var rule domain.PricingRule
+		if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		out, err := h.svc.UpdatePricingRule(r.Context(), r.Header.Get("Authorization"), spaceID, ruleID, &rule)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, out)
+	case http.MethodDelete:
+		if err := h.svc.DeletePricingRule(r.Context(), r.Header.Get("Authorization"), spaceID, ruleID); err != nil {
+			writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
@@ -1,7 +1,10 @@
 package http
 
 import (
+	"expvar"
 	stdhttp "net/http"
+
+	"templespace/cmd/space/internal/events"
 )
 
 func NewRouter(h *Handlers) *stdhttp.ServeMux {
@@ -22,7 +25,9 @@ func NewRouter(h *Handlers) *stdhttp.ServeMux {
 			w.WriteHeader(stdhttp.StatusMethodNotAllowed)
 		}
 	})
-	mux.HandleFunc("/spaces/", h.handleUpdateSpace) // expects PUT /spaces/{id}
+	mux.Handle("/debug/vars", expvar.Handler())   // outbox relay metrics among others
+	mux.HandleFunc("/spaces/", h.handleSpaceItem) // PUT /spaces/{id}, /spaces/{id}/hours, /spaces/{id}/blackouts, /spaces/{id}/pricing-rules
+	mux.Handle(events.SchemaPath, events.SchemaHandler())
 
 	return mux
 }
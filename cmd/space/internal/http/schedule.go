@@ -0,0 +1,139 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"templespace/cmd/space/internal/domain"
+)
+
+// handleSpaceItem routes /spaces/{id}, /spaces/{id}/hours, /spaces/{id}/blackouts[/{blackoutID}] and
+// /spaces/{id}/pricing-rules[/{ruleID}].
+func (h *Handlers) handleSpaceItem(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/spaces/"), "/"), "/")
+	switch {
+	case len(parts) == 1:
+		h.handleUpdateSpace(w, r)
+	case len(parts) == 2 && parts[1] == "hours":
+		h.handleOpeningHours(w, r, parts[0])
+	case len(parts) == 2 && parts[1] == "blackouts":
+		h.handleBlackouts(w, r, parts[0])
+	case len(parts) == 3 && parts[1] == "blackouts" && r.Method == http.MethodDelete:
+		if err := h.svc.DeleteBlackout(r.Context(), r.Header.Get("Authorization"), parts[0], parts[2]); err != nil {
+			writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case len(parts) == 2 && parts[1] == "pricing-rules":
+		h.handlePricingRules(w, r, parts[0])
+	case len(parts) == 3 && parts[1] == "pricing-rules":
+		h.handlePricingRule(w, r, parts[0], parts[2])
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (h *Handlers) handleOpeningHours(w http.ResponseWriter, r *http.Request, spaceID string) {
+	switch r.Method {
+	case http.MethodGet:
+		sp, err := h.svc.GetSpace(r.Context(), spaceID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, sp.OpeningHours)
+	case http.MethodPut:
+		var hours *domain.OpeningHours
+		if err := json.NewDecoder(r.Body).Decode(&hours); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		sp, err := h.svc.SetOpeningHours(r.Context(), r.Header.Get("Authorization"), spaceID, hours)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, sp.OpeningHours)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+type createBlackoutRequest struct {
+	Start  string `json:"start"`
+	End    string `json:"end"`
+	Reason string `json:"reason"`
+}
+
+func (h *Handlers) handleBlackouts(w http.ResponseWriter, r *http.Request, spaceID string) {
+	switch r.Method {
+	case http.MethodGet:
+		var from, to time.Time
+		var err error
+		if v := r.URL.Query().Get("from"); v != "" {
+			if from, err = time.Parse(time.RFC3339, v); err != nil {
+				http.Error(w, "invalid from", http.StatusBadRequest)
+				return
+			}
+		}
+		if v := r.URL.Query().Get("to"); v != "" {
+			if to, err = time.Parse(time.RFC3339, v); err != nil {
+				http.Error(w, "invalid to", http.StatusBadRequest)
+				return
+			}
+		}
+		list, err := h.svc.ListBlackouts(r.Context(), spaceID, from, to)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, list)
+	case http.MethodPost:
+		var req createBlackoutRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		start, err := time.Parse(time.RFC3339, req.Start)
+		if err != nil {
+			http.Error(w, "invalid start", http.StatusBadRequest)
+			return
+		}
+		end, err := time.Parse(time.RFC3339, req.End)
+		if err != nil {
+			http.Error(w, "invalid end", http.StatusBadRequest)
+			return
+		}
+		b, err := h.svc.AddBlackout(r.Context(), r.Header.Get("Authorization"), spaceID, start, end, req.Reason)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, b)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError maps domain errors onto HTTP status codes.
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusBadRequest
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, domain.ErrForbidden):
+		status = http.StatusForbidden
+	case errors.Is(err, domain.ErrConflict):
+		status = http.StatusConflict
+	}
+	http.Error(w, err.Error(), status)
+}
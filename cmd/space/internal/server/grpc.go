@@ -6,6 +6,7 @@ import (
 	"context"
 	"log"
 	"net"
+	"time"
 
 	gogrpc "google.golang.org/grpc"
 	"google.golang.org/protobuf/types/known/structpb"
@@ -34,6 +35,8 @@ func (s *Server) register() {
 			{MethodName: "ListSpaces", Handler: s.handleListSpaces},
 			{MethodName: "CreateSpace", Handler: s.handleCreateSpace},
 			{MethodName: "UpdateSpace", Handler: s.handleUpdateSpace},
+			{MethodName: "GetSchedule", Handler: s.handleGetSchedule},
+			{MethodName: "GetPricing", Handler: s.handleGetPricing},
 		},
 		Streams:  []gogrpc.StreamDesc{},
 		Metadata: "space.proto",
@@ -101,6 +104,36 @@ func (s *Server) handleUpdateSpace(_ interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
+func (s *Server) handleGetSchedule(_ interface{}, ctx context.Context, dec func(interface{}) error, interceptor gogrpc.UnaryServerInterceptor) (interface{}, error) {
+	in := &structpb.Struct{}
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return s.getSchedule(ctx, in)
+	}
+	info := &gogrpc.UnaryServerInfo{Server: s, FullMethod: "/space.SpaceService/GetSchedule"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return s.getSchedule(ctx, req.(*structpb.Struct))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func (s *Server) handleGetPricing(_ interface{}, ctx context.Context, dec func(interface{}) error, interceptor gogrpc.UnaryServerInterceptor) (interface{}, error) {
+	in := &structpb.Struct{}
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return s.getPricing(ctx, in)
+	}
+	info := &gogrpc.UnaryServerInfo{Server: s, FullMethod: "/space.SpaceService/GetPricing"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return s.getPricing(ctx, req.(*structpb.Struct))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func (s *Server) getSpace(ctx context.Context, in *structpb.Struct) (*structpb.Struct, error) {
 	idv, _ := in.Fields["id"]
 	if idv == nil {
@@ -167,6 +200,79 @@ func (s *Server) updateSpace(ctx context.Context, in *structpb.Struct) (*structp
 	return structpb.NewStruct(spaceToMap(out))
 }
 
+// getSchedule returns the opening hours of a space and its blackouts overlapping [from, to).
+func (s *Server) getSchedule(ctx context.Context, in *structpb.Struct) (*structpb.Struct, error) {
+	spaceID := getString(in, "space_id")
+	sp, err := s.svc.GetSpace(ctx, spaceID)
+	if err != nil {
+		return structpb.NewStruct(map[string]any{"error": err.Error()})
+	}
+	from, _ := time.Parse(time.RFC3339, getString(in, "from"))
+	to, _ := time.Parse(time.RFC3339, getString(in, "to"))
+	blackouts, err := s.svc.ListBlackouts(ctx, spaceID, from, to)
+	if err != nil {
+		return structpb.NewStruct(map[string]any{"error": err.Error()})
+	}
+	out := map[string]any{"space_id": spaceID}
+	if h := sp.OpeningHours; h != nil {
+		weekly := make([]any, 0, len(h.Weekly))
+		for _, d := range h.Weekly {
+			weekly = append(weekly, map[string]any{"weekday": d.Weekday, "open": d.Open, "close": d.Close})
+		}
+		out["opening_hours"] = map[string]any{"time_zone": h.TimeZone, "weekly": weekly}
+	}
+	list := make([]any, 0, len(blackouts))
+	for _, b := range blackouts {
+		list = append(list, map[string]any{
+			"start":  b.Start.Format(time.RFC3339),
+			"end":    b.End.Format(time.RFC3339),
+			"reason": b.Reason,
+		})
+	}
+	out["blackouts"] = list
+	return structpb.NewStruct(out)
+}
+
+// getPricing returns the hourly price of a space with its pricing rules and the time zone they are read in.
+func (s *Server) getPricing(ctx context.Context, in *structpb.Struct) (*structpb.Struct, error) {
+	spaceID := getString(in, "space_id")
+	sp, err := s.svc.GetSpace(ctx, spaceID)
+	if err != nil {
+		return structpb.NewStruct(map[string]any{"error": err.Error()})
+	}
+	rules, err := s.svc.ListPricingRules(ctx, spaceID)
+	if err != nil {
+		return structpb.NewStruct(map[string]any{"error": err.Error()})
+	}
+	currency, _ := sp.Attributes["currency"].(string)
+	list := make([]any, 0, len(rules))
+	for _, r := range rules {
+		days := make([]any, 0, len(r.Weekdays))
+		for _, d := range r.Weekdays {
+			days = append(days, d)
+		}
+		list = append(list, map[string]any{
+			"name":             r.Name,
+			"kind":             r.Kind,
+			"weekdays":         days,
+			"from":             r.From,
+			"to":               r.To,
+			"start_date":       r.StartDate,
+			"end_date":         r.EndDate,
+			"multiplier":       r.Multiplier,
+			"min_hours":        r.MinHours,
+			"discount_percent": r.DiscountPercent,
+		})
+	}
+	return structpb.NewStruct(map[string]any{
+		"space_id":       spaceID,
+		"price_per_hour": sp.PricePerHour,
+		"currency":       currency,
+		"time_zone":      timeZone(sp),
+		"rules":          list,
+	})
+}
+
 func (s *Server) Listen(addr string) error {
 	ln, err := net.Listen("tcp", addr)
 	if err != nil {
@@ -190,14 +296,29 @@ func getFloat(in *structpb.Struct, key string) float64 {
 	return 0
 }
 
+// timeZone is the zone the space's local times are read in: that of its opening hours, else UTC.
+func timeZone(sp *domain.Space) string {
+	if sp.OpeningHours != nil {
+		return sp.OpeningHours.TimeZone
+	}
+	return "UTC"
+}
+
 func spaceToMap(s *domain.Space) map[string]any {
+	// structpb only accepts []any for list values
+	tags := make([]any, 0, len(s.Tags))
+	for _, t := range s.Tags {
+		tags = append(tags, t)
+	}
 	return map[string]any{
 		"id":             s.ID,
+		"owner_id":       s.OwnerID,
 		"name":           s.Name,
 		"location":       s.Location,
-		"tags":           s.Tags,
+		"tags":           tags,
 		"attributes":     s.Attributes,
 		"price_per_hour": s.PricePerHour,
+		"time_zone":      timeZone(s),
 		"created_at":     s.CreatedAt.String(),
 		"updated_at":     s.UpdatedAt.String(),
 	}
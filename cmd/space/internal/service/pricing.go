@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"templespace/cmd/space/internal/domain"
+	"templespace/cmd/space/internal/events"
+)
+
+// AddPricingRule attaches a validated rule to a space. Only the owner of the space may add rules, and
+// a rule contradicting the existing ones fails with domain.ErrConflict.
+func (s *Service) AddPricingRule(ctx context.Context, accessToken, spaceID string, r *domain.PricingRule) (*domain.PricingRule, error) {
+	if _, err := s.ownedSpace(ctx, accessToken, spaceID); err != nil {
+		return nil, err
+	}
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	r.ID = generateID()
+	r.SpaceID = spaceID
+	r.CreatedAt = time.Now().UTC()
+	r.UpdatedAt = r.CreatedAt
+	ev, err := event("space_pricing_rule_added", spaceID, events.PricingRule(r))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.rules.SaveRule(ctx, r, ev); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// UpdatePricingRule replaces rule id of a space with r, under the same checks as AddPricingRule.
+func (s *Service) UpdatePricingRule(ctx context.Context, accessToken, spaceID, id string, r *domain.PricingRule) (*domain.PricingRule, error) {
+	if _, err := s.ownedSpace(ctx, accessToken, spaceID); err != nil {
+		return nil, err
+	}
+	existing, err := s.rules.GetRule(ctx, spaceID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	r.ID = existing.ID
+	r.SpaceID = spaceID
+	r.CreatedAt = existing.CreatedAt
+	r.UpdatedAt = time.Now().UTC()
+	ev, err := event("space_pricing_rule_updated", spaceID, events.PricingRule(r))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.rules.SaveRule(ctx, r, ev); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// DeletePricingRule removes rule id from a space.
+func (s *Service) DeletePricingRule(ctx context.Context, accessToken, spaceID, id string) error {
+	if _, err := s.ownedSpace(ctx, accessToken, spaceID); err != nil {
+		return err
+	}
+	ev, err := event("space_pricing_rule_removed", spaceID, events.RemovedV1{ID: id, SpaceID: spaceID})
+	if err != nil {
+		return err
+	}
+	return s.rules.DeleteRule(ctx, spaceID, id, ev)
+}
+
+func (s *Service) GetPricingRule(ctx context.Context, spaceID, id string) (*domain.PricingRule, error) {
+	return s.rules.GetRule(ctx, spaceID, id)
+}
+
+func (s *Service) ListPricingRules(ctx context.Context, spaceID string) ([]*domain.PricingRule, error) {
+	return s.rules.ListRules(ctx, spaceID)
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"templespace/cmd/space/internal/domain"
+	"templespace/cmd/space/internal/events"
+)
+
+// SetOpeningHours replaces the weekly opening hours of a space; nil makes it always open.
+// Only the owner of the space may change them.
+func (s *Service) SetOpeningHours(ctx context.Context, accessToken, spaceID string, hours *domain.OpeningHours) (*domain.Space, error) {
+	sp, err := s.ownedSpace(ctx, accessToken, spaceID)
+	if err != nil {
+		return nil, err
+	}
+	if hours != nil {
+		if err := hours.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	sp.OpeningHours = hours
+	sp.Version++
+	sp.UpdatedAt = time.Now().UTC()
+	ev, err := event("space_updated", sp.ID, events.Space(sp))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(ctx, sp, ev); err != nil {
+		return nil, err
+	}
+	_ = s.readModel.Index(ctx, sp)
+	return sp, nil
+}
+
+// AddBlackout closes a space for [start, end). Only the owner of the space may add blackouts.
+func (s *Service) AddBlackout(ctx context.Context, accessToken, spaceID string, start, end time.Time, reason string) (*domain.Blackout, error) {
+	if _, err := s.ownedSpace(ctx, accessToken, spaceID); err != nil {
+		return nil, err
+	}
+	if !start.Before(end) {
+		return nil, errors.New("start must be before end")
+	}
+	b := &domain.Blackout{
+		ID:        generateID(),
+		SpaceID:   spaceID,
+		Start:     start.UTC(),
+		End:       end.UTC(),
+		Reason:    reason,
+		CreatedAt: time.Now().UTC(),
+	}
+	ev, err := event("space_blackout_added", spaceID, events.Blackout(b))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.blackouts.AddBlackout(ctx, b, ev); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// DeleteBlackout reopens a space closed by blackout id.
+func (s *Service) DeleteBlackout(ctx context.Context, accessToken, spaceID, id string) error {
+	if _, err := s.ownedSpace(ctx, accessToken, spaceID); err != nil {
+		return err
+	}
+	ev, err := event("space_blackout_removed", spaceID, events.RemovedV1{ID: id, SpaceID: spaceID})
+	if err != nil {
+		return err
+	}
+	return s.blackouts.DeleteBlackout(ctx, spaceID, id, ev)
+}
+
+// ListBlackouts returns the blackouts of a space overlapping [from, to); zero bounds are open.
+func (s *Service) ListBlackouts(ctx context.Context, spaceID string, from, to time.Time) ([]*domain.Blackout, error) {
+	return s.blackouts.ListBlackouts(ctx, spaceID, from, to)
+}
+
+// ownedSpace loads spaceID on behalf of its owner.
+func (s *Service) ownedSpace(ctx context.Context, accessToken, spaceID string) (*domain.Space, error) {
+	userID, err := s.auth.Verify(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	sp, err := s.repo.GetByID(ctx, spaceID)
+	if err != nil {
+		return nil, err
+	}
+	if sp.OwnerID != userID {
+		return nil, fmt.Errorf("%w: only the owner may manage space %s", domain.ErrForbidden, spaceID)
+	}
+	return sp, nil
+}
@@ -2,11 +2,11 @@ package service
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"time"
 
 	"templespace/cmd/space/internal/domain"
+	"templespace/cmd/space/internal/events"
 )
 
 type TokenVerifier interface {
@@ -16,40 +16,46 @@ type TokenVerifier interface {
 type Service struct {
 	repo      domain.SpaceRepository
 	photos    domain.PhotoRepository
+	blackouts domain.BlackoutRepository
+	rules     domain.PricingRuleRepository
 	readModel domain.ReadModel
-	events    domain.EventPublisher
 	auth      TokenVerifier
 }
 
-func New(repo domain.SpaceRepository, photos domain.PhotoRepository, readModel domain.ReadModel, events domain.EventPublisher, auth TokenVerifier) *Service {
-	return &Service{repo: repo, photos: photos, readModel: readModel, events: events, auth: auth}
+// New builds the service. The repositories store the events of each write in their outbox, from which
+// an outbox.Relay publishes them.
+func New(repo domain.SpaceRepository, photos domain.PhotoRepository, blackouts domain.BlackoutRepository, rules domain.PricingRuleRepository, readModel domain.ReadModel, auth TokenVerifier) *Service {
+	return &Service{repo: repo, photos: photos, blackouts: blackouts, rules: rules, readModel: readModel, auth: auth}
 }
 
 func (s *Service) CreateSpace(ctx context.Context, accessToken string, sp *domain.Space) (*domain.Space, error) {
-	if _, err := s.auth.Verify(ctx, accessToken); err != nil {
+	userID, err := s.auth.Verify(ctx, accessToken)
+	if err != nil {
 		return nil, err
 	}
 	if sp.Name == "" {
 		return nil, errors.New("name required")
 	}
 	sp.ID = generateID()
+	sp.OwnerID = userID
 	sp.CreatedAt = time.Now().UTC()
 	sp.UpdatedAt = sp.CreatedAt
 	sp.Version = 1
-	if err := s.repo.Create(ctx, sp); err != nil {
+	ev, err := event("space_created", sp.ID, events.Space(sp))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, sp, ev); err != nil {
 		return nil, err
 	}
 	_ = s.readModel.Index(ctx, sp)
-	payload, _ := json.Marshal(sp)
-	_ = s.events.Publish("space_created", sp.ID, payload)
 	return sp, nil
 }
 
+// UpdateSpace replaces the details of a space on behalf of its owner. The booking service trusts the
+// booking policy, capacity and cancellation tiers in the attributes, so nobody else may change them.
 func (s *Service) UpdateSpace(ctx context.Context, accessToken string, sp *domain.Space) (*domain.Space, error) {
-	if _, err := s.auth.Verify(ctx, accessToken); err != nil {
-		return nil, err
-	}
-	existing, err := s.repo.GetByID(ctx, sp.ID)
+	existing, err := s.ownedSpace(ctx, accessToken, sp.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -60,12 +66,14 @@ func (s *Service) UpdateSpace(ctx context.Context, accessToken string, sp *domai
 	existing.PricePerHour = sp.PricePerHour
 	existing.Version++
 	existing.UpdatedAt = time.Now().UTC()
-	if err := s.repo.Update(ctx, existing); err != nil {
+	ev, err := event("space_updated", existing.ID, events.Space(existing))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(ctx, existing, ev); err != nil {
 		return nil, err
 	}
 	_ = s.readModel.Index(ctx, existing)
-	payload, _ := json.Marshal(existing)
-	_ = s.events.Publish("space_updated", existing.ID, payload)
 	return existing, nil
 }
 
@@ -77,6 +85,17 @@ func (s *Service) ListSpaces(ctx context.Context, q domain.Query) ([]*domain.Spa
 	return s.readModel.Search(ctx, q)
 }
 
+// event builds event typ about spaceID for the repository to store with the write it reports; data is
+// one of the events payloads.
+func event(typ, spaceID string, data any) (domain.OutboxMessage, error) {
+	now := time.Now().UTC()
+	payload, err := events.Encode(typ, spaceID, now, data)
+	if err != nil {
+		return domain.OutboxMessage{}, err
+	}
+	return domain.OutboxMessage{Topic: typ, Key: spaceID, Payload: payload, CreatedAt: now}, nil
+}
+
 func generateID() string {
 	return time.Now().UTC().Format("20060102150405.000000000")
 }
@@ -3,21 +3,26 @@ package storage
 import (
 	"context"
 	"errors"
+	"sort"
 	"sync"
+	"time"
 
 	"templespace/cmd/space/internal/domain"
 )
 
+// InMemorySpaces appends the events of a write to outbox under its own lock, so a change and its
+// events become visible together. InMemoryBlackouts and InMemoryPricingRules do the same.
 type InMemorySpaces struct {
-	mu   sync.RWMutex
-	byID map[string]*domain.Space
+	mu     sync.RWMutex
+	byID   map[string]*domain.Space
+	outbox *InMemoryOutbox
 }
 
-func NewInMemorySpaces() *InMemorySpaces {
-	return &InMemorySpaces{byID: make(map[string]*domain.Space)}
+func NewInMemorySpaces(outbox *InMemoryOutbox) *InMemorySpaces {
+	return &InMemorySpaces{byID: make(map[string]*domain.Space), outbox: outbox}
 }
 
-func (m *InMemorySpaces) Create(ctx context.Context, s *domain.Space) error {
+func (m *InMemorySpaces) Create(ctx context.Context, s *domain.Space, events ...domain.OutboxMessage) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	if _, exists := m.byID[s.ID]; exists {
@@ -25,18 +30,18 @@ func (m *InMemorySpaces) Create(ctx context.Context, s *domain.Space) error {
 	}
 	cp := *s
 	m.byID[s.ID] = &cp
-	return nil
+	return m.outbox.Append(ctx, events...)
 }
 
-func (m *InMemorySpaces) Update(ctx context.Context, s *domain.Space) error {
+func (m *InMemorySpaces) Update(ctx context.Context, s *domain.Space, events ...domain.OutboxMessage) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	if _, ok := m.byID[s.ID]; !ok {
-		return errors.New("not found")
+		return domain.ErrNotFound
 	}
 	cp := *s
 	m.byID[s.ID] = &cp
-	return nil
+	return m.outbox.Append(ctx, events...)
 }
 
 func (m *InMemorySpaces) GetByID(ctx context.Context, id string) (*domain.Space, error) {
@@ -44,7 +49,7 @@ func (m *InMemorySpaces) GetByID(ctx context.Context, id string) (*domain.Space,
 	defer m.mu.RUnlock()
 	s, ok := m.byID[id]
 	if !ok {
-		return nil, errors.New("not found")
+		return nil, domain.ErrNotFound
 	}
 	cp := *s
 	return &cp, nil
@@ -89,3 +94,115 @@ func (m *InMemoryPhotos) ListBySpace(ctx context.Context, spaceID string) ([]*do
 	}
 	return out, nil
 }
+
+type InMemoryBlackouts struct {
+	mu      sync.RWMutex
+	bySpace map[string][]*domain.Blackout
+	outbox  *InMemoryOutbox
+}
+
+func NewInMemoryBlackouts(outbox *InMemoryOutbox) *InMemoryBlackouts {
+	return &InMemoryBlackouts{bySpace: make(map[string][]*domain.Blackout), outbox: outbox}
+}
+
+func (m *InMemoryBlackouts) AddBlackout(ctx context.Context, b *domain.Blackout, events ...domain.OutboxMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cp := *b
+	list := append(m.bySpace[b.SpaceID], &cp)
+	sort.Slice(list, func(i, j int) bool { return list[i].Start.Before(list[j].Start) })
+	m.bySpace[b.SpaceID] = list
+	return m.outbox.Append(ctx, events...)
+}
+
+func (m *InMemoryBlackouts) DeleteBlackout(ctx context.Context, spaceID, id string, events ...domain.OutboxMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	list := m.bySpace[spaceID]
+	for i, b := range list {
+		if b.ID == id {
+			m.bySpace[spaceID] = append(list[:i:i], list[i+1:]...)
+			return m.outbox.Append(ctx, events...)
+		}
+	}
+	return domain.ErrNotFound
+}
+
+func (m *InMemoryBlackouts) ListBlackouts(ctx context.Context, spaceID string, from, to time.Time) ([]*domain.Blackout, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]*domain.Blackout, 0)
+	for _, b := range m.bySpace[spaceID] {
+		if (!from.IsZero() && !b.End.After(from)) || (!to.IsZero() && !b.Start.Before(to)) {
+			continue
+		}
+		cp := *b
+		out = append(out, &cp)
+	}
+	return out, nil
+}
+
+type InMemoryPricingRules struct {
+	mu      sync.RWMutex
+	bySpace map[string][]*domain.PricingRule
+	outbox  *InMemoryOutbox
+}
+
+func NewInMemoryPricingRules(outbox *InMemoryOutbox) *InMemoryPricingRules {
+	return &InMemoryPricingRules{bySpace: make(map[string][]*domain.PricingRule), outbox: outbox}
+}
+
+func (m *InMemoryPricingRules) SaveRule(ctx context.Context, r *domain.PricingRule, events ...domain.OutboxMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	list := m.bySpace[r.SpaceID]
+	if err := domain.CheckRuleConflicts(r, list); err != nil {
+		return err
+	}
+	cp := *r
+	for i, o := range list {
+		if o.ID == r.ID {
+			list[i] = &cp
+			return m.outbox.Append(ctx, events...)
+		}
+	}
+	m.bySpace[r.SpaceID] = append(list, &cp)
+	return m.outbox.Append(ctx, events...)
+}
+
+func (m *InMemoryPricingRules) GetRule(ctx context.Context, spaceID, id string) (*domain.PricingRule, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, r := range m.bySpace[spaceID] {
+		if r.ID == id {
+			cp := *r
+			return &cp, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (m *InMemoryPricingRules) DeleteRule(ctx context.Context, spaceID, id string, events ...domain.OutboxMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	list := m.bySpace[spaceID]
+	for i, r := range list {
+		if r.ID == id {
+			m.bySpace[spaceID] = append(list[:i:i], list[i+1:]...)
+			return m.outbox.Append(ctx, events...)
+		}
+	}
+	return domain.ErrNotFound
+}
+
+func (m *InMemoryPricingRules) ListRules(ctx context.Context, spaceID string) ([]*domain.PricingRule, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	list := m.bySpace[spaceID]
+	out := make([]*domain.PricingRule, 0, len(list))
+	for _, r := range list {
+		cp := *r
+		out = append(out, &cp)
+	}
+	return out, nil
+}
@@ -15,6 +15,7 @@ import (
 	spaceServer "templespace/cmd/space/internal/server"
 	"templespace/cmd/space/internal/service"
 	"templespace/cmd/space/internal/storage"
+	"templespace/internal/outbox"
 )
 
 func main() {
@@ -22,12 +23,23 @@ func main() {
 	defer stop()
 
 	// In-memory dependencies and handlers
-	repo := storage.NewInMemorySpaces()
+	// the repositories store the events of their writes in the outbox, which the relay publishes
+	store := storage.NewInMemoryOutbox()
+	repo := storage.NewInMemorySpaces(store)
 	photos := storage.NewInMemoryPhotos()
+	blackouts := storage.NewInMemoryBlackouts(store)
+	rules := storage.NewInMemoryPricingRules(store)
 	rm := readmodel.NewInMemoryReadModel()
 	events := queue.NewInMemoryPublisher()
 	auth := spaceHttp.TokenVerifierStub()
-	svc := service.New(repo, photos, rm, events, auth)
+	svc := service.New(repo, photos, blackouts, rules, rm, auth)
+	// the relay outlives the signal until the server has drained, so it publishes the last requests' events
+	relayCtx, stopRelay := context.WithCancel(context.Background())
+	relayDone := make(chan struct{})
+	go func() {
+		defer close(relayDone)
+		outbox.NewRelay(store, events, time.Second).Run(relayCtx)
+	}()
 	handlers := spaceHttp.NewHandlers(svc)
 	router := spaceHttp.NewRouter(handlers)
 
@@ -52,8 +64,14 @@ func main() {
 	<-ctx.Done()
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
-	if err := srv.Shutdown(shutdownCtx); err != nil {
-		log.Printf("http server shutdown error: %v", err)
+	shutdownErr := srv.Shutdown(shutdownCtx)
+	if shutdownErr != nil {
+		log.Printf("http server shutdown error: %v", shutdownErr)
+	}
+	// the relay makes a last pass over what the finished requests stored
+	stopRelay()
+	<-relayDone
+	if shutdownErr != nil {
 		os.Exit(1)
 	}
 	log.Println("space service gracefully stopped")
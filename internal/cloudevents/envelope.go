@@ -0,0 +1,46 @@
+// Package cloudevents is the event format the services publish: a CloudEvents envelope around
+// versioned payloads, each event type with a JSON Schema its payload is tested against.
+package cloudevents
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	SpecVersion = "1.0"
+	// ContentType marks a message whose body is a whole envelope (CloudEvents structured mode).
+	ContentType = "application/cloudevents+json"
+)
+
+// Envelope follows the CloudEvents 1.0 JSON format. ID is unique per event and stays the same when
+// the relay publishes it again, so consumers can dedupe on it.
+type Envelope struct {
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	SpecVersion     string          `json:"specversion"`
+	Time            time.Time       `json:"time"`
+	Subject         string          `json:"subject"`
+	DataContentType string          `json:"datacontenttype"`
+	DataSchema      string          `json:"dataschema"`
+	Data            json.RawMessage `json:"data"`
+}
+
+// Decode reads an envelope; its Data can then be unmarshalled into the payload type its DataSchema names.
+func Decode(b []byte) (Envelope, error) {
+	var e Envelope
+	err := json.Unmarshal(b, &e)
+	return e, err
+}
+
+// newID returns a random UUID.
+func newID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,153 @@
+package cloudevents
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+	"time"
+)
+
+// validate checks data against the schema file name in files. It understands the subset of JSON Schema the
+// registered schemas use: type, properties, required, additionalProperties, items, enum, minimum,
+// format date-time and $ref to "file.json", "#/$defs/x" or "file.json#/$defs/x".
+func validate(files fs.FS, name string, data []byte) error {
+	root, err := loadSchema(files, name)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return err
+	}
+	return check(files, root, root, v, "$")
+}
+
+func loadSchema(files fs.FS, name string) (map[string]any, error) {
+	doc, err := fs.ReadFile(files, name)
+	if err != nil {
+		return nil, err
+	}
+	var root map[string]any
+	if err := json.Unmarshal(doc, &root); err != nil {
+		return nil, fmt.Errorf("schema %s: %w", name, err)
+	}
+	return root, nil
+}
+
+func check(files fs.FS, root, s map[string]any, v any, path string) error {
+	if ref, ok := s["$ref"].(string); ok {
+		file, fragment, _ := strings.Cut(ref, "#")
+		if file != "" {
+			var err error
+			if root, err = loadSchema(files, file); err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+		}
+		target := root
+		if fragment != "" {
+			target, ok = root["$defs"].(map[string]any)[strings.TrimPrefix(fragment, "/$defs/")].(map[string]any)
+			if !ok {
+				return fmt.Errorf("%s: unknown $ref %s", path, ref)
+			}
+		}
+		return check(files, root, target, v, path)
+	}
+	if t, ok := s["type"]; ok && !hasType(t, v) {
+		return fmt.Errorf("%s: want %v, got %s", path, t, typeOf(v))
+	}
+	// enums only list strings, which decode to the same values
+	if enum, ok := s["enum"].([]any); ok && !slices.Contains(enum, v) {
+		return fmt.Errorf("%s: %v is not one of %v", path, v, enum)
+	}
+	if min, ok := s["minimum"].(float64); ok {
+		if n, ok := v.(json.Number); ok {
+			if f, _ := n.Float64(); f < min {
+				return fmt.Errorf("%s: %v is below %v", path, v, min)
+			}
+		}
+	}
+	if s["format"] == "date-time" {
+		if str, ok := v.(string); ok {
+			if _, err := time.Parse(time.RFC3339, str); err != nil {
+				return fmt.Errorf("%s: %q is not a date-time", path, str)
+			}
+		}
+	}
+	switch v := v.(type) {
+	case map[string]any:
+		props, _ := s["properties"].(map[string]any)
+		for _, r := range asList(s["required"]) {
+			if _, ok := v[r.(string)]; !ok {
+				return fmt.Errorf("%s: missing %s", path, r)
+			}
+		}
+		for k, fv := range v {
+			ps, ok := props[k].(map[string]any)
+			if !ok {
+				if s["additionalProperties"] == false {
+					return fmt.Errorf("%s: unexpected property %s", path, k)
+				}
+				continue
+			}
+			if err := check(files, root, ps, fv, path+"."+k); err != nil {
+				return err
+			}
+		}
+	case []any:
+		if items, ok := s["items"].(map[string]any); ok {
+			for i, iv := range v {
+				if err := check(files, root, items, iv, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func hasType(t, v any) bool {
+	for _, want := range asList(t) {
+		got := typeOf(v)
+		if want == got || want == "number" && got == "integer" {
+			return true
+		}
+	}
+	return false
+}
+
+func typeOf(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// asList reads a keyword that is either a single value or a list of them.
+func asList(v any) []any {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case []any:
+		return v
+	default:
+		return []any{v}
+	}
+}
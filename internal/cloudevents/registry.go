@@ -0,0 +1,106 @@
+package cloudevents
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SchemaPath is where the schemas are served; an envelope's dataschema is SchemaPath plus the file.
+const SchemaPath = "/events/schemas/"
+
+// Registry holds the event types of one producer and the schema file of each type's current payload
+// version. A breaking payload change adds a new file, e.g. booking.v2.json, and points the types at it.
+type Registry struct {
+	source string
+	files  fs.FS
+	types  map[string]string
+}
+
+// NewRegistry registers types, mapping event types to schema files in files, for events of source.
+func NewRegistry(source string, files fs.FS, types map[string]string) *Registry {
+	return &Registry{source: source, files: files, types: types}
+}
+
+// Types returns the registered event types in name order.
+func (r *Registry) Types() []string {
+	out := make([]string, 0, len(r.types))
+	for t := range r.types {
+		out = append(out, t)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// SchemaOf returns the dataschema URI of event type typ.
+func (r *Registry) SchemaOf(typ string) (string, error) {
+	name, ok := r.types[typ]
+	if !ok {
+		return "", fmt.Errorf("events: unregistered type %q", typ)
+	}
+	return SchemaPath + name, nil
+}
+
+// Validate checks the data of an event of type typ against its registered schema. Encode does not
+// call it: payloads are checked against their schemas by the tests of the producing service.
+func (r *Registry) Validate(typ string, data []byte) error {
+	name, ok := r.types[typ]
+	if !ok {
+		return fmt.Errorf("events: unregistered type %q", typ)
+	}
+	if err := validate(r.files, name, data); err != nil {
+		return fmt.Errorf("events: %s does not match %s: %w", typ, name, err)
+	}
+	return nil
+}
+
+// Encode wraps data, a payload of a registered type, in an envelope of typ about subject, e.g. a
+// booking ID.
+func (r *Registry) Encode(typ, subject string, at time.Time, data any) ([]byte, error) {
+	schema, err := r.SchemaOf(typ)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(Envelope{
+		ID:              newID(),
+		Source:          r.source,
+		Type:            typ,
+		SpecVersion:     SpecVersion,
+		Time:            at.UTC(),
+		Subject:         subject,
+		DataContentType: "application/json",
+		DataSchema:      schema,
+		Data:            raw,
+	})
+}
+
+// SchemaHandler serves GET SchemaPath, mapping event types to their dataschema, and the schema
+// files below it.
+func (r *Registry) SchemaHandler() http.Handler {
+	files := http.StripPrefix(SchemaPath, http.FileServerFS(r.files))
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if strings.TrimPrefix(req.URL.Path, SchemaPath) != "" {
+			w.Header().Set("Content-Type", "application/schema+json")
+			files.ServeHTTP(w, req)
+			return
+		}
+		index := map[string]string{}
+		for t, name := range r.types {
+			index[t] = SchemaPath + name
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(index)
+	})
+}
@@ -0,0 +1,49 @@
+package cloudevents
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestValidateCatchesDrift(t *testing.T) {
+	files := fstest.MapFS{
+		"thing.v1.json": {Data: []byte(`{
+			"type": "object",
+			"required": ["id", "at"],
+			"additionalProperties": false,
+			"properties": {
+				"id": {"type": "string"},
+				"at": {"type": "string", "format": "date-time"},
+				"size": {"$ref": "common.json#/$defs/size"}
+			}
+		}`)},
+		"common.json": {Data: []byte(`{"$defs": {"size": {"type": "integer", "minimum": 1}}}`)},
+	}
+	r := NewRegistry("test", files, map[string]string{"thing_made": "thing.v1.json"})
+	for _, c := range []struct {
+		data string
+		ok   bool
+	}{
+		{`{"id": "t-1", "at": "2030-03-04T09:00:00Z", "size": 2}`, true},
+		{`{"id": "t-1"}`, false},
+		{`{"id": 1, "at": "2030-03-04T09:00:00Z"}`, false},
+		{`{"id": "t-1", "at": "tomorrow"}`, false},
+		{`{"id": "t-1", "at": "2030-03-04T09:00:00Z", "size": 0}`, false},
+		{`{"id": "t-1", "at": "2030-03-04T09:00:00Z", "colour": "red"}`, false},
+	} {
+		if err := r.Validate("thing_made", []byte(c.data)); (err == nil) != c.ok {
+			t.Errorf("%s: got %v, want ok %v", c.data, err, c.ok)
+		}
+	}
+	if err := r.Validate("thing_lost", []byte(`{}`)); err == nil {
+		t.Error("unregistered type validated")
+	}
+	// Encode leaves validation to the tests, so a drifting payload is still published
+	if _, err := r.Encode("thing_made", "t-1", time.Now(), map[string]any{"id": 1}); err != nil {
+		t.Errorf("encode: %v", err)
+	}
+	if _, err := r.Encode("thing_lost", "t-1", time.Now(), nil); err == nil {
+		t.Error("encoded an unregistered type")
+	}
+}
@@ -0,0 +1,47 @@
+// Package outbox holds the transactional outbox shared by the services: events are stored with the
+// change they report and published afterwards by a Relay, so a crash or broker outage after the write
+// cannot lose them.
+package outbox
+
+import (
+	"context"
+	"time"
+)
+
+// Message is an event waiting in an outbox.
+type Message struct {
+	// Seq is assigned by the outbox and orders the messages.
+	Seq       int64
+	Topic     string
+	Key       string
+	Payload   []byte
+	CreatedAt time.Time
+	// Attempts counts failed publishes; the next one waits until NextAttemptAt.
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     string
+}
+
+// Stats describes the unpublished backlog; Oldest is zero when it is empty.
+type Stats struct {
+	Pending int
+	Oldest  time.Time
+}
+
+// Store is an outbox. Services write most messages through their repositories, in the same
+// transaction as the change they report; Append stores messages that belong to no write.
+type Store interface {
+	Append(ctx context.Context, msgs ...Message) error
+	// Pending returns up to limit unpublished messages in Seq order. Keys with a message waiting for
+	// a retry are left out entirely, so they neither reorder nor hold up the rest.
+	Pending(ctx context.Context, limit int) ([]Message, error)
+	MarkPublished(ctx context.Context, seqs ...int64) error
+	// MarkFailed records a failed publish and defers the message until retryAt.
+	MarkFailed(ctx context.Context, seq int64, reason string, retryAt time.Time) error
+	Stats(ctx context.Context) (Stats, error)
+}
+
+// Publisher sends a message to the broker.
+type Publisher interface {
+	Publish(topic, key string, payload []byte) error
+}
@@ -0,0 +1,148 @@
+package outbox
+
+import (
+	"context"
+	"expvar"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	relayBatch      = 100
+	relayMinBackoff = time.Second
+	relayMaxBackoff = 5 * time.Minute
+)
+
+// relayMetrics is served under "outbox" at /debug/vars.
+var relayMetrics = expvar.NewMap("outbox")
+
+// Relay drains a Store to a Publisher. Messages of one key are published in the order they were
+// stored: once one fails, later messages of that key wait until it goes through.
+type Relay struct {
+	store     Store
+	publisher Publisher
+	interval  time.Duration
+	now       func() time.Time
+}
+
+func NewRelay(store Store, publisher Publisher, interval time.Duration) *Relay {
+	return &Relay{store: store, publisher: publisher, interval: interval, now: time.Now}
+}
+
+// Run drains the outbox every interval until ctx is cancelled, then makes a last pass.
+func (r *Relay) Run(ctx context.Context) {
+	t := time.NewTicker(r.interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			if _, err := r.Drain(context.WithoutCancel(ctx)); err != nil {
+				log.Printf("outbox relay: %v", err)
+			}
+			return
+		case <-t.C:
+			if _, err := r.Drain(ctx); err != nil {
+				log.Printf("outbox relay: %v", err)
+			}
+		}
+	}
+}
+
+// Drain publishes due messages until none are left and returns how many went out.
+func (r *Relay) Drain(ctx context.Context) (int, error) {
+	total := 0
+	defer r.observe(ctx)
+	for {
+		n, more, err := r.pass(ctx)
+		total += n
+		if err != nil || !more {
+			return total, err
+		}
+	}
+}
+
+// pass publishes one batch and reports whether another batch may be waiting. Keys are published
+// concurrently, so a batching publisher can send them together; each key's messages go out in order.
+func (r *Relay) pass(ctx context.Context) (int, bool, error) {
+	msgs, err := r.store.Pending(ctx, relayBatch)
+	if err != nil || len(msgs) == 0 {
+		return 0, false, err
+	}
+	now := r.now()
+	byKey := map[string][]Message{}
+	for _, m := range msgs {
+		byKey[m.Key] = append(byKey[m.Key], m)
+	}
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		published []int64
+		failed    []failure
+	)
+	for _, ms := range byKey {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, m := range ms {
+				if m.NextAttemptAt.After(now) {
+					return
+				}
+				err := r.publisher.Publish(m.Topic, m.Key, m.Payload)
+				mu.Lock()
+				if err != nil {
+					failed = append(failed, failure{m, err})
+				} else {
+					published = append(published, m.Seq)
+				}
+				mu.Unlock()
+				if err != nil {
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	for _, f := range failed {
+		relayMetrics.Add("failed", 1)
+		if err := r.store.MarkFailed(ctx, f.msg.Seq, f.err.Error(), now.Add(backoff(f.msg.Attempts))); err != nil {
+			return 0, false, err
+		}
+	}
+	if err := r.store.MarkPublished(ctx, published...); err != nil {
+		return 0, false, err
+	}
+	relayMetrics.Add("published", int64(len(published)))
+	// a full batch that was all published may have more behind it; anything held back waits a tick
+	return len(published), len(msgs) == relayBatch && len(published) == len(msgs), nil
+}
+
+type failure struct {
+	msg Message
+	err error
+}
+
+// observe updates the backlog gauges: pending messages and the age of the oldest in seconds.
+func (r *Relay) observe(ctx context.Context) {
+	st, err := r.store.Stats(ctx)
+	if err != nil {
+		return
+	}
+	pending := new(expvar.Int)
+	pending.Set(int64(st.Pending))
+	relayMetrics.Set("pending", pending)
+	lag := new(expvar.Float)
+	if !st.Oldest.IsZero() {
+		lag.Set(r.now().Sub(st.Oldest).Seconds())
+	}
+	relayMetrics.Set("lag_seconds", lag)
+}
+
+// backoff doubles the wait after each failed attempt, up to relayMaxBackoff.
+func backoff(attempts int) time.Duration {
+	d := relayMinBackoff
+	for i := 0; i < attempts && d < relayMaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, relayMaxBackoff)
+}
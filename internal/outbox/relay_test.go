@@ -0,0 +1,119 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memStore is a Store over a slice, enough to drive a Relay.
+type memStore struct {
+	mu   sync.Mutex
+	seq  int64
+	msgs []Message
+}
+
+func (s *memStore) Append(ctx context.Context, msgs ...Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, m := range msgs {
+		s.seq++
+		m.Seq = s.seq
+		s.msgs = append(s.msgs, m)
+	}
+	return nil
+}
+
+func (s *memStore) Pending(ctx context.Context, limit int) ([]Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message(nil), s.msgs[:min(limit, len(s.msgs))]...), nil
+}
+
+func (s *memStore) MarkPublished(ctx context.Context, seqs ...int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	done := map[int64]bool{}
+	for _, q := range seqs {
+		done[q] = true
+	}
+	kept := s.msgs[:0]
+	for _, m := range s.msgs {
+		if !done[m.Seq] {
+			kept = append(kept, m)
+		}
+	}
+	s.msgs = kept
+	return nil
+}
+
+func (s *memStore) MarkFailed(ctx context.Context, seq int64, reason string, retryAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.msgs {
+		if s.msgs[i].Seq == seq {
+			s.msgs[i].Attempts++
+			s.msgs[i].LastError, s.msgs[i].NextAttemptAt = reason, retryAt
+		}
+	}
+	return nil
+}
+
+func (s *memStore) Stats(ctx context.Context) (Stats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return Stats{Pending: len(s.msgs)}, nil
+}
+
+// flaky fails the first publish of each key in fail and records what went out.
+type flaky struct {
+	mu        sync.Mutex
+	fail      map[string]bool
+	published map[string][]string
+}
+
+func (p *flaky) Publish(topic, key string, payload []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.fail[key] {
+		delete(p.fail, key)
+		return errors.New("broker down")
+	}
+	p.published[key] = append(p.published[key], string(payload))
+	return nil
+}
+
+func TestRelayKeepsKeyOrderThroughFailures(t *testing.T) {
+	store := &memStore{}
+	for i := 0; i < 3; i++ {
+		for _, key := range []string{"a", "b", "c"} {
+			_ = store.Append(context.Background(), Message{Topic: "t", Key: key, Payload: []byte(strconv.Itoa(i))})
+		}
+	}
+	pub := &flaky{fail: map[string]bool{"b": true}, published: map[string][]string{}}
+	r := NewRelay(store, pub, time.Second)
+	clock := time.Now()
+	r.now = func() time.Time { return clock }
+
+	if n, err := r.Drain(context.Background()); err != nil || n != 6 {
+		t.Fatalf("first drain published %d, %v; want the 6 messages of a and c", n, err)
+	}
+	if got := pub.published["b"]; len(got) != 0 {
+		t.Fatalf("b published %v past its failed first message", got)
+	}
+	if n, _ := r.Drain(context.Background()); n != 0 {
+		t.Fatalf("published %d before the backoff ran out", n)
+	}
+	clock = clock.Add(relayMinBackoff)
+	if n, err := r.Drain(context.Background()); err != nil || n != 3 {
+		t.Fatalf("retry published %d, %v; want 3", n, err)
+	}
+	for _, key := range []string{"a", "b", "c"} {
+		if got := pub.published[key]; len(got) != 3 || got[0] != "0" || got[1] != "1" || got[2] != "2" {
+			t.Errorf("%s published as %v, want [0 1 2]", key, got)
+		}
+	}
+}